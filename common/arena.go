@@ -0,0 +1,245 @@
+package common
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"path"
+	"sort"
+	"strings"
+)
+
+// CharRadius is half the width of the box a character takes up when
+// colliding with walls.
+const CharRadius = 6.0
+
+// DefaultArena is played when no other map has been picked.
+const DefaultArena = "open"
+
+//go:embed maps/*.json
+var mapFiles embed.FS
+
+// Arenas are the maps built into the game, by name. Server and client
+// both load them so they agree on where the walls are.
+var Arenas = loadArenas()
+
+// Arena is a grid of square tiles, some of which are solid.
+type Arena struct {
+	Name string
+	// Size in tiles.
+	Width, Height int
+	TileSize      int
+	solid         []bool
+	// Indexes of the tiles that are not solid.
+	open []int
+}
+
+func loadArenas() map[string]*Arena {
+	files, err := mapFiles.ReadDir("maps")
+	if err != nil {
+		panic(err)
+	}
+	arenas := make(map[string]*Arena)
+	for _, f := range files {
+		data, err := mapFiles.ReadFile(path.Join("maps", f.Name()))
+		if err != nil {
+			panic(err)
+		}
+		name := strings.TrimSuffix(f.Name(), ".json")
+		a, err := ParseArena(name, data)
+		if err != nil {
+			panic(fmt.Sprintf("map %s: %v", name, err))
+		}
+		arenas[name] = a
+	}
+	return arenas
+}
+
+// ArenaNames returns the names of the built-in maps in order.
+func ArenaNames() []string {
+	names := make([]string, 0, len(Arenas))
+	for name := range Arenas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tiledMap is the part of the Tiled editor's JSON map format that arenas
+// use.
+type tiledMap struct {
+	Orientation string       `json:"orientation"`
+	Infinite    bool         `json:"infinite"`
+	Width       int          `json:"width"`
+	Height      int          `json:"height"`
+	TileWidth   int          `json:"tilewidth"`
+	TileHeight  int          `json:"tileheight"`
+	Layers      []tiledLayer `json:"layers"`
+}
+
+type tiledLayer struct {
+	Type       string          `json:"type"`
+	Name       string          `json:"name"`
+	Encoding   string          `json:"encoding"`
+	Data       json.RawMessage `json:"data"`
+	Properties []tiledProperty `json:"properties"`
+}
+
+type tiledProperty struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+func (l tiledLayer) solid() bool {
+	for _, p := range l.Properties {
+		if p.Name == "solid" && p.Value == true {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseArena reads a map saved by Tiled as JSON. Every tile placed on a
+// tile layer with the bool property "solid" set is a wall; other layers
+// are decoration. Maps must be orthogonal, finite, have square tiles and
+// store layer data as plain arrays.
+func ParseArena(name string, data []byte) (*Arena, error) {
+	var m tiledMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	switch {
+	case m.Orientation != "orthogonal":
+		return nil, fmt.Errorf("%q maps are not supported", m.Orientation)
+	case m.Infinite:
+		return nil, errors.New("infinite maps are not supported")
+	case m.TileWidth <= 0 || m.TileWidth != m.TileHeight:
+		return nil, errors.New("tiles must be square")
+	case m.Width <= 0 || m.Height <= 0:
+		return nil, errors.New("map is empty")
+	}
+	a := &Arena{
+		Name:     name,
+		Width:    m.Width,
+		Height:   m.Height,
+		TileSize: m.TileWidth,
+		solid:    make([]bool, m.Width*m.Height),
+	}
+	for _, l := range m.Layers {
+		if l.Type != "tilelayer" || !l.solid() {
+			continue
+		}
+		if l.Encoding != "" && l.Encoding != "csv" {
+			return nil, fmt.Errorf("layer %q: %s encoding is not supported", l.Name, l.Encoding)
+		}
+		var tiles []int
+		if err := json.Unmarshal(l.Data, &tiles); err != nil {
+			return nil, fmt.Errorf("layer %q: %w", l.Name, err)
+		}
+		if len(tiles) != len(a.solid) {
+			return nil, fmt.Errorf("layer %q has %d tiles, want %d", l.Name, len(tiles), len(a.solid))
+		}
+		for i, gid := range tiles {
+			if gid != 0 {
+				a.solid[i] = true
+			}
+		}
+	}
+	for i, solid := range a.solid {
+		if !solid {
+			a.open = append(a.open, i)
+		}
+	}
+	if len(a.open) == 0 {
+		return nil, errors.New("map has no open tiles")
+	}
+	return a, nil
+}
+
+// PixelWidth is the width of the arena in pixels.
+func (a *Arena) PixelWidth() float64 {
+	return float64(a.Width * a.TileSize)
+}
+
+// PixelHeight is the height of the arena in pixels.
+func (a *Arena) PixelHeight() float64 {
+	return float64(a.Height * a.TileSize)
+}
+
+// Solid reports whether the tile at column tx and row ty is a wall. Tiles
+// outside the map are solid.
+func (a *Arena) Solid(tx, ty int) bool {
+	if tx < 0 || ty < 0 || tx >= a.Width || ty >= a.Height {
+		return true
+	}
+	return a.solid[ty*a.Width+tx]
+}
+
+// Blocked reports whether a square reaching r pixels out from (x, y)
+// overlaps a wall.
+func (a *Arena) Blocked(x, y, r float64) bool {
+	// written to also catch NaN
+	if !(x-r >= 0 && y-r >= 0 && x+r < a.PixelWidth() && y+r < a.PixelHeight()) {
+		return true
+	}
+	size := float64(a.TileSize)
+	x0, x1 := int(math.Floor((x-r)/size)), int(math.Floor((x+r)/size))
+	y0, y1 := int(math.Floor((y-r)/size)), int(math.Floor((y+r)/size))
+	for ty := y0; ty <= y1; ty++ {
+		for tx := x0; tx <= x1; tx++ {
+			if a.Solid(tx, ty) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LineOfSight reports whether the straight line from (x0, y0) to (x1, y1)
+// stays clear of walls. The line is sampled four times per tile.
+func (a *Arena) LineOfSight(x0, y0, x1, y1 float64) bool {
+	size := float64(a.TileSize)
+	dx, dy := x1-x0, y1-y0
+	steps := int(math.Ceil(math.Max(math.Abs(dx), math.Abs(dy)) / (size / 4)))
+	for i := 0; i <= steps; i++ {
+		t := 1.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		x, y := x0+dx*t, y0+dy*t
+		if a.Solid(int(math.Floor(x/size)), int(math.Floor(y/size))) {
+			return false
+		}
+	}
+	return true
+}
+
+// RandomSpot returns the centre of a random open tile.
+func (a *Arena) RandomSpot() (x, y float64) {
+	i := a.open[rand.Intn(len(a.open))]
+	size := float64(a.TileSize)
+	return (float64(i%a.Width) + .5) * size, (float64(i/a.Width) + .5) * size
+}
+
+// RandomSpotNear returns the centre of a random open tile within r of
+// (x, y), or of any open tile if none is that close.
+func (a *Arena) RandomSpotNear(x, y, r float64) (float64, float64) {
+	size := float64(a.TileSize)
+	var near []int
+	for ty := int(math.Floor((y - r) / size)); ty <= int(math.Floor((y+r)/size)); ty++ {
+		for tx := int(math.Floor((x - r) / size)); tx <= int(math.Floor((x+r)/size)); tx++ {
+			cx, cy := (float64(tx)+.5)*size, (float64(ty)+.5)*size
+			if !a.Solid(tx, ty) && math.Hypot(cx-x, cy-y) <= r {
+				near = append(near, ty*a.Width+tx)
+			}
+		}
+	}
+	if len(near) == 0 {
+		return a.RandomSpot()
+	}
+	i := near[rand.Intn(len(near))]
+	return (float64(i%a.Width) + .5) * size, (float64(i/a.Width) + .5) * size
+}
@@ -0,0 +1,120 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+const testMap = `{
+ "orientation": "orthogonal",
+ "width": 4, "height": 3, "tilewidth": 16, "tileheight": 16,
+ "layers": [
+  {"type": "tilelayer", "name": "floor", "data": [2,2,2,2, 2,2,2,2, 2,2,2,2]},
+  {"type": "tilelayer", "name": "walls", "data": [1,1,1,1, 1,0,0,1, 1,1,1,1],
+   "properties": [{"name": "solid", "type": "bool", "value": true}]}
+ ]
+}`
+
+func TestParseArena(t *testing.T) {
+	a, err := ParseArena("test", []byte(testMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Width != 4 || a.Height != 3 || a.PixelWidth() != 64 || a.PixelHeight() != 48 {
+		t.Fatalf("got %+v", a)
+	}
+	for _, tile := range [][2]int{{0, 0}, {3, 1}, {2, 2}, {-1, 1}, {4, 1}, {1, 3}} {
+		if !a.Solid(tile[0], tile[1]) {
+			t.Errorf("tile %v is not solid", tile)
+		}
+	}
+	if a.Solid(1, 1) || a.Solid(2, 1) {
+		t.Error("floor tiles are solid")
+	}
+	for i := 0; i < 20; i++ {
+		if x, y := a.RandomSpot(); a.Blocked(x, y, CharRadius) {
+			t.Fatalf("random spot (%v, %v) is in a wall", x, y)
+		}
+		if x, y := a.RandomSpotNear(40, 24, 10); x != 40 || y != 24 {
+			t.Fatalf("random spot near (40, 24) is (%v, %v)", x, y)
+		}
+		// nothing open that close, so anywhere will do
+		if x, y := a.RandomSpotNear(0, 0, 4); a.Blocked(x, y, CharRadius) {
+			t.Fatalf("fallback spot (%v, %v) is in a wall", x, y)
+		}
+	}
+}
+
+func TestParseArenaErrors(t *testing.T) {
+	const solid = `"properties": [{"name": "solid", "value": true}]`
+	tests := []struct {
+		header, layer, want string
+	}{
+		{`"orientation": "isometric", "tilewidth": 16, "tileheight": 16`, "", "isometric"},
+		{`"orientation": "orthogonal", "infinite": true, "tilewidth": 16, "tileheight": 16`, "", "infinite"},
+		{`"orientation": "orthogonal", "tilewidth": 16, "tileheight": 8`, "", "square"},
+		{`"orientation": "orthogonal", "tilewidth": 16, "tileheight": 16`, `"data": [1, 0, 0], ` + solid, "has 3 tiles"},
+		{`"orientation": "orthogonal", "tilewidth": 16, "tileheight": 16`, `"data": [1, 1], ` + solid, "no open tiles"},
+		{`"orientation": "orthogonal", "tilewidth": 16, "tileheight": 16`, `"encoding": "base64", "data": "AQAAAA==", ` + solid, "base64"},
+	}
+	for _, tt := range tests {
+		data := `{"width": 2, "height": 1, ` + tt.header
+		if tt.layer != "" {
+			data += `, "layers": [{"type": "tilelayer", ` + tt.layer + `}]`
+		}
+		data += "}"
+		if _, err := ParseArena("test", []byte(data)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("got %v, want error containing %q", err, tt.want)
+		}
+	}
+}
+
+func TestBuiltInArenas(t *testing.T) {
+	if Arenas[DefaultArena] == nil {
+		t.Fatalf("default map %q missing from %v", DefaultArena, ArenaNames())
+	}
+	for name, a := range Arenas {
+		if a.Name != name {
+			t.Errorf("map %s is called %s", name, a.Name)
+		}
+	}
+}
+
+func TestMoveSlidesAlongWalls(t *testing.T) {
+	arena := Arenas[DefaultArena]
+	c := NewChar()
+	// one pixel clear of the left wall
+	c.Px, c.Py = float64(arena.TileSize)+CharRadius+1, 100
+	c.ProcessInput(&pb.Input{LeftPressed: true, DownPressed: true})
+	for i := 0; i < 10; i++ {
+		c.Move(arena)
+	}
+	if arena.Blocked(c.Px, c.Py, CharRadius) {
+		t.Fatalf("walked into the wall to (%v, %v)", c.Px, c.Py)
+	}
+	if c.Py <= 100+5 {
+		t.Fatalf("stuck on the wall at y %v", c.Py)
+	}
+}
+
+func TestLineOfSight(t *testing.T) {
+	// pillar at tiles (6, 4) to (7, 5)
+	arena := Arenas["pillars"]
+	tests := []struct {
+		x0, y0, x1, y1 float64
+		want           bool
+	}{
+		{72, 80, 72, 140, true},
+		{72, 40, 152, 40, true},
+		{72, 80, 152, 80, false},
+		{72, 40, 8, 40, false},
+		{72, 80, 72, 80, true},
+	}
+	for _, tt := range tests {
+		if got := arena.LineOfSight(tt.x0, tt.y0, tt.x1, tt.y1); got != tt.want {
+			t.Errorf("(%v, %v) to (%v, %v): got %v, want %v", tt.x0, tt.y0, tt.x1, tt.y1, got, tt.want)
+		}
+	}
+}
@@ -8,27 +8,83 @@ import (
 	"github.com/kisunji/ebiten-poc/pb"
 )
 
+// AttackPhase is how far along an attack a character is.
+type AttackPhase int
+
+const (
+	AttackIdle AttackPhase = iota
+	// Committed to the attack but not dangerous yet.
+	AttackWindUp
+	// Kills whoever is at the impact site.
+	AttackActive
+	// Following through; still cannot move.
+	AttackRecovery
+	// Free to move but not to attack again.
+	AttackCooldown
+
+	NumAttackPhases = int(AttackCooldown) + 1
+)
+
+// AttackTiming is how many ticks each attack phase lasts. A phase of zero
+// ticks is skipped.
+type AttackTiming struct {
+	WindUp   int
+	Active   int
+	Recovery int
+	Cooldown int
+}
+
+// DefaultAttackTiming is used until the server says otherwise.
+var DefaultAttackTiming = AttackTiming{WindUp: 9, Active: 3, Recovery: 9, Cooldown: 18}
+
+func (t AttackTiming) ticks(p AttackPhase) int {
+	switch p {
+	case AttackWindUp:
+		return t.WindUp
+	case AttackActive:
+		return t.Active
+	case AttackRecovery:
+		return t.Recovery
+	case AttackCooldown:
+		return t.Cooldown
+	}
+	return 0
+}
+
 type Char struct {
 	Fx, Fy      int     // facing
 	Vx, Vy      int     // velocity
 	Px, Py      float64 // position
 	Speed       int
+	Stamina     int
 	Offset      int // animation offset
+	AttackPhase AttackPhase
+	// Ticks left in AttackPhase.
 	AttackFrame int
 	IsDead      bool
+	// Length of each attack phase.
+	Timing AttackTiming
+	// Extra pixels per tick on top of Speed, from a power-up.
+	Boost int
 
 	// used by server only
 	lastUpdatedTimer time.Time
+	// Movement asked for during an attack, applied once it is over.
+	heldVx, heldVy int
+	// Whether the player wants to sprint.
+	sprintHeld bool
 }
 
 func NewChar() *Char {
 	return &Char{
-		Fx:     rand.Intn(2) - 1,
-		Fy:     rand.Intn(2) - 1,
-		Px:     float64(ScreenPadding + rand.Intn(ScreenWidth-ScreenPadding*3)),
-		Py:     float64(ScreenPadding + rand.Intn(ScreenHeight-ScreenPadding*3)),
-		Speed:  1,
-		Offset: rand.Intn(10),
+		Fx:      rand.Intn(2) - 1,
+		Fy:      rand.Intn(2) - 1,
+		Px:      float64(ScreenPadding + rand.Intn(ScreenWidth-ScreenPadding*3)),
+		Py:      float64(ScreenPadding + rand.Intn(ScreenHeight-ScreenPadding*3)),
+		Speed:   WalkSpeed,
+		Stamina: MaxStamina,
+		Offset:  rand.Intn(10),
+		Timing:  DefaultAttackTiming,
 	}
 }
 
@@ -47,52 +103,127 @@ func (cc Chars) UpdateFromData(input *pb.UpdateEntity) {
 	c.Vx = int(input.Vx)
 	c.Vy = int(input.Vy)
 	c.Speed = int(input.Speed)
+	c.Stamina = int(input.Stamina)
+	c.Boost = int(input.Boost)
+	c.AttackPhase = AttackPhase(input.AttackPhase)
 	c.AttackFrame = int(input.AttackFrame)
 	c.IsDead = input.IsDead
 }
 
+// ProcessInput applies a player's or AI's input. Pressing action starts an
+// attack unless one is already under way. Movement asked for while
+// attacking waits until the character is free to move.
 func (c *Char) ProcessInput(input *pb.Input) {
-	c.Vx = 0
-	c.Vy = 0
-	if input.ActionPressed {
-		c.Attack()
-		return
-	}
-	if input.RightPressed {
-		c.Vx = 1
-	}
-	if input.LeftPressed {
-		c.Vx = -1
+	var vx, vy int
+	if input.RightPressed != input.LeftPressed {
+		vx = 1
+		if input.LeftPressed {
+			vx = -1
+		}
 	}
-	if input.UpPressed {
-		c.Vy = -1
+	if input.UpPressed != input.DownPressed {
+		vy = 1
+		if input.UpPressed {
+			vy = -1
+		}
 	}
-	if input.DownPressed {
-		c.Vy = 1
-	}
-	if input.RightPressed == input.LeftPressed {
-		c.Vx = 0
+	c.lastUpdatedTimer = time.Now()
+	c.sprintHeld = input.SprintPressed
+	if c.Attacking() {
+		c.heldVx, c.heldVy = vx, vy
+		return
 	}
-	if input.UpPressed == input.DownPressed {
-		c.Vy = 0
+	if input.ActionPressed && c.AttackPhase == AttackIdle {
+		c.Vx, c.Vy = 0, 0
+		c.heldVx, c.heldVy = vx, vy
+		c.enterPhase(AttackWindUp)
+		return
 	}
-	c.lastUpdatedTimer = time.Now()
+	c.Vx, c.Vy = vx, vy
 }
 
-// Attack sets decrements attackFrame
+// Attack advances an attack by one tick, moving on to the next phase when
+// the current one runs out.
 func (c *Char) Attack() {
-	if c.AttackFrame == 0 {
-		c.AttackFrame = 20
+	if c.AttackPhase == AttackIdle {
 		return
 	}
 	c.AttackFrame--
+	for c.AttackFrame <= 0 && c.AttackPhase != AttackIdle {
+		c.enterPhase(c.AttackPhase + 1)
+	}
 }
 
+func (c *Char) enterPhase(p AttackPhase) {
+	if p < AttackIdle || p > AttackCooldown {
+		p = AttackIdle
+	}
+	c.AttackPhase = p
+	c.AttackFrame = c.Timing.ticks(p)
+	if p == AttackCooldown {
+		c.Vx, c.Vy = c.heldVx, c.heldVy
+		c.heldVx, c.heldVy = 0, 0
+	}
+}
+
+// Attacking reports whether the character is locked in an attack, from
+// wind-up through recovery.
 func (c *Char) Attacking() bool {
-	return c.AttackFrame > 0
+	return c.AttackPhase >= AttackWindUp && c.AttackPhase <= AttackRecovery
+}
+
+// AttackAnimFrame picks one of the four attack animation frames: the
+// first two spread over the wind-up, the third while active and the last
+// during recovery.
+func (c *Char) AttackAnimFrame() int {
+	switch c.AttackPhase {
+	case AttackWindUp:
+		if c.AttackFrame*2 > c.Timing.WindUp {
+			return 0
+		}
+		return 1
+	case AttackActive:
+		return 2
+	}
+	return 3
+}
+
+// Exert picks the character's speed for this tick from the sprint input and
+// its stamina, then spends or regains stamina. It reports whether the speed
+// changed. Only the server picks speeds; clients just call UseStamina.
+func (c *Char) Exert() bool {
+	speed := WalkSpeed
+	if c.sprintHeld && (c.Vx != 0 || c.Vy != 0) {
+		// keep running until stamina runs out, but only start with enough
+		if c.Speed == SprintSpeed && c.Stamina > 0 || c.Stamina >= MinSprintStamina {
+			speed = SprintSpeed
+		}
+	}
+	changed := speed != c.Speed
+	c.Speed = speed
+	c.UseStamina()
+	return changed
 }
 
-func (c *Char) Move() {
+// UseStamina spends stamina for one tick of sprinting, or regains some
+// while walking or standing still.
+func (c *Char) UseStamina() {
+	if c.Speed > WalkSpeed {
+		c.Stamina -= SprintCost
+	} else {
+		c.Stamina += StaminaRegen
+	}
+	if c.Stamina < 0 {
+		c.Stamina = 0
+	}
+	if c.Stamina > MaxStamina {
+		c.Stamina = MaxStamina
+	}
+}
+
+// Move takes one step along the character's velocity. Each axis is moved
+// separately, so running diagonally into a wall slides along it.
+func (c *Char) Move(arena *Arena) {
 	if c.IsDead {
 		return
 	}
@@ -103,19 +234,12 @@ func (c *Char) Move() {
 	if normalized == 0 {
 		return
 	}
-	c.Px += float64(c.Vx) / normalized
-	if c.Px >= ScreenWidth-ScreenPadding {
-		c.Px = ScreenWidth - ScreenPadding - 1
-	}
-	if c.Px <= ScreenPadding {
-		c.Px = ScreenPadding + 1
-	}
-	c.Py += float64(c.Vy) / normalized
-	if c.Py >= ScreenHeight-ScreenPadding-10 {
-		c.Py = ScreenHeight - ScreenPadding - 11
+	speed := c.Speed + c.Boost
+	if x := c.Px + float64(c.Vx*speed)/normalized; !arena.Blocked(x, c.Py, CharRadius) {
+		c.Px = x
 	}
-	if c.Py <= ScreenPadding {
-		c.Py = ScreenPadding + 1
+	if y := c.Py + float64(c.Vy*speed)/normalized; !arena.Blocked(c.Px, y, CharRadius) {
+		c.Py = y
 	}
 	if c.Vx > 0 {
 		c.Fx = 1
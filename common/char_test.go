@@ -0,0 +1,146 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+func TestAttackPhases(t *testing.T) {
+	c := NewChar()
+	c.Timing = AttackTiming{WindUp: 2, Active: 1, Recovery: 2, Cooldown: 3}
+	c.ProcessInput(&pb.Input{ActionPressed: true, RightPressed: true})
+	if c.AttackPhase != AttackWindUp || c.Vx != 0 {
+		t.Fatalf("got phase %d and Vx %d after attacking", c.AttackPhase, c.Vx)
+	}
+
+	want := []AttackPhase{
+		AttackWindUp, AttackWindUp, AttackActive,
+		AttackRecovery, AttackRecovery,
+		AttackCooldown, AttackCooldown, AttackCooldown,
+		AttackIdle,
+	}
+	for tick, phase := range want {
+		if c.AttackPhase != phase {
+			t.Fatalf("tick %d: got phase %d, want %d", tick, c.AttackPhase, phase)
+		}
+		if c.Attacking() != (phase != AttackCooldown && phase != AttackIdle) {
+			t.Fatalf("tick %d: Attacking() = %v in phase %d", tick, c.Attacking(), phase)
+		}
+		if tick == 1 {
+			// pressing again mid-attack neither restarts it nor moves
+			c.ProcessInput(&pb.Input{ActionPressed: true, LeftPressed: true})
+		}
+		c.Attack()
+	}
+}
+
+func TestAttackHoldsMovement(t *testing.T) {
+	c := NewChar()
+	c.Timing = AttackTiming{WindUp: 1, Active: 1, Recovery: 1, Cooldown: 2}
+	c.ProcessInput(&pb.Input{ActionPressed: true})
+	c.ProcessInput(&pb.Input{DownPressed: true})
+	if c.Vy != 0 {
+		t.Fatal("moved during the wind-up")
+	}
+	for c.Attacking() {
+		c.Attack()
+	}
+	if c.AttackPhase != AttackCooldown || c.Vy != 1 {
+		t.Fatalf("got phase %d and Vy %d after recovery", c.AttackPhase, c.Vy)
+	}
+
+	// the cooldown blocks a new attack but not movement
+	c.ProcessInput(&pb.Input{ActionPressed: true, LeftPressed: true})
+	if c.Attacking() || c.Vx != -1 {
+		t.Fatalf("got phase %d and Vx %d during cooldown", c.AttackPhase, c.Vx)
+	}
+}
+
+func TestAttackSkipsEmptyPhases(t *testing.T) {
+	c := NewChar()
+	c.Timing = AttackTiming{Active: 1}
+	c.ProcessInput(&pb.Input{ActionPressed: true})
+	c.Attack()
+	if c.AttackPhase != AttackActive {
+		t.Fatalf("got phase %d, want the wind-up skipped", c.AttackPhase)
+	}
+	c.Attack()
+	if c.AttackPhase != AttackIdle {
+		t.Fatalf("got phase %d, want recovery and cooldown skipped", c.AttackPhase)
+	}
+}
+
+func TestAttackAnimFrame(t *testing.T) {
+	c := NewChar()
+	c.Timing = AttackTiming{WindUp: 4, Active: 2, Recovery: 2}
+	c.ProcessInput(&pb.Input{ActionPressed: true})
+	var frames []int
+	for c.Attacking() {
+		frames = append(frames, c.AttackAnimFrame())
+		c.Attack()
+	}
+	want := []int{0, 0, 1, 1, 2, 2, 3, 3}
+	if len(frames) != len(want) {
+		t.Fatalf("got frames %v, want %v", frames, want)
+	}
+	for i := range want {
+		if frames[i] != want[i] {
+			t.Fatalf("got frames %v, want %v", frames, want)
+		}
+	}
+}
+
+func TestSprint(t *testing.T) {
+	c := NewChar()
+	c.Px, c.Py = 100, 100
+	c.ProcessInput(&pb.Input{RightPressed: true, SprintPressed: true})
+	if !c.Exert() || c.Speed != SprintSpeed {
+		t.Fatalf("got speed %d, want to sprint", c.Speed)
+	}
+	c.Move(Arenas[DefaultArena])
+	if c.Px != 100+SprintSpeed || c.Stamina != MaxStamina-SprintCost {
+		t.Fatalf("got px %v and stamina %d after one sprinting tick", c.Px, c.Stamina)
+	}
+
+	// run until out of stamina
+	for c.Stamina > 0 {
+		if c.Exert() {
+			t.Fatalf("stopped sprinting with %d stamina left", c.Stamina)
+		}
+	}
+	if !c.Exert() || c.Speed != WalkSpeed {
+		t.Fatal("kept sprinting without stamina")
+	}
+
+	// too tired to start again until enough stamina is back
+	for c.Stamina < MinSprintStamina {
+		if c.Exert() {
+			t.Fatalf("sprinted again with %d stamina", c.Stamina)
+		}
+	}
+	if !c.Exert() {
+		t.Fatal("cannot sprint after resting")
+	}
+}
+
+func TestSprintNeedsMovement(t *testing.T) {
+	c := NewChar()
+	c.ProcessInput(&pb.Input{SprintPressed: true})
+	if c.Exert() || c.Speed != WalkSpeed || c.Stamina != MaxStamina {
+		t.Fatalf("got speed %d and stamina %d standing still", c.Speed, c.Stamina)
+	}
+}
+
+func TestBoost(t *testing.T) {
+	c := NewChar()
+	c.Px, c.Py = 100, 100
+	c.Boost = SpeedBoost
+	c.ProcessInput(&pb.Input{DownPressed: true})
+	c.Exert()
+	c.Move(Arenas[DefaultArena])
+	// a boost is free, unlike sprinting
+	if c.Py != 100+WalkSpeed+SpeedBoost || c.Stamina != MaxStamina {
+		t.Fatalf("got py %v and stamina %d after one boosted tick", c.Py, c.Stamina)
+	}
+}
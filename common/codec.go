@@ -0,0 +1,200 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/kisunji/ebiten-poc/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// ErrEmptyMessage is returned when a peer sends a zero-length message.
+	ErrEmptyMessage = errors.New("empty message")
+	// ErrMalformed is returned when the bytes are not a valid protobuf message.
+	ErrMalformed = errors.New("malformed message")
+	// ErrNoContent is returned when a message decodes but has no known content set.
+	ErrNoContent = errors.New("message has no content")
+	// ErrOutOfRange is returned when a message references an entity or slot
+	// that cannot exist.
+	ErrOutOfRange = errors.New("index out of range")
+)
+
+// DecodeError describes a message that could not be decoded.
+// Errors.Is can be used to match it against the Err* sentinels.
+type DecodeError struct {
+	Size int
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding %d bytes: %v", e.Size, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeStats counts decode results. It is safe for concurrent use.
+type DecodeStats struct {
+	decoded    uint64
+	empty      uint64
+	malformed  uint64
+	noContent  uint64
+	outOfRange uint64
+}
+
+// DecodeCounts is a point-in-time copy of DecodeStats.
+type DecodeCounts struct {
+	Decoded    uint64
+	Empty      uint64
+	Malformed  uint64
+	NoContent  uint64
+	OutOfRange uint64
+}
+
+// Record counts the result of a decode. A nil err counts as decoded.
+func (s *DecodeStats) Record(err error) {
+	switch {
+	case err == nil:
+		atomic.AddUint64(&s.decoded, 1)
+	case errors.Is(err, ErrEmptyMessage):
+		atomic.AddUint64(&s.empty, 1)
+	case errors.Is(err, ErrNoContent):
+		atomic.AddUint64(&s.noContent, 1)
+	case errors.Is(err, ErrOutOfRange):
+		atomic.AddUint64(&s.outOfRange, 1)
+	default:
+		atomic.AddUint64(&s.malformed, 1)
+	}
+}
+
+func (s *DecodeStats) Counts() DecodeCounts {
+	return DecodeCounts{
+		Decoded:    atomic.LoadUint64(&s.decoded),
+		Empty:      atomic.LoadUint64(&s.empty),
+		Malformed:  atomic.LoadUint64(&s.malformed),
+		NoContent:  atomic.LoadUint64(&s.noContent),
+		OutOfRange: atomic.LoadUint64(&s.outOfRange),
+	}
+}
+
+// Failed returns the total number of messages that could not be decoded.
+func (c DecodeCounts) Failed() uint64 {
+	return c.Empty + c.Malformed + c.NoContent + c.OutOfRange
+}
+
+// DecodeClientMessage unmarshals a message sent by a game client.
+// All errors are of type *DecodeError.
+func DecodeClientMessage(data []byte) (*pb.ClientMessage, error) {
+	if len(data) == 0 {
+		return nil, &DecodeError{Err: ErrEmptyMessage}
+	}
+	msg := &pb.ClientMessage{}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, &DecodeError{Size: len(data), Err: fmt.Errorf("%w: %v", ErrMalformed, err)}
+	}
+	switch msg.Content.(type) {
+	case *pb.ClientMessage_Input,
+		*pb.ClientMessage_StartGame,
+		*pb.ClientMessage_WorldUpdate,
+		*pb.ClientMessage_SelectMap,
+		*pb.ClientMessage_SetCapacity:
+	default:
+		return nil, &DecodeError{Size: len(data), Err: ErrNoContent}
+	}
+	return msg, nil
+}
+
+// DecodeServerMessage unmarshals a message sent by the server and checks
+// that every index it carries is safe to use against client-side state.
+// All errors are of type *DecodeError.
+func DecodeServerMessage(data []byte) (*pb.ServerMessage, error) {
+	if len(data) == 0 {
+		return nil, &DecodeError{Err: ErrEmptyMessage}
+	}
+	msg := &pb.ServerMessage{}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, &DecodeError{Size: len(data), Err: fmt.Errorf("%w: %v", ErrMalformed, err)}
+	}
+	if err := validateServerMessage(msg); err != nil {
+		return nil, &DecodeError{Size: len(data), Err: err}
+	}
+	return msg, nil
+}
+
+func validateServerMessage(msg *pb.ServerMessage) error {
+	switch content := msg.Content.(type) {
+	case *pb.ServerMessage_ConnectResponse:
+		return checkIndex(content.ConnectResponse.ClientSlot, MaxClients)
+	case *pb.ServerMessage_UpdateLobby:
+		ul := content.UpdateLobby
+		if len(ul.ConnectedSlots) > MaxClients || ul.Crowd < 0 || ul.Crowd > MaxChars {
+			return ErrOutOfRange
+		}
+		return checkIndex(ul.HostSlot, MaxClients)
+	case *pb.ServerMessage_PlayerDisconnected:
+		return checkIndex(content.PlayerDisconnected.Id, MaxClients)
+	case *pb.ServerMessage_NewHost:
+		return checkIndex(content.NewHost.Id, MaxClients)
+	case *pb.ServerMessage_UpdateEntity:
+		return checkEntity(content.UpdateEntity)
+	case *pb.ServerMessage_UpdateEntities:
+		for _, ue := range content.UpdateEntities.UpdateEntity {
+			if err := checkEntity(ue); err != nil {
+				return err
+			}
+		}
+	case *pb.ServerMessage_NewCoin:
+		return checkIndex(content.NewCoin.Index, MaxCoins)
+	case *pb.ServerMessage_CoinGot:
+		return checkIndex(content.CoinGot.Index, MaxCoins)
+	case *pb.ServerMessage_CoinGone:
+		return checkIndex(content.CoinGone.Index, MaxCoins)
+	case *pb.ServerMessage_GameEnd:
+		if len(content.GameEnd.Score) > MaxClients || len(content.GameEnd.Kills) > MaxClients {
+			return ErrOutOfRange
+		}
+		return checkIndex(content.GameEnd.Survivor, MaxClients)
+	case *pb.ServerMessage_PlayerKilled:
+		if err := checkIndex(content.PlayerKilled.Killer, MaxClients); err != nil {
+			return err
+		}
+		return checkIndex(content.PlayerKilled.Victim, MaxClients)
+	case *pb.ServerMessage_PlayerPenalized:
+		return checkIndex(content.PlayerPenalized.Index, MaxClients)
+	case *pb.ServerMessage_NewPickup:
+		if err := checkIndex(content.NewPickup.Index, MaxPickups); err != nil {
+			return err
+		}
+		return checkIndex(content.NewPickup.Kind, NumPickupKinds)
+	case *pb.ServerMessage_PickupGot:
+		return checkIndex(content.PickupGot.Index, MaxPickups)
+	case *pb.ServerMessage_GameStart:
+		gs := content.GameStart
+		if gs.Players < 0 || gs.Players > MaxClients || gs.Crowd < gs.Players || gs.Crowd > MaxChars {
+			return ErrOutOfRange
+		}
+	case *pb.ServerMessage_ConnectError,
+		*pb.ServerMessage_TimeSync,
+		*pb.ServerMessage_ServerNotice:
+	default:
+		return ErrNoContent
+	}
+	return nil
+}
+
+func checkEntity(ue *pb.UpdateEntity) error {
+	if err := checkIndex(ue.Index, MaxChars); err != nil {
+		return err
+	}
+	return checkIndex(ue.AttackPhase, NumAttackPhases)
+}
+
+func checkIndex(i int32, n int) error {
+	if i < 0 || int(i) >= n {
+		return ErrOutOfRange
+	}
+	return nil
+}
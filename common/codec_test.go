@@ -0,0 +1,155 @@
+package common
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kisunji/ebiten-poc/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+func mustMarshal(tb testing.TB, m proto.Message) []byte {
+	tb.Helper()
+	b, err := proto.Marshal(m)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return b
+}
+
+func TestDecodeClientMessage(t *testing.T) {
+	valid := mustMarshal(t, &pb.ClientMessage{
+		Content: &pb.ClientMessage_Input{Input: &pb.Input{UpPressed: true}},
+	})
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"valid", valid, nil},
+		{"empty", nil, ErrEmptyMessage},
+		{"garbage", []byte{0xff, 0xff, 0xff}, ErrMalformed},
+		{"zero value", mustMarshal(t, &pb.ClientMessage{}), ErrEmptyMessage},
+		{"unknown field only", []byte{0x78, 0x01}, ErrNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeClientMessage(tt.data)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			var de *DecodeError
+			if err != nil && !errors.As(err, &de) {
+				t.Fatalf("got %T, want *DecodeError", err)
+			}
+		})
+	}
+}
+
+func TestDecodeServerMessageOutOfRange(t *testing.T) {
+	msgs := []*pb.ServerMessage{
+		{Content: &pb.ServerMessage_UpdateEntity{UpdateEntity: &pb.UpdateEntity{Index: MaxChars}}},
+		{Content: &pb.ServerMessage_UpdateEntity{UpdateEntity: &pb.UpdateEntity{Index: -1}}},
+		{Content: &pb.ServerMessage_UpdateEntity{UpdateEntity: &pb.UpdateEntity{AttackPhase: int32(NumAttackPhases)}}},
+		{Content: &pb.ServerMessage_PlayerDisconnected{PlayerDisconnected: &pb.PlayerDisconnected{Id: MaxClients}}},
+		{Content: &pb.ServerMessage_CoinGot{CoinGot: &pb.CoinGot{Index: -3}}},
+		{Content: &pb.ServerMessage_NewCoin{NewCoin: &pb.NewCoin{Index: MaxCoins}}},
+		{Content: &pb.ServerMessage_CoinGone{CoinGone: &pb.CoinGone{Index: -1}}},
+		{Content: &pb.ServerMessage_UpdateLobby{UpdateLobby: &pb.UpdateLobby{
+			ConnectedSlots: make([]bool, MaxClients+1),
+		}}},
+		{Content: &pb.ServerMessage_UpdateLobby{UpdateLobby: &pb.UpdateLobby{Crowd: MaxChars + 1}}},
+		{Content: &pb.ServerMessage_PlayerKilled{PlayerKilled: &pb.PlayerKilled{Killer: 1, Victim: MaxClients}}},
+		{Content: &pb.ServerMessage_PlayerKilled{PlayerKilled: &pb.PlayerKilled{Killer: -1, Victim: 0}}},
+		{Content: &pb.ServerMessage_GameEnd{GameEnd: &pb.GameEnd{Kills: make([]int32, MaxClients+1)}}},
+		{Content: &pb.ServerMessage_PlayerPenalized{PlayerPenalized: &pb.PlayerPenalized{Index: MaxClients}}},
+		{Content: &pb.ServerMessage_GameStart{GameStart: &pb.GameStart{Players: MaxClients + 1, Crowd: MaxChars}}},
+		{Content: &pb.ServerMessage_GameStart{GameStart: &pb.GameStart{Players: 8, Crowd: 4}}},
+		{Content: &pb.ServerMessage_NewPickup{NewPickup: &pb.NewPickup{Index: MaxPickups}}},
+		{Content: &pb.ServerMessage_NewPickup{NewPickup: &pb.NewPickup{Kind: int32(NumPickupKinds)}}},
+		{Content: &pb.ServerMessage_PickupGot{PickupGot: &pb.PickupGot{Index: -1}}},
+	}
+	for _, m := range msgs {
+		_, err := DecodeServerMessage(mustMarshal(t, m))
+		if !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("%v: got %v, want ErrOutOfRange", m, err)
+		}
+	}
+}
+
+func TestDecodeStats(t *testing.T) {
+	var s DecodeStats
+	s.Record(nil)
+	s.Record(&DecodeError{Err: ErrEmptyMessage})
+	s.Record(&DecodeError{Err: ErrOutOfRange})
+	c := s.Counts()
+	if c.Decoded != 1 || c.Empty != 1 || c.OutOfRange != 1 || c.Failed() != 2 {
+		t.Fatalf("unexpected counts %+v", c)
+	}
+}
+
+func FuzzDecodeClientMessage(f *testing.F) {
+	f.Add(mustMarshal(f, &pb.ClientMessage{Content: &pb.ClientMessage_Input{Input: &pb.Input{ActionPressed: true}}}))
+	f.Add(mustMarshal(f, &pb.ClientMessage{Content: &pb.ClientMessage_StartGame{StartGame: &pb.StartGame{}}}))
+	f.Add(mustMarshal(f, &pb.ClientMessage{Content: &pb.ClientMessage_WorldUpdate{WorldUpdate: &pb.WorldUpdate{}}}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msg, err := DecodeClientMessage(data)
+		if err != nil {
+			return
+		}
+		if msg.Content == nil {
+			t.Fatal("decoded message without content")
+		}
+		if input := msg.GetInput(); input != nil {
+			NewChar().ProcessInput(input)
+		}
+	})
+}
+
+// FuzzServerMessage exercises the client's handling of server messages:
+// anything DecodeServerMessage accepts must be safe to apply.
+func FuzzServerMessage(f *testing.F) {
+	f.Add(mustMarshal(f, &pb.ServerMessage{Content: &pb.ServerMessage_UpdateEntity{
+		UpdateEntity: &pb.UpdateEntity{Index: 3, Px: 40, Py: 50, Vx: 1, AttackFrame: 20},
+	}}))
+	f.Add(mustMarshal(f, &pb.ServerMessage{Content: &pb.ServerMessage_UpdateEntities{
+		UpdateEntities: &pb.UpdateEntities{UpdateEntity: []*pb.UpdateEntity{{Index: 0}, {Index: MaxChars - 1}}},
+	}}))
+	f.Add(mustMarshal(f, &pb.ServerMessage{Content: &pb.ServerMessage_GameEnd{
+		GameEnd: &pb.GameEnd{Survivor: 2, Score: make([]int32, MaxClients), Kills: make([]int32, MaxClients)},
+	}}))
+	f.Add(mustMarshal(f, &pb.ServerMessage{Content: &pb.ServerMessage_UpdateLobby{
+		UpdateLobby: &pb.UpdateLobby{ConnectedSlots: make([]bool, MaxClients), HostSlot: 1},
+	}}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msg, err := DecodeServerMessage(data)
+		if err != nil {
+			return
+		}
+		chars := make(Chars, MaxChars)
+		slots := make([]bool, MaxClients)
+		switch content := msg.Content.(type) {
+		case *pb.ServerMessage_UpdateEntity:
+			chars.UpdateFromData(content.UpdateEntity)
+		case *pb.ServerMessage_UpdateEntities:
+			for _, ue := range content.UpdateEntities.UpdateEntity {
+				chars.UpdateFromData(ue)
+			}
+		case *pb.ServerMessage_PlayerDisconnected:
+			chars[content.PlayerDisconnected.Id] = nil
+			slots[content.PlayerDisconnected.Id] = false
+		case *pb.ServerMessage_NewHost:
+			_ = slots[content.NewHost.Id]
+		case *pb.ServerMessage_UpdateLobby:
+			copy(slots, content.UpdateLobby.ConnectedSlots)
+			_ = slots[content.UpdateLobby.HostSlot]
+		}
+		for _, c := range chars {
+			if c == nil {
+				continue
+			}
+			c.Attack()
+			c.Move(Arenas[DefaultArena])
+		}
+	})
+}
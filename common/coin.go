@@ -2,6 +2,11 @@ package common
 
 import "math/rand"
 
+// MaxCoins is the most coins a match can have lying around at once.
+const MaxCoins = 256
+
+// Coin is a coin lying in the arena. PickedUp is also set once it expires
+// uncollected.
 type Coin struct {
 	Px, Py       float64
 	PickupRadius float64
@@ -9,10 +14,16 @@ type Coin struct {
 	FrameOffset  int
 }
 
-func NewCoin() *Coin {
+// NewCoin places a coin on a random open tile of arena.
+func NewCoin(arena *Arena) *Coin {
+	return NewCoinAt(arena.RandomSpot())
+}
+
+// NewCoinAt places a coin at (x, y).
+func NewCoinAt(x, y float64) *Coin {
 	return &Coin{
-		Px:           float64(ScreenPadding + rand.Intn(ScreenWidth-ScreenPadding*5)),
-		Py:           float64(ScreenPadding + rand.Intn(ScreenHeight-ScreenPadding*5)),
+		Px:           x,
+		Py:           y,
 		PickupRadius: 10.0,
 		FrameOffset:  rand.Intn(3),
 	}
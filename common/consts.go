@@ -7,8 +7,29 @@ const (
 	ScreenHeight  = 320
 	ScreenPadding = 10
 
-	MaxChars   = 32
-	MaxClients = 8
+	// Most characters and players a room can have. Each room picks its
+	// own sizes up to these.
+	MaxChars   = 1024
+	MaxClients = 64
+	// Sizes rooms use unless configured otherwise.
+	DefaultCrowd   = 32
+	DefaultPlayers = 8
 
 	HitRadius = 12.0
+
+	// Pixels moved per tick.
+	WalkSpeed   = 1
+	SprintSpeed = 2
+
+	// Stamina is spent while sprinting and slowly regained otherwise.
+	MaxStamina   = 300
+	SprintCost   = 3
+	StaminaRegen = 1
+	// Least stamina needed to start sprinting, so a player who ran dry has
+	// to rest before running again.
+	MinSprintStamina = 60
 )
+
+// Version identifies the build in LAN announcements.
+// Override with -ldflags "-X github.com/kisunji/ebiten-poc/common.Version=...".
+var Version = "dev"
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+
+	"github.com/kisunji/ebiten-poc/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// DiscoveryPort is the UDP port servers announce themselves on.
+const DiscoveryPort = 47777
+
+// Prefix on every announcement so unrelated traffic on the port is ignored.
+var discoveryMagic = []byte("OOT1")
+
+// ErrNotAnnouncement is returned for packets that are not server announcements.
+var ErrNotAnnouncement = errors.New("not a server announcement")
+
+func MarshalAnnouncement(a *pb.Announcement) ([]byte, error) {
+	data, err := proto.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, discoveryMagic...), data...), nil
+}
+
+func ParseAnnouncement(data []byte) (*pb.Announcement, error) {
+	if !bytes.HasPrefix(data, discoveryMagic) {
+		return nil, ErrNotAnnouncement
+	}
+	a := &pb.Announcement{}
+	if err := proto.Unmarshal(data[len(discoveryMagic):], a); err != nil {
+		return nil, ErrNotAnnouncement
+	}
+	if a.Port <= 0 || a.Port > 65535 {
+		return nil, ErrNotAnnouncement
+	}
+	return a, nil
+}
+
+// ListenAnnouncements calls found for every announcement received on conn
+// until ctx is done, then closes conn. Other packets are skipped.
+func ListenAnnouncements(ctx context.Context, conn net.PacketConn, found func(from net.Addr, a *pb.Announcement)) error {
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		a, err := ParseAnnouncement(buf[:n])
+		if err != nil {
+			continue
+		}
+		found(from, a)
+	}
+}
@@ -0,0 +1,91 @@
+package common
+
+import "math"
+
+// Grid is a spatial hash over a rectangle. It sorts ids into square cells
+// by position so a query only looks at the cells around a point instead
+// of at everything. Positions outside the rectangle go in the nearest
+// edge cell.
+type Grid struct {
+	cellSize   float64
+	cols, rows int
+	cells      [][]int
+}
+
+// NewGrid covers a width by height rectangle with cells cellSize across.
+// Queries are cheapest when the cells are about as big as the radius
+// usually asked for.
+func NewGrid(width, height, cellSize float64) *Grid {
+	cols := int(math.Max(1, math.Ceil(width/cellSize)))
+	rows := int(math.Max(1, math.Ceil(height/cellSize)))
+	return &Grid{
+		cellSize: cellSize,
+		cols:     cols,
+		rows:     rows,
+		cells:    make([][]int, cols*rows),
+	}
+}
+
+// Clear removes every id, keeping the memory for reuse.
+func (g *Grid) Clear() {
+	for i := range g.cells {
+		g.cells[i] = g.cells[i][:0]
+	}
+}
+
+// Insert adds id at (x, y).
+func (g *Grid) Insert(id int, x, y float64) {
+	c := g.cell(x, y)
+	g.cells[c] = append(g.cells[c], id)
+}
+
+// Move updates id, inserted at (x0, y0), to be at (x1, y1).
+func (g *Grid) Move(id int, x0, y0, x1, y1 float64) {
+	from, to := g.cell(x0, y0), g.cell(x1, y1)
+	if from == to {
+		return
+	}
+	ids := g.cells[from]
+	for k, other := range ids {
+		if other == id {
+			g.cells[from] = append(ids[:k], ids[k+1:]...)
+			break
+		}
+	}
+	g.cells[to] = append(g.cells[to], id)
+}
+
+// Near appends to dst the ids in every cell within r of (x, y) and
+// returns it. The ids come in no particular order and some may be
+// further than r away, so callers still check the exact distance.
+func (g *Grid) Near(dst []int, x, y, r float64) []int {
+	x0, y0 := g.coords(x-r, y-r)
+	x1, y1 := g.coords(x+r, y+r)
+	for cy := y0; cy <= y1; cy++ {
+		for cx := x0; cx <= x1; cx++ {
+			dst = append(dst, g.cells[cy*g.cols+cx]...)
+		}
+	}
+	return dst
+}
+
+func (g *Grid) cell(x, y float64) int {
+	cx, cy := g.coords(x, y)
+	return cy*g.cols + cx
+}
+
+// coords returns the column and row of the cell holding (x, y).
+func (g *Grid) coords(x, y float64) (int, int) {
+	return clampCell(x/g.cellSize, g.cols), clampCell(y/g.cellSize, g.rows)
+}
+
+func clampCell(v float64, n int) int {
+	// written to also catch NaN
+	if !(v >= 0) {
+		return 0
+	}
+	if v >= float64(n) {
+		return n - 1
+	}
+	return int(v)
+}
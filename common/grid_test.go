@@ -0,0 +1,105 @@
+package common
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// scatter places n points at random across arena, some of them outside.
+func scatter(arena *Arena, n int) (xs, ys []float64) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < n; i++ {
+		xs = append(xs, r.Float64()*(arena.PixelWidth()+40)-20)
+		ys = append(ys, r.Float64()*(arena.PixelHeight()+40)-20)
+	}
+	return xs, ys
+}
+
+func within(x0, y0, x1, y1, r float64) bool {
+	dx, dy := x1-x0, y1-y0
+	return dx*dx+dy*dy <= r*r
+}
+
+func TestGridNear(t *testing.T) {
+	arena := Arenas[DefaultArena]
+	xs, ys := scatter(arena, 300)
+	g := NewGrid(arena.PixelWidth(), arena.PixelHeight(), 32)
+	for i := range xs {
+		g.Insert(i, xs[i], ys[i])
+	}
+	// move half of them somewhere else
+	for i := 0; i < len(xs); i += 2 {
+		x, y := xs[len(xs)-1-i], ys[i]
+		g.Move(i, xs[i], ys[i], x, y)
+		xs[i] = x
+	}
+	var near []int
+	for _, r := range []float64{0, HitRadius, 50, 500} {
+		for i := range xs {
+			var got, want []int
+			near = g.Near(near[:0], xs[i], ys[i], r)
+			for _, j := range near {
+				if within(xs[i], ys[i], xs[j], ys[j], r) {
+					got = append(got, j)
+				}
+			}
+			for j := range xs {
+				if within(xs[i], ys[i], xs[j], ys[j], r) {
+					want = append(want, j)
+				}
+			}
+			sort.Ints(got)
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("within %v of point %d: got %v, want %v", r, i, got, want)
+			}
+		}
+	}
+
+	g.Clear()
+	if near = g.Near(near[:0], 0, 0, 1000); len(near) != 0 {
+		t.Fatalf("cleared grid still holds %v", near)
+	}
+}
+
+// BenchmarkNeighbours finds everything within HitRadius of each of n
+// entities, as attacks and pickups do every tick, by checking every pair
+// and by building a grid and querying it.
+func BenchmarkNeighbours(b *testing.B) {
+	arena := Arenas["warehouse"]
+	for _, n := range []int{32, 256, 2048} {
+		xs, ys := scatter(arena, n)
+		b.Run(fmt.Sprintf("loop/%d", n), func(b *testing.B) {
+			for k := 0; k < b.N; k++ {
+				hits := 0
+				for i := range xs {
+					for j := range xs {
+						if i != j && within(xs[i], ys[i], xs[j], ys[j], HitRadius) {
+							hits++
+						}
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("grid/%d", n), func(b *testing.B) {
+			g := NewGrid(arena.PixelWidth(), arena.PixelHeight(), 32)
+			var near []int
+			for k := 0; k < b.N; k++ {
+				g.Clear()
+				for i := range xs {
+					g.Insert(i, xs[i], ys[i])
+				}
+				hits := 0
+				for i := range xs {
+					near = g.Near(near[:0], xs[i], ys[i], HitRadius)
+					for _, j := range near {
+						if i != j && within(xs[i], ys[i], xs[j], ys[j], HitRadius) {
+							hits++
+						}
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package common
+
+// PlayerRating is a named player's Elo rating.
+type PlayerRating struct {
+	Name    string  `json:"name"`
+	Rating  float64 `json:"rating"`
+	Matches int     `json:"matches"`
+	Wins    int     `json:"wins"`
+}
+
+// RankedPlayer is a rating with its place on the leaderboard.
+type RankedPlayer struct {
+	Rank int `json:"rank"`
+	PlayerRating
+}
+
+// Leaderboard lists the best rated players and, if asked for, where one
+// player stands. Servers serve it as JSON.
+type Leaderboard struct {
+	Top    []RankedPlayer `json:"top"`
+	Player *RankedPlayer  `json:"player,omitempty"`
+}
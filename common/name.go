@@ -0,0 +1,32 @@
+package common
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxNameLen is the longest player name, in characters.
+const MaxNameLen = 12
+
+// CleanName trims a player name to MaxNameLen letters, digits, spaces,
+// '-' and '_'. Other characters are dropped. An empty result means the
+// player is anonymous.
+func CleanName(name string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range strings.TrimSpace(name) {
+		if n == MaxNameLen {
+			break
+		}
+		if IsNameRune(r) {
+			b.WriteRune(r)
+			n++
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// IsNameRune reports whether r may appear in a player name.
+func IsNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' || r == '-' || r == '_'
+}
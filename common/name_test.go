@@ -0,0 +1,21 @@
+package common
+
+import "testing"
+
+func TestCleanName(t *testing.T) {
+	tests := map[string]string{
+		"":                      "",
+		"  alice  ":             "alice",
+		"bob_the-2nd":           "bob_the-2nd",
+		"<script>":              "script",
+		"averyveryverylongname": "averyveryver",
+		"zoë":                   "zoë",
+		"a\nb\tc":               "abc",
+		"   ---   ":             "---",
+	}
+	for in, want := range tests {
+		if got := CleanName(in); got != want {
+			t.Errorf("CleanName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package common
+
+// PickupKind is what a power-up does for the player who collects it.
+type PickupKind int32
+
+const (
+	// Moves faster for a while.
+	PickupSpeed PickupKind = iota
+	// Swaps places with the nearest AI, leaving it to take the blame.
+	PickupDisguise
+	// Attacks reach further for a while.
+	PickupReach
+	// Sends a clone walking off the way the player was heading.
+	PickupDecoy
+
+	NumPickupKinds = int(PickupDecoy) + 1
+)
+
+const (
+	// Most power-ups lying in the arena at once.
+	MaxPickups = 8
+	// How close a player has to get to collect a power-up.
+	PickupRadius = 10.0
+	// Extra pixels per tick while a speed pickup lasts.
+	SpeedBoost = 1
+	// How much further attacks reach while a reach pickup lasts.
+	ReachBoost = 1.75
+	// How far away an AI can be to swap places with.
+	DisguiseRange = 80.0
+)
+
+func (k PickupKind) String() string {
+	switch k {
+	case PickupSpeed:
+		return "speed"
+	case PickupDisguise:
+		return "disguise"
+	case PickupReach:
+		return "reach"
+	case PickupDecoy:
+		return "decoy"
+	}
+	return "unknown"
+}
+
+// Pickup is a power-up lying in the arena.
+type Pickup struct {
+	Kind     PickupKind
+	Px, Py   float64
+	PickedUp bool
+}
+
+// NewPickup places a pickup of kind on a random open tile of arena.
+func NewPickup(arena *Arena, kind PickupKind) *Pickup {
+	x, y := arena.RandomSpot()
+	return &Pickup{Kind: kind, Px: x, Py: y}
+}
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+// Servers not heard from for this long drop off the list.
+const serverExpiry = 10 * time.Second
+
+// DiscoveredServer is a server found on the local network.
+type DiscoveredServer struct {
+	Name     string
+	Addr     string // host:port
+	Secure   bool
+	Version  string
+	Rooms    int
+	MaxRooms int
+	Players  int
+	Slots    int
+	seen     time.Time
+}
+
+func (d DiscoveredServer) String() string {
+	return fmt.Sprintf("%s %s %d/%d players", d.Name, d.Addr, d.Players, d.Slots)
+}
+
+// ServerBrowser collects LAN announcements in the background.
+type ServerBrowser struct {
+	mu      sync.Mutex
+	servers map[string]DiscoveredServer
+}
+
+func NewServerBrowser() *ServerBrowser {
+	return &ServerBrowser{
+		servers: make(map[string]DiscoveredServer),
+	}
+}
+
+// Listen collects announcements until ctx is done. Browsers cannot receive
+// UDP, so in wasm this logs once and returns.
+func (b *ServerBrowser) Listen(ctx context.Context) {
+	conn, err := net.ListenPacket("udp4", fmt.Sprintf(":%d", common.DiscoveryPort))
+	if err != nil {
+		log.Println("server browser disabled:", err)
+		return
+	}
+	err = common.ListenAnnouncements(ctx, conn, b.add)
+	if err != nil {
+		log.Println("server browser:", err)
+	}
+}
+
+func (b *ServerBrowser) add(from net.Addr, a *pb.Announcement) {
+	host, _, err := net.SplitHostPort(from.String())
+	if err != nil {
+		return
+	}
+	d := DiscoveredServer{
+		Name:     a.Name,
+		Addr:     net.JoinHostPort(host, strconv.Itoa(int(a.Port))),
+		Secure:   a.Secure,
+		Version:  a.Version,
+		Rooms:    len(a.Rooms),
+		MaxRooms: int(a.MaxRooms),
+		seen:     time.Now(),
+	}
+	for _, r := range a.Rooms {
+		d.Players += int(r.Players)
+		d.Slots += int(r.Slots)
+	}
+	b.mu.Lock()
+	b.servers[d.Addr] = d
+	b.mu.Unlock()
+}
+
+// Servers returns the servers heard from recently, sorted by name.
+func (b *ServerBrowser) Servers() []DiscoveredServer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var list []DiscoveredServer
+	for addr, d := range b.servers {
+		if time.Since(d.seen) > serverExpiry {
+			delete(b.servers, addr)
+			continue
+		}
+		list = append(list, d)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name == list[j].Name {
+			return list[i].Addr < list[j].Addr
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
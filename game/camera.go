@@ -0,0 +1,19 @@
+package game
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kisunji/ebiten-poc/game/state"
+)
+
+// Camera is the part of the arena shown on screen.
+type Camera struct {
+	state.Camera
+}
+
+// Translate moves geoM from world to screen pixels. The offset is rounded
+// so pixel art stays sharp while the camera glides.
+func (c *Camera) Translate(geoM *ebiten.GeoM) {
+	geoM.Translate(-math.Round(c.X), -math.Round(c.Y))
+}
@@ -2,9 +2,15 @@ package game
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/url"
 	"time"
 
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/game/state"
+	"github.com/kisunji/ebiten-poc/pb"
+	"github.com/kisunji/ebiten-poc/transport"
 	"nhooyr.io/websocket"
 )
 
@@ -23,16 +29,26 @@ type Client struct {
 	// Outbound messages to the server.
 	Send chan []byte
 
-	// Disconnect
+	// Signalled once when the connection started by Listen is lost.
 	Disconnect chan bool
-	conn       *websocket.Conn
+	conn       transport.Conn
 	Latency    int64
+	// Results of decoding server messages.
+	DecodeStats common.DecodeStats
+	// Player name sent when dialing, may be empty.
+	Name string
+	// Slot the server gave this player, known once the lobby is joined.
+	Slot int32
+	// Map of the match being played, known once it starts.
+	Map string
+	// Size of the match being played, known once it starts.
+	PlayerSlots, Crowd int
 }
 
 func NewClient() *Client {
 	return &Client{
 		Recv:       make(chan []byte, 256),
-		Disconnect: make(chan bool),
+		Disconnect: make(chan bool, 1),
 		Send:       make(chan []byte, 256),
 	}
 }
@@ -40,47 +56,111 @@ func NewClient() *Client {
 func (c *Client) Dial(addr string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
-	conn, _, err := websocket.Dial(ctx, "ws://"+addr+"/ws", nil)
+	conn, _, err := websocket.Dial(ctx, c.url("ws://", addr), nil)
 	if err != nil {
 		return err
 	}
-	c.conn = conn
+	c.conn = &wsConn{conn: conn}
 	return nil
 }
 
 func (c *Client) DialTLS(addr string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
-	conn, _, err := websocket.Dial(ctx, "wss://"+addr+"/ws", nil)
+	conn, _, err := websocket.Dial(ctx, c.url("wss://", addr), nil)
 	if err != nil {
 		return err
 	}
-	c.conn = conn
+	c.conn = &wsConn{conn: conn}
 	return nil
 }
 
+func (c *Client) url(scheme, addr string) string {
+	u := scheme + addr + "/ws"
+	if c.Name != "" {
+		u += "?name=" + url.QueryEscape(c.Name)
+	}
+	return u
+}
+
+// Connect uses an already established connection, such as one end of
+// transport.Pipe, instead of dialing.
+func (c *Client) Connect(conn transport.Conn) {
+	c.conn = conn
+}
+
+// Listen starts pumping messages over the connection. Each connection
+// gets its own Disconnect channel, so pumps that stop late cannot signal
+// or close the next one.
 func (c *Client) Listen(ctx context.Context) {
-	go c.writePump(ctx)
-	go c.readPump(ctx)
+	conn := c.conn
+	disconnect := make(chan bool, 1)
+	c.Disconnect = disconnect
+	// closed when readPump stops, so writePump does not take messages
+	// meant for the next connection
+	done := make(chan struct{})
+	go c.writePump(ctx, conn, done, disconnect)
+	go c.readPump(ctx, conn, done, disconnect)
+}
+
+// disconnected signals disconnect without blocking. Both pumps call it
+// when they stop and only the first signal is kept.
+func disconnected(disconnect chan<- bool) {
+	select {
+	case disconnect <- true:
+	default:
+	}
 }
 
 func (c *Client) SendMessage(message []byte) {
 	c.Send <- message
 }
 
+// Decode decodes a message received from the server and records the result.
+func (c *Client) Decode(data []byte) (*pb.ServerMessage, error) {
+	msg, err := common.DecodeServerMessage(data)
+	c.DecodeStats.Record(err)
+	return msg, err
+}
+
+// Receive passes the messages that have arrived to apply. It reports
+// false once the connection is lost, dropping it first if the server
+// sent something that cannot be decoded.
+func (c *Client) Receive(apply func(*pb.ServerMessage)) bool {
+	err := state.Receive(c.Recv, c.Disconnect, c.Decode, apply)
+	if errors.Is(err, state.ErrDisconnected) {
+		log.Println(err)
+		return false
+	}
+	if err != nil {
+		log.Println("dropping connection:", err)
+		c.Drop("malformed message")
+		return false
+	}
+	return true
+}
+
+// Drop closes the connection after the server sent something unusable.
+func (c *Client) Drop(reason string) {
+	if c.conn == nil {
+		return
+	}
+	c.conn.Close(transport.StatusUnsupportedData, reason)
+}
+
 // readPump pumps messages from the websocket connection to the hub.
 //
 // The application runs readPump in a per-connection goroutine. The application
 // ensures that there is at most one reader on a connection by executing all
 // reads from this goroutine.
-func (c *Client) readPump(ctx context.Context) {
+func (c *Client) readPump(ctx context.Context, conn transport.Conn, done chan<- struct{}, disconnect chan<- bool) {
 	defer func() {
-		c.conn.Close(websocket.StatusInternalError, "unexpected close")
-		c.Disconnect <- true
+		conn.Close(transport.StatusInternalError, "unexpected close")
+		close(done)
+		disconnected(disconnect)
 	}()
-	// c.conn.SetReadLimit(maxMessageSize)
 	for {
-		_, buf, err := c.conn.Read(ctx)
+		buf, err := conn.Read(ctx)
 		if err != nil {
 			break
 		}
@@ -94,35 +174,31 @@ func (c *Client) readPump(ctx context.Context) {
 // A goroutine running writePump is started for each connection. The
 // application ensures that there is at most one writer to a connection by
 // executing all writes from this goroutine.
-func (c *Client) writePump(ctx context.Context) {
+func (c *Client) writePump(ctx context.Context, conn transport.Conn, done <-chan struct{}, disconnect chan<- bool) {
 	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
-		c.conn.Close(websocket.StatusInternalError, "unexpected close")
-		c.Disconnect <- true
+		conn.Close(transport.StatusInternalError, "unexpected close")
+		disconnected(disconnect)
 	}()
 	for {
 		select {
+		case <-done:
+			return
 		case message, ok := <-c.Send:
 			if !ok {
 				// The hub closed the channel.
-				c.conn.Write(ctx, websocket.MessageText, []byte("closed"))
+				conn.Close(transport.StatusNormalClosure, "closed")
 				log.Println("server closed")
 				return
 			}
 
-			w, err := c.conn.Writer(ctx, websocket.MessageBinary)
-			if err != nil {
-				return
-			}
-			w.Write(message)
-
-			if err := w.Close(); err != nil {
+			if err := conn.Write(ctx, message); err != nil {
 				return
 			}
 		case <-ticker.C:
 			lastPinged := time.Now().UnixNano()
-			err := c.conn.Ping(ctx)
+			err := conn.Ping(ctx)
 			if err != nil {
 				c.Latency = 999
 				return
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kisunji/ebiten-poc/common"
+)
+
+// fogColor covers whatever lies beyond the local player's vision.
+var fogColor = color.RGBA{R: 8, G: 6, B: 14, A: 235}
+
+// fogEdge is the fraction of the vision radius over which the darkness
+// fades in.
+const fogEdge = .25
+
+// Fog darkens the screen outside the local player's vision radius.
+type Fog struct {
+	// Opaque inside the radius and fading out towards its edge, for
+	// cutting a hole in the darkness. Rebuilt when the radius changes.
+	light  *ebiten.Image
+	radius float64
+	// The darkness is put together here each frame.
+	dark *ebiten.Image
+}
+
+// Draw darkens screen except within radius world pixels of (x, y).
+func (f *Fog) Draw(screen *ebiten.Image, x, y, radius float64, camera *Camera) {
+	if f.light == nil || f.radius != radius {
+		f.light, f.radius = drawLight(radius), radius
+	}
+	if f.dark == nil {
+		f.dark = ebiten.NewImage(common.ScreenWidth, common.ScreenHeight)
+	}
+	f.dark.Fill(fogColor)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(x-radius, y-radius)
+	camera.Translate(&op.GeoM)
+	op.CompositeMode = ebiten.CompositeModeDestinationOut
+	f.dark.DrawImage(f.light, op)
+	screen.DrawImage(f.dark, nil)
+}
+
+// drawLight renders a disc of the given radius whose alpha falls from
+// full to none across its outer edge.
+func drawLight(radius float64) *ebiten.Image {
+	size := int(math.Ceil(radius * 2))
+	pix := make([]byte, size*size*4)
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			d := math.Hypot(float64(px)+.5-radius, float64(py)+.5-radius)
+			a := (radius - d) / (radius * fogEdge)
+			a = math.Max(0, math.Min(1, a))
+			// premultiplied white
+			v := byte(a * 0xff)
+			i := (py*size + px) * 4
+			pix[i], pix[i+1], pix[i+2], pix[i+3] = v, v, v, v
+		}
+	}
+	img := ebiten.NewImage(size, size)
+	img.ReplacePixels(pix)
+	return img
+}
@@ -12,6 +12,7 @@ var (
 	titleFont font.Face
 	menuFont  font.Face
 	smallFont font.Face
+	tinyFont  font.Face
 )
 
 func init() {
@@ -47,4 +48,13 @@ func init() {
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	tinyFont, err = opentype.NewFace(tt, &opentype.FaceOptions{
+		Size:    8,
+		DPI:     dpi,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 }
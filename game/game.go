@@ -16,11 +16,14 @@ const (
 	SceneLobby
 	SceneMainGame
 	SceneNotConnected
+	SceneLeaderboard
 )
 
 type Game struct {
-	Client        *Client
-	Scene         Scene
+	Client *Client
+	Scene  Scene
+	// ServerAddr overrides the saved server address when set.
+	ServerAddr    string
 	SceneHandlers map[Scene]SceneHandler
 
 	inited bool
@@ -32,6 +35,7 @@ type input struct {
 	LeftPressed   bool
 	RightPressed  bool
 	ActionPressed bool
+	SprintPressed bool
 }
 
 func (g *Game) init() {
@@ -43,10 +47,11 @@ func (g *Game) init() {
 	}
 	go d.debounce()
 	g.SceneHandlers = map[Scene]SceneHandler{
-		SceneStartMenu:    &StartMenu{client: g.Client},
+		SceneStartMenu:    NewStartMenu(g.Client, g.ServerAddr),
 		SceneNotConnected: &NotConnected{},
 		SceneLobby:        NewLobby(g.Client),
 		SceneMainGame:     NewMainGame(g.Client, d),
+		SceneLeaderboard:  NewLeaderboardScene(),
 	}
 	g.inited = true
 }
@@ -72,6 +77,10 @@ func (g *Game) Update() error {
 		handler := g.SceneHandlers[g.Scene]
 		handler.Update()
 		g.Scene = handler.Next()
+	case SceneLeaderboard:
+		handler := g.SceneHandlers[g.Scene]
+		handler.Update()
+		g.Scene = handler.Next()
 	}
 	return nil
 }
@@ -93,6 +102,8 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		g.SceneHandlers[g.Scene].Draw(screen)
 	case SceneMainGame:
 		g.SceneHandlers[g.Scene].Draw(screen)
+	case SceneLeaderboard:
+		g.SceneHandlers[g.Scene].Draw(screen)
 	}
 
 	msg := fmt.Sprintf("TPS: %0.2f\nFPS: %0.2f\nPing: %dms\n",
@@ -0,0 +1,135 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/kisunji/ebiten-poc/common"
+)
+
+const leaderboardSize = 10
+
+// fetchLeaderboard asks the saved server for its best players and the
+// rank of the player called name.
+func fetchLeaderboard(s Settings) (common.Leaderboard, error) {
+	scheme := "http://"
+	if s.Secure {
+		scheme = "https://"
+	}
+	u := fmt.Sprintf("%s%s/leaderboard?limit=%d", scheme, s.ServerAddr, leaderboardSize)
+	if s.Name != "" {
+		u += "&player=" + url.QueryEscape(s.Name)
+	}
+	var lb common.Leaderboard
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(u)
+	if err != nil {
+		return lb, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return lb, fmt.Errorf("leaderboard: %s", resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&lb)
+	return lb, err
+}
+
+type leaderboardResult struct {
+	board common.Leaderboard
+	err   error
+}
+
+// LeaderboardScene shows the best rated players on the saved server.
+type LeaderboardScene struct {
+	// Set while a request is in flight.
+	loading chan leaderboardResult
+	board   *common.Leaderboard
+	err     error
+	name    string
+
+	backText    string
+	backPressed bool
+	next        Scene
+}
+
+func NewLeaderboardScene() *LeaderboardScene {
+	return &LeaderboardScene{next: SceneLeaderboard}
+}
+
+func (l *LeaderboardScene) Update() {
+	l.next = SceneLeaderboard
+	if l.loading == nil && l.board == nil && l.err == nil {
+		settings := LoadSettings()
+		l.name = settings.Name
+		l.loading = make(chan leaderboardResult, 1)
+		go func(done chan<- leaderboardResult) {
+			board, err := fetchLeaderboard(settings)
+			done <- leaderboardResult{board: board, err: err}
+		}(l.loading)
+	}
+	select {
+	case res := <-l.loading:
+		l.loading = nil
+		l.board, l.err = &res.board, res.err
+	default:
+	}
+
+	l.backText = "BACK"
+	x, y := ebiten.CursorPosition()
+	if x > 40 && x < 120 && y > common.ScreenHeight-46 && y < common.ScreenHeight-30 {
+		l.backText = ">BACK"
+		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			l.backPressed = true
+		} else if l.backPressed {
+			l.backPressed = false
+			l.back()
+		}
+	} else {
+		l.backPressed = false
+	}
+}
+
+// back returns to the start menu. The leaderboard is fetched again next
+// time it is shown.
+func (l *LeaderboardScene) back() {
+	l.board, l.err = nil, nil
+	l.next = SceneStartMenu
+}
+
+func (l *LeaderboardScene) Draw(screen *ebiten.Image) {
+	text.Draw(screen, "RANKS", menuFont, 40, 50, color.White)
+	switch {
+	case l.err != nil:
+		text.Draw(screen, "cannot load leaderboard", tinyFont, 45, 80, color.White)
+	case l.board == nil:
+		text.Draw(screen, "loading...", tinyFont, 45, 80, color.Gray{Y: 200})
+	case len(l.board.Top) == 0:
+		text.Draw(screen, "no rated matches yet", tinyFont, 45, 80, color.Gray{Y: 200})
+	default:
+		for i, p := range l.board.Top {
+			clr := color.Color(color.Gray{Y: 200})
+			if p.Name == l.name {
+				clr = color.White
+			}
+			text.Draw(screen, rankLine(p), tinyFont, 45, 80+i*14, clr)
+		}
+		if p := l.board.Player; p != nil {
+			text.Draw(screen, "YOU "+rankLine(*p), tinyFont, 45, 80+(leaderboardSize+1)*14, color.White)
+		}
+	}
+	text.Draw(screen, l.backText, smallFont, 45, common.ScreenHeight-30, color.White)
+}
+
+func rankLine(p common.RankedPlayer) string {
+	return fmt.Sprintf("%3d. %-*s %4.0f  %dW/%d", p.Rank, common.MaxNameLen, p.Name, p.Rating, p.Wins, p.Matches)
+}
+
+func (l *LeaderboardScene) Next() Scene {
+	return l.next
+}
@@ -0,0 +1,95 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/game/state"
+)
+
+const (
+	// Largest size of the minimap on screen.
+	minimapWidth  = 96
+	minimapHeight = 64
+)
+
+var (
+	minimapBackground = color.RGBA{R: 20, G: 20, B: 28, A: 180}
+	minimapColors     = map[state.MarkKind]color.Color{
+		state.MarkWall: color.RGBA{R: 130, G: 120, B: 150, A: 255},
+		state.MarkCoin: color.RGBA{R: 250, G: 210, B: 60, A: 255},
+		state.MarkView: color.RGBA{R: 255, G: 255, B: 255, A: 80},
+		state.MarkYou:  color.White,
+	}
+)
+
+// Minimap is a small overview of the arena in the bottom right corner. It
+// shows walls, coins, what the camera sees and the local player, but no
+// other characters so it gives nobody away.
+type Minimap struct {
+	Hidden bool
+
+	layout state.MinimapLayout
+	// Background and walls, drawn once per arena.
+	walls *ebiten.Image
+	// The minimap is put together here each frame.
+	img   *ebiten.Image
+	marks []state.Mark
+}
+
+// Toggle shows or hides the minimap.
+func (m *Minimap) Toggle() {
+	m.Hidden = !m.Hidden
+}
+
+// setArena prepares the minimap for arena if it is not already.
+func (m *Minimap) setArena(arena *common.Arena) {
+	if m.layout.Arena == arena {
+		return
+	}
+	m.layout = state.NewMinimapLayout(arena, minimapWidth, minimapHeight)
+	m.walls = ebiten.NewImage(m.layout.Width, m.layout.Height)
+	m.walls.Fill(minimapBackground)
+	for _, mark := range m.layout.Walls() {
+		drawMark(m.walls, mark)
+	}
+	m.img = ebiten.NewImage(m.layout.Width, m.layout.Height)
+}
+
+// Draw renders the minimap of match onto screen for the player in slot
+// you.
+func (m *Minimap) Draw(screen *ebiten.Image, match *state.Match, you int32, camera *Camera) {
+	if m.Hidden {
+		return
+	}
+	m.setArena(match.Arena)
+	m.img.Clear()
+	m.img.DrawImage(m.walls, nil)
+	m.marks = m.layout.Marks(m.marks[:0], match, you, &camera.Camera)
+	for _, mark := range m.marks {
+		drawMark(m.img, mark)
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(
+		float64(common.ScreenWidth-common.ScreenPadding-m.layout.Width),
+		float64(common.ScreenHeight-common.ScreenPadding-m.layout.Height),
+	)
+	screen.DrawImage(m.img, op)
+}
+
+// drawMark fills mark on img, or outlines it if it is the camera's view.
+func drawMark(img *ebiten.Image, mark state.Mark) {
+	clr := minimapColors[mark.Kind]
+	if mark.Kind != state.MarkView {
+		ebitenutil.DrawRect(img, mark.X, mark.Y, mark.W, mark.H, clr)
+		return
+	}
+	x, y, w, h := mark.X, mark.Y, mark.W, mark.H
+	ebitenutil.DrawRect(img, x, y, w, 1, clr)
+	ebitenutil.DrawRect(img, x, y+h-1, w, 1, clr)
+	ebitenutil.DrawRect(img, x, y, 1, h, clr)
+	ebitenutil.DrawRect(img, x+w-1, y, 1, h, clr)
+}
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/game/state"
+)
+
+// pickupSize is the width of a power-up on screen.
+const pickupSize = 8
+
+var (
+	pickupColors = [common.NumPickupKinds]color.Color{
+		common.PickupSpeed:    color.RGBA{R: 80, G: 220, B: 250, A: 255},
+		common.PickupDisguise: color.RGBA{R: 190, G: 110, B: 250, A: 255},
+		common.PickupReach:    color.RGBA{R: 250, G: 120, B: 60, A: 255},
+		common.PickupDecoy:    color.RGBA{R: 110, G: 230, B: 110, A: 255},
+	}
+	pickupBorder = color.RGBA{R: 20, G: 20, B: 28, A: 255}
+)
+
+// drawPickup draws a power-up as a bobbing square in its kind's colour.
+// count is the frame counter driving the bob.
+func drawPickup(screen *ebiten.Image, p *common.Pickup, count int, camera *Camera) {
+	var geoM ebiten.GeoM
+	bob := math.Round(math.Sin(float64(count)/10) * 1.5)
+	geoM.Translate(p.Px-pickupSize/2, p.Py-pickupSize/2+bob)
+	camera.Translate(&geoM)
+	x, y := geoM.Apply(0, 0)
+	ebitenutil.DrawRect(screen, x-1, y-1, pickupSize+2, pickupSize+2, pickupBorder)
+	ebitenutil.DrawRect(screen, x, y, pickupSize, pickupSize, pickupColors[p.Kind])
+}
+
+// drawEffects lists the local player's power-ups and the seconds each has
+// left under the stamina bar.
+func drawEffects(screen *ebiten.Image, effects []state.Effect) {
+	for i, e := range effects {
+		s := fmt.Sprintf("%s %.0fs", e.Kind, math.Ceil(e.Left.Seconds()))
+		text.Draw(screen, s, tinyFont, common.ScreenPadding, 26+i*12, pickupColors[e.Kind])
+	}
+}
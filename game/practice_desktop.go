@@ -0,0 +1,29 @@
+//go:build !js
+
+package game
+
+import (
+	"sync"
+
+	"github.com/kisunji/ebiten-poc/server"
+	"github.com/kisunji/ebiten-poc/transport"
+)
+
+// practiceAvailable reports whether the start menu offers practice mode.
+const practiceAvailable = true
+
+var (
+	practiceOnce  sync.Once
+	practiceRooms *server.Rooms
+)
+
+// practiceConn connects to an in-process practice server, starting it on
+// first use. No network is involved.
+func practiceConn(name string) transport.Conn {
+	practiceOnce.Do(func() {
+		practiceRooms = server.NewRooms(server.PracticeConfig())
+	})
+	serverEnd, clientEnd := transport.Pipe()
+	practiceRooms.Accept(serverEnd, "practice", name)
+	return clientEnd
+}
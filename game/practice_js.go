@@ -0,0 +1,10 @@
+package game
+
+import "github.com/kisunji/ebiten-poc/transport"
+
+// The browser build leaves the server out, so there is no practice mode.
+const practiceAvailable = false
+
+func practiceConn(name string) transport.Conn {
+	panic("practice mode is not available in the browser")
+}
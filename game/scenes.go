@@ -10,9 +10,11 @@ import (
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/game/state"
 	"github.com/kisunji/ebiten-poc/pb"
 	"google.golang.org/protobuf/proto"
 )
@@ -25,12 +27,63 @@ type SceneHandler interface {
 
 type StartMenu struct {
 	client          *Client
+	settings        Settings
+	browser         *ServerBrowser
+	lanServers      []DiscoveredServer
 	hostGameHovered bool
 	scanningInput   bool
+	editingName     bool
 	startPressed    bool
+	practicePressed bool
 	inputText       string
+	nameText        string
 	startText       string
+	practiceText    string
+	ranksText       string
 	next            Scene
+	count           int
+}
+
+const (
+	maxAddrLen = 64
+
+	addrFieldX = 45 + 6*8 // after the scheme toggle
+	addrFieldY = common.ScreenHeight/2 + 6
+
+	nameFieldX = 45 + 5*8 // after the label
+	nameFieldY = common.ScreenHeight/2 - 22
+
+	ranksX = common.ScreenWidth - 80
+
+	practiceX = 230
+
+	lanListY      = common.ScreenHeight/2 + 82
+	lanLineHeight = 12
+	maxLanServers = 4
+)
+
+// NewStartMenu builds the start menu from the saved settings. A non-empty
+// serverAddr overrides the saved server for this session.
+func NewStartMenu(c *Client, serverAddr string) *StartMenu {
+	settings := LoadSettings()
+	if serverAddr != "" {
+		settings = settings.WithServer(serverAddr)
+	}
+	browser := NewServerBrowser()
+	go browser.Listen(context.Background())
+	return &StartMenu{
+		client:    c,
+		settings:  settings,
+		browser:   browser,
+		inputText: settings.ServerAddr,
+		nameText:  settings.Name,
+		startText: "START",
+	}
+}
+
+func menuButtonHovered(x, y, left, right int) bool {
+	return x > left && x < right &&
+		y > common.ScreenHeight/2+24 && y < common.ScreenHeight/2+48
 }
 
 func repeatingKeyPressed(key ebiten.Key) bool {
@@ -50,23 +103,54 @@ func repeatingKeyPressed(key ebiten.Key) bool {
 
 func (s *StartMenu) Update() {
 	s.next = SceneStartMenu
+	s.count++
+	if s.count%30 == 1 {
+		s.lanServers = s.browser.Servers()
+		if len(s.lanServers) > maxLanServers {
+			s.lanServers = s.lanServers[:maxLanServers]
+		}
+	}
 	x, y := ebiten.CursorPosition()
-	if x > 40 && x < common.ScreenWidth/2 &&
-		y > common.ScreenHeight/2+24 && y < common.ScreenHeight/2+48 {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		switch {
+		case y > lanListY && y < lanListY+len(s.lanServers)*lanLineHeight+2 && x > 40:
+			d := s.lanServers[(y-lanListY-2)/lanLineHeight]
+			s.settings.Secure = d.Secure
+			s.inputText = d.Addr
+			s.scanningInput = false
+			s.editingName = false
+		case y > nameFieldY-10 && y < nameFieldY+4 && x >= ranksX:
+			s.saveSettings()
+			s.scanningInput = false
+			s.editingName = false
+			s.next = SceneLeaderboard
+			return
+		case y > nameFieldY-10 && y < nameFieldY+4 && x > 40:
+			s.editingName = true
+			s.scanningInput = false
+		case y > addrFieldY-10 && y < addrFieldY+4 && x > 40 && x < addrFieldX:
+			s.settings.Secure = !s.settings.Secure
+			s.scanningInput = false
+			s.editingName = false
+		case y > addrFieldY-10 && y < addrFieldY+4 && x >= addrFieldX:
+			s.scanningInput = true
+			s.editingName = false
+		default:
+			s.scanningInput = false
+			s.editingName = false
+		}
+	}
+	s.ranksText = "RANKS"
+	if y > nameFieldY-10 && y < nameFieldY+4 && x >= ranksX {
+		s.ranksText = ">RANKS"
+	}
+	if menuButtonHovered(x, y, 40, practiceX-20) {
 		s.startText = ">START"
 		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
 			s.startText = "> START"
 			s.startPressed = true
 		} else if s.startPressed {
-			err := s.client.DialTLS("ws.chriskim.dev:3000")
-			//err := s.client.Dial("localhost:8080")
-			if err != nil {
-				s.next = SceneNotConnected
-				return
-			} else {
-				go s.client.Listen(context.Background())
-			}
-			s.next = SceneLobby
+			s.connect()
 			return
 		} else {
 			s.startPressed = false
@@ -75,22 +159,139 @@ func (s *StartMenu) Update() {
 		s.startPressed = false
 		s.startText = "START"
 	}
+	if practiceAvailable && menuButtonHovered(x, y, practiceX-5, common.ScreenWidth) {
+		s.practiceText = ">PRACTICE"
+		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			s.practiceText = "> PRACTICE"
+			s.practicePressed = true
+		} else if s.practicePressed {
+			s.practicePressed = false
+			s.practice()
+			return
+		}
+	} else {
+		s.practicePressed = false
+		s.practiceText = "PRACTICE"
+	}
 
 	if s.scanningInput {
-		if len(s.inputText) < 4 {
-			s.inputText += strings.ToUpper(string(ebiten.InputChars()))
+		if len(s.inputText) < maxAddrLen {
+			s.inputText += strings.TrimSpace(string(ebiten.InputChars()))
 		}
 		if repeatingKeyPressed(ebiten.KeyBackspace) {
 			if len(s.inputText) >= 1 {
 				s.inputText = s.inputText[:len(s.inputText)-1]
 			}
 		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			s.scanningInput = false
+			s.connect()
+		}
+	}
+	if s.editingName {
+		for _, r := range ebiten.InputChars() {
+			if common.IsNameRune(r) && len([]rune(s.nameText)) < common.MaxNameLen {
+				s.nameText += string(r)
+			}
+		}
+		if repeatingKeyPressed(ebiten.KeyBackspace) {
+			if r := []rune(s.nameText); len(r) >= 1 {
+				s.nameText = string(r[:len(r)-1])
+			}
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			s.editingName = false
+		}
+	}
+}
+
+// saveSettings remembers the chosen server and name.
+func (s *StartMenu) saveSettings() {
+	s.settings = s.settings.WithServer(s.inputText)
+	s.inputText = s.settings.ServerAddr
+	s.settings.Name = common.CleanName(s.nameText)
+	s.nameText = s.settings.Name
+	s.client.Name = s.settings.Name
+	if err := s.settings.Save(); err != nil {
+		log.Println("saving settings:", err)
+	}
+}
+
+// connect saves the chosen server and dials it.
+func (s *StartMenu) connect() {
+	s.saveSettings()
+	var err error
+	if s.settings.Secure {
+		err = s.client.DialTLS(s.settings.ServerAddr)
+	} else {
+		err = s.client.Dial(s.settings.ServerAddr)
 	}
+	if err != nil {
+		log.Println(err)
+		s.next = SceneNotConnected
+		return
+	}
+	go s.client.Listen(context.Background())
+	s.next = SceneLobby
+}
+
+// practice connects to an in-process server and jumps straight into a
+// game against the crowd.
+func (s *StartMenu) practice() {
+	s.saveSettings()
+	s.client.Connect(practiceConn(s.client.Name))
+	b, err := proto.Marshal(&pb.ClientMessage{
+		Content: &pb.ClientMessage_StartGame{},
+	})
+	if err != nil {
+		log.Fatalln(err)
+	}
+	s.client.Send <- b
+	go s.client.Listen(context.Background())
+	s.next = SceneLobby
 }
 
 func (s *StartMenu) Draw(screen *ebiten.Image) {
 	text.Draw(screen, common.GameTitle, titleFont, 40, common.ScreenHeight/2-50, color.White)
+	text.Draw(screen, "NAME", tinyFont, 45, nameFieldY, color.Gray{Y: 160})
+	name := s.nameText
+	if s.editingName && s.count/30%2 == 0 {
+		name += "_"
+	}
+	nameClr := color.Color(color.Gray{Y: 200})
+	if s.editingName {
+		nameClr = color.White
+	}
+	text.Draw(screen, name, tinyFont, nameFieldX, nameFieldY, nameClr)
+	text.Draw(screen, s.ranksText, tinyFont, ranksX, nameFieldY, color.White)
+	text.Draw(screen, "SERVER", tinyFont, 45, addrFieldY-14, color.Gray{Y: 160})
+	text.Draw(screen, s.settings.scheme(), tinyFont, 45, addrFieldY, color.White)
+	addr := s.inputText
+	if max := (common.ScreenWidth - addrFieldX - 8) / 8; len(addr) > max {
+		addr = addr[len(addr)-max:]
+	}
+	if s.scanningInput && s.count/30%2 == 0 {
+		addr += "_"
+	}
+	clr := color.Color(color.Gray{Y: 200})
+	if s.scanningInput {
+		clr = color.White
+	}
+	text.Draw(screen, addr, tinyFont, addrFieldX, addrFieldY, clr)
 	text.Draw(screen, s.startText, menuFont, 45, common.ScreenHeight/2+50, color.White)
+	if practiceAvailable {
+		text.Draw(screen, s.practiceText, smallFont, practiceX, common.ScreenHeight/2+46, color.White)
+	}
+	if len(s.lanServers) > 0 {
+		text.Draw(screen, "ON YOUR NETWORK", tinyFont, 45, lanListY, color.Gray{Y: 160})
+		for i, d := range s.lanServers {
+			clr := color.Color(color.Gray{Y: 200})
+			if d.Addr == s.inputText {
+				clr = color.White
+			}
+			text.Draw(screen, d.String(), tinyFont, 45, lanListY+(i+1)*lanLineHeight, clr)
+		}
+	}
 }
 
 func (s *StartMenu) Next() Scene {
@@ -112,21 +313,42 @@ func (n *NotConnected) Next() Scene {
 }
 
 type Lobby struct {
-	Players      []bool
-	yourId       int32
-	hostId       int32
+	state        *state.Lobby
 	Client       *Client
 	starting     bool
 	next         Scene
 	startText    string
 	startPressed bool
+	mapText      string
+	mapPressed   bool
+	// Room size, which the host clicks through like the map.
+	playersText    string
+	playersPressed bool
+	crowdText      string
+	crowdPressed   bool
+	// Page of the player list shown, for rooms with more slots than fit.
+	page        int
+	pageText    string
+	pagePressed bool
 }
 
+const (
+	mapX = common.ScreenWidth - 170
+	mapY = common.ScreenHeight/2 - 50
+	// Room size settings, below the map.
+	playersY = mapY + 18
+	crowdY   = mapY + 36
+	// Player list rows per page, and where the page picker sits.
+	lobbyRows = 8
+	pageX     = 45
+	pageY     = common.ScreenHeight/2 - 20
+)
+
 func NewLobby(c *Client) *Lobby {
 	return &Lobby{
-		Client:  c,
-		Players: make([]bool, common.MaxClients),
-		next:    SceneLobby,
+		Client: c,
+		state:  state.NewLobby(),
+		next:   SceneLobby,
 	}
 }
 
@@ -155,69 +377,147 @@ func (l *Lobby) Update() {
 		l.startText = "START"
 	}
 
-outer:
-	for {
-		select {
-		case bytes := <-l.Client.Recv:
-			msg := &pb.ServerMessage{}
-			err := proto.Unmarshal(bytes, msg)
-			if err != nil {
-				log.Fatalln(err)
-			}
-			switch buf := msg.Content.(type) {
-			case *pb.ServerMessage_ConnectResponse:
-				log.Println("connected")
-				if buf.ConnectResponse.IsHost {
-					log.Println("is host")
-					l.hostId = buf.ConnectResponse.ClientSlot
-				}
-				l.yourId = buf.ConnectResponse.ClientSlot
-			case *pb.ServerMessage_ConnectError:
-				log.Println(buf.ConnectError.Message)
-				l.next = SceneNotConnected
-			case *pb.ServerMessage_UpdateLobby:
-				l.Players = buf.UpdateLobby.ConnectedSlots
-				l.hostId = buf.UpdateLobby.HostSlot
-			case *pb.ServerMessage_GameStart:
-				l.next = SceneMainGame
-			case *pb.ServerMessage_PlayerDisconnected:
-				l.Players[buf.PlayerDisconnected.Id] = false
-			case *pb.ServerMessage_NewHost:
-				l.hostId = buf.NewHost.Id
-			case *pb.ServerMessage_UpdateEntity:
-				// just suppress
-			default:
-				log.Printf("Unknown message type %T\n", buf)
-			}
-		case <-l.Client.Disconnect:
-			log.Println("lost connection to server")
-			l.next = SceneNotConnected
-			break outer
-		default:
-			// no more messages
-			break outer
+	// the host clicks the map and room size to cycle through the others
+	var clicked bool
+	if l.mapText, clicked = l.hostClick("MAP "+l.state.Map, mapY, &l.mapPressed); clicked {
+		l.selectMap(l.state.NextMap())
+	}
+	players := fmt.Sprintf("PLAYERS %d", len(l.state.Players))
+	if l.playersText, clicked = l.hostClick(players, playersY, &l.playersPressed); clicked {
+		l.setCapacity(l.state.NextPlayerSlots(), l.state.CrowdSize)
+	}
+	crowd := fmt.Sprintf("CROWD %d", l.state.CrowdSize)
+	if l.crowdText, clicked = l.hostClick(crowd, crowdY, &l.crowdPressed); clicked {
+		l.setCapacity(len(l.state.Players), l.state.NextCrowd())
+	}
+
+	// click the page number or scroll to see more players
+	pages := l.pages()
+	if l.page >= pages {
+		// the room has fewer slots than when the page was picked
+		l.page = 0
+	}
+	l.pageText = fmt.Sprintf("PAGE %d/%d", l.page+1, pages)
+	if x > pageX && x < pageX+90 && y > pageY-16 && y < pageY {
+		l.pageText = ">" + l.pageText
+		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			l.pagePressed = true
+		} else if l.pagePressed {
+			l.pagePressed = false
+			l.page = (l.page + 1) % pages
 		}
+	} else {
+		l.pagePressed = false
+	}
+	if _, dy := ebiten.Wheel(); dy < 0 && l.page < pages-1 {
+		l.page++
+	} else if dy > 0 && l.page > 0 {
+		l.page--
+	}
+
+	if !l.Client.Receive(l.apply) {
+		l.next = SceneNotConnected
+	}
+}
+
+func (l *Lobby) apply(msg *pb.ServerMessage) {
+	l.state.Apply(msg)
+	if l.state.ConnectError != "" {
+		l.next = SceneNotConnected
+	} else if l.state.Started {
+		l.Client.Slot = l.state.YourID
+		l.Client.Map = l.state.Map
+		l.Client.PlayerSlots = l.state.PlayerSlots
+		l.Client.Crowd = l.state.Crowd
+		l.next = SceneMainGame
+	}
+}
+
+// pages returns how many pages the player list takes.
+func (l *Lobby) pages() int {
+	if n := (len(l.state.Players) + lobbyRows - 1) / lobbyRows; n > 1 {
+		return n
+	}
+	return 1
+}
+
+// hostClick handles a setting the host changes by clicking its line of
+// text, which ends at height y on the right. It returns the text to draw,
+// marked while hovered, and whether a click just finished.
+func (l *Lobby) hostClick(label string, y int, pressed *bool) (string, bool) {
+	cx, cy := ebiten.CursorPosition()
+	if !l.state.IsHost() || cx <= mapX || cx >= common.ScreenWidth-30 || cy <= y-16 || cy >= y {
+		*pressed = false
+		return label, false
+	}
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		*pressed = true
+		return ">" + label, false
+	}
+	clicked := *pressed
+	*pressed = false
+	return ">" + label, clicked
+}
+
+// setCapacity asks the server to resize the room.
+func (l *Lobby) setCapacity(players, crowd int) {
+	b, err := proto.Marshal(&pb.ClientMessage{
+		Content: &pb.ClientMessage_SetCapacity{
+			SetCapacity: &pb.SetCapacity{Players: int32(players), Crowd: int32(crowd)},
+		},
+	})
+	if err != nil {
+		log.Fatalln(err)
+	}
+	l.Client.Send <- b
+}
+
+// selectMap asks the server to play the next match on the named map.
+func (l *Lobby) selectMap(name string) {
+	b, err := proto.Marshal(&pb.ClientMessage{
+		Content: &pb.ClientMessage_SelectMap{
+			SelectMap: &pb.SelectMap{Name: name},
+		},
+	})
+	if err != nil {
+		log.Fatalln(err)
 	}
+	l.Client.Send <- b
 }
 
 func (l *Lobby) Draw(screen *ebiten.Image) {
 	text.Draw(screen, "Lobby", titleFont, 40, common.ScreenHeight/2-50, color.White)
-	for i, p := range l.Players {
+	if l.state.Map != "" {
+		text.Draw(screen, l.mapText, smallFont, mapX, mapY, color.White)
+	}
+	if l.state.CrowdSize > 0 {
+		text.Draw(screen, l.playersText, smallFont, mapX, playersY, color.White)
+		text.Draw(screen, l.crowdText, smallFont, mapX, crowdY, color.White)
+	}
+	if l.pages() > 1 {
+		text.Draw(screen, l.pageText, smallFont, pageX, pageY, color.White)
+	}
+	first := l.page * lobbyRows
+	for i, p := range l.state.Players {
+		if i < first || i >= first+lobbyRows {
+			continue
+		}
 		var s string
 		if p {
 			s = fmt.Sprintf("Player %d", i+1)
-			if l.yourId == int32(i) {
+			if l.state.YourID == int32(i) {
 				s = fmt.Sprintf("%s (you)", s)
 			}
-			if l.hostId == int32(i) {
+			if l.state.HostID == int32(i) {
 				s = fmt.Sprintf("%s (host)", s)
 			}
 		} else {
 			s = "Not connected"
 		}
-		text.Draw(screen, s, smallFont, 45, common.ScreenHeight/2+i*18, color.White)
+		text.Draw(screen, s, smallFont, 45, common.ScreenHeight/2+(i-first)*18, color.White)
 	}
-	if l.hostId == l.yourId {
+	drawNotice(screen, l.state.Notice)
+	if l.state.IsHost() {
 		text.Draw(screen, l.startText, smallFont, common.ScreenWidth-120, common.ScreenHeight-30, color.White)
 	}
 }
@@ -227,19 +527,21 @@ func (l *Lobby) Next() Scene {
 }
 
 type MainGame struct {
+	*state.Match
 	Client      *Client
 	input       input
 	count       int
 	Speed       int
-	Chars       common.Chars
-	Coins       []*common.Coin
-	StartTime   int64 // unixtime
-	Duration    time.Duration
 	next        Scene
 	Op          *ebiten.DrawImageOptions
 	lastUpdated time.Time
 	debouncer   *Debouncer
-	EndMessage  string
+	// The arena's walls, drawn once.
+	walls      *ebiten.Image
+	wallsArena *common.Arena
+	camera     Camera
+	minimap    Minimap
+	fog        Fog
 }
 
 func NewMainGame(c *Client, d *Debouncer) *MainGame {
@@ -247,7 +549,7 @@ func NewMainGame(c *Client, d *Debouncer) *MainGame {
 		Op:        &ebiten.DrawImageOptions{},
 		Speed:     5,
 		Client:    c,
-		Chars:     make([]*common.Char, common.MaxChars),
+		Match:     state.NewMatch(),
 		next:      SceneMainGame,
 		debouncer: d,
 	}
@@ -255,6 +557,8 @@ func NewMainGame(c *Client, d *Debouncer) *MainGame {
 
 func (mg *MainGame) Update() {
 	if mg.lastUpdated.IsZero() {
+		mg.Resize(mg.Client.PlayerSlots, mg.Client.Crowd)
+		mg.SetArena(mg.Client.Map)
 		b, err := proto.Marshal(&pb.ClientMessage{
 			Content: &pb.ClientMessage_WorldUpdate{},
 		})
@@ -264,82 +568,31 @@ func (mg *MainGame) Update() {
 		mg.Client.Send <- b
 		mg.lastUpdated = time.Now()
 	}
-outer:
-	for {
-		select {
-		case bytes := <-mg.Client.Recv:
-			msg := &pb.ServerMessage{}
-			err := proto.Unmarshal(bytes, msg)
-			if err != nil {
-				log.Fatalln(err)
-			}
-			switch content := msg.Content.(type) {
-			case *pb.ServerMessage_UpdateEntity:
-				mg.Chars.UpdateFromData(content.UpdateEntity)
-			case *pb.ServerMessage_UpdateEntities:
-				for _, ue := range content.UpdateEntities.UpdateEntity {
-					mg.Chars.UpdateFromData(ue)
-				}
-			case *pb.ServerMessage_NewCoin:
-				coin := &common.Coin{
-					Px:          content.NewCoin.Px,
-					Py:          content.NewCoin.Py,
-					FrameOffset: int(content.NewCoin.FrameOffset),
-				}
-				mg.Coins = append(mg.Coins, coin)
-			case *pb.ServerMessage_CoinGot:
-				// todo: sometimes server sends messages from last game
-				if int(content.CoinGot.Index) > len(mg.Coins) {
-					continue
-				}
-				mg.Coins[content.CoinGot.Index].PickedUp = true
-			case *pb.ServerMessage_TimeSync:
-				mg.StartTime = content.TimeSync.StartTime
-				mg.Duration = time.Duration(content.TimeSync.Duration) * time.Minute
-			case *pb.ServerMessage_GameEnd:
-				var sb strings.Builder
-				sb.WriteString("GAME OVER\n")
-				if content.GameEnd.Survivor != 0 {
-					sb.WriteString(fmt.Sprintf("Player %d wins!\n", content.GameEnd.Survivor+1))
-				} else {
-					for i, score := range content.GameEnd.Score {
-						if score > 0 {
-							sb.WriteString(fmt.Sprintf("Player %d: %d\n", i+1, score))
-						}
-					}
-				}
-				mg.EndMessage = sb.String()
-			case *pb.ServerMessage_PlayerDisconnected:
-				// maybe kill animation?
-				mg.Chars[content.PlayerDisconnected.Id] = nil
-			default:
-				log.Printf("Unknown message type %T\n", content)
-			}
-		case <-mg.Client.Disconnect:
-			log.Println("lost connection to server")
-			mg.next = SceneNotConnected
-			break outer
-		default:
-			// no more messages
-			break outer
-		}
+	if !mg.Client.Receive(mg.Apply) {
+		mg.next = SceneNotConnected
 	}
-	if mg.EndMessage != "" {
+	if mg.Over() {
 		return
 	}
 	mg.parseInput()
-	for _, char := range mg.Chars {
-		if char == nil {
-			continue
-		}
-		if char.Attacking() {
-			char.Attack()
-		}
-		char.Move()
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		mg.minimap.Toggle()
+	}
+	mg.Step()
+	if you := mg.you(); you != nil {
+		mg.camera.Follow(you.Px, you.Py, mg.Arena)
 	}
 	mg.count++
 }
 
+// you returns the local player's character, if the server has sent it.
+func (mg *MainGame) you() *common.Char {
+	if int(mg.Client.Slot) >= len(mg.Chars) {
+		return nil
+	}
+	return mg.Chars[mg.Client.Slot]
+}
+
 func (mg *MainGame) parseInput() {
 	var pi pb.Input
 	var inputChanged bool
@@ -358,11 +611,15 @@ func (mg *MainGame) parseInput() {
 	if ebiten.IsKeyPressed(ebiten.KeySpace) {
 		pi.ActionPressed = true
 	}
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		pi.SprintPressed = true
+	}
 	if mg.input.RightPressed != pi.RightPressed ||
 		mg.input.LeftPressed != pi.LeftPressed ||
 		mg.input.UpPressed != pi.UpPressed ||
 		mg.input.DownPressed != pi.DownPressed ||
-		mg.input.ActionPressed != pi.ActionPressed {
+		mg.input.ActionPressed != pi.ActionPressed ||
+		mg.input.SprintPressed != pi.SprintPressed {
 		inputChanged = true
 	}
 	mg.input.RightPressed = pi.RightPressed
@@ -370,6 +627,7 @@ func (mg *MainGame) parseInput() {
 	mg.input.UpPressed = pi.UpPressed
 	mg.input.DownPressed = pi.DownPressed
 	mg.input.ActionPressed = pi.ActionPressed
+	mg.input.SprintPressed = pi.SprintPressed
 	if !inputChanged {
 		return
 	}
@@ -385,29 +643,50 @@ func (mg *MainGame) parseInput() {
 }
 
 func (mg *MainGame) Draw(screen *ebiten.Image) {
+	if mg.walls == nil || mg.wallsArena != mg.Arena {
+		mg.walls, mg.wallsArena = drawWalls(mg.Arena), mg.Arena
+	}
+	mg.Op.GeoM.Reset()
+	mg.camera.Translate(&mg.Op.GeoM)
+	screen.DrawImage(mg.walls, mg.Op)
 	for _, coin := range mg.Coins {
 		if coin.PickedUp {
 			continue
 		}
 		img := coinFrame(mg.count/15 + coin.FrameOffset)
 		w, h := img.Size()
+		if !mg.camera.Visible(coin.Px, coin.Py, float64(w), float64(h)) {
+			continue
+		}
 		mg.Op.GeoM.Reset()
 		mg.Op.GeoM.Translate(
 			coin.Px-float64(w)/2,
 			coin.Py-float64(h)/2,
 		)
+		mg.camera.Translate(&mg.Op.GeoM)
 		screen.DrawImage(img, mg.Op)
 	}
-	tmp := make([]*common.Char, len(mg.Chars))
-	copy(tmp, mg.Chars)
-	sort.Slice(tmp, func(i, j int) bool {
-		if tmp[i] == nil || tmp[j] == nil {
-			return true
+	for _, p := range mg.Pickups {
+		if p != nil && !p.PickedUp && mg.camera.Visible(p.Px, p.Py, pickupSize+4, pickupSize+4) {
+			drawPickup(screen, p, mg.count, &mg.camera)
 		}
-		return tmp[i].IsDead || tmp[i].Py < tmp[j].Py
+	}
+	// draw back to front, by index so penalties can be looked up
+	order := make([]int, 0, len(mg.Chars))
+	for i, char := range mg.Chars {
+		if char != nil {
+			order = append(order, i)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := mg.Chars[order[i]], mg.Chars[order[j]]
+		return a.IsDead || a.Py < b.Py
 	})
-	for _, char := range tmp {
-		if char == nil {
+	now := time.Now()
+	for _, i := range order {
+		char := mg.Chars[i]
+		// big enough for the widest attack frame
+		if !mg.camera.Visible(char.Px, char.Py, 64, 64) {
 			continue
 		}
 		if char.IsDead {
@@ -422,6 +701,7 @@ func (mg *MainGame) Draw(screen *ebiten.Image) {
 				char.Px-float64(w)/2,
 				char.Py-float64(h)/2,
 			)
+			mg.camera.Translate(&mg.Op.GeoM)
 			screen.DrawImage(sprite, mg.Op)
 			continue
 		}
@@ -434,21 +714,21 @@ func (mg *MainGame) Draw(screen *ebiten.Image) {
 		if char.Fx != 0 {
 			if char.Fy < 0 {
 				if char.Attacking() {
-					sprite = upRightAttackingFrame(char.AttackFrame)
+					sprite = upRightAttackingFrame(char.AttackAnimFrame())
 				} else {
 					sprite = upRightRestingFrame(clock)
 				}
 			}
 			if char.Fy > 0 {
 				if char.Attacking() {
-					sprite = downRightAttackingFrame(char.AttackFrame)
+					sprite = downRightAttackingFrame(char.AttackAnimFrame())
 				} else {
 					sprite = downRightRestingFrame(clock)
 				}
 			}
 			if char.Fy == 0 {
 				if char.Attacking() {
-					sprite = rightAttackingFrame(char.AttackFrame)
+					sprite = rightAttackingFrame(char.AttackAnimFrame())
 				} else {
 					sprite = rightRestingFrame(clock)
 				}
@@ -456,14 +736,14 @@ func (mg *MainGame) Draw(screen *ebiten.Image) {
 		} else {
 			if char.Fy < 0 {
 				if char.Attacking() {
-					sprite = upAttackingFrame(char.AttackFrame)
+					sprite = upAttackingFrame(char.AttackAnimFrame())
 				} else {
 					sprite = upRestingFrame(clock)
 				}
 			}
 			if char.Fy > 0 {
 				if char.Attacking() {
-					sprite = downAttackingFrame(char.AttackFrame)
+					sprite = downAttackingFrame(char.AttackAnimFrame())
 				} else {
 					sprite = downRestingFrame(clock)
 				}
@@ -479,7 +759,18 @@ func (mg *MainGame) Draw(screen *ebiten.Image) {
 			char.Px-float64(w)/2,
 			char.Py-float64(h)/2,
 		)
+		mg.camera.Translate(&mg.Op.GeoM)
+		if mg.Revealed(i, now) {
+			drawOutline(screen, sprite, mg.Op.GeoM)
+		}
+		if mg.Stunned(i, now) {
+			mg.Op.ColorM.Scale(.5, .5, .5, 1)
+		}
 		screen.DrawImage(sprite, mg.Op)
+		mg.Op.ColorM.Reset()
+	}
+	if you := mg.you(); you != nil && !you.IsDead && mg.Vision > 0 {
+		mg.fog.Draw(screen, you.Px, you.Py, mg.Vision, &mg.camera)
 	}
 	if mg.StartTime > 0 && mg.Duration > 0 && mg.EndMessage == "" {
 		elapsed := time.Since(time.Unix(mg.StartTime, 0))
@@ -490,9 +781,103 @@ func (mg *MainGame) Draw(screen *ebiten.Image) {
 
 		text.Draw(screen, fmt.Sprintf("%d:%02d", minutes, seconds), smallFont, common.ScreenWidth/2-24, 24, color.White)
 	}
+	mg.minimap.Draw(screen, mg.Match, mg.Client.Slot, &mg.camera)
+	if you := mg.you(); you != nil && !you.IsDead {
+		drawStamina(screen, you.Stamina)
+		drawEffects(screen, mg.Effects(time.Now()))
+	}
+	drawKillFeed(screen, mg.VisibleKills(time.Now()))
 	if mg.EndMessage != "" {
 		text.Draw(screen, mg.EndMessage, smallFont, 0, common.ScreenHeight/2, color.White)
 	}
+	drawNotice(screen, mg.Notice)
+}
+
+var (
+	wallColor    = color.RGBA{R: 70, G: 62, B: 84, A: 255}
+	wallTopColor = color.RGBA{R: 104, G: 94, B: 124, A: 255}
+)
+
+// drawWalls renders the solid tiles of arena, lit along their top edge
+// where nothing solid is above.
+func drawWalls(arena *common.Arena) *ebiten.Image {
+	img := ebiten.NewImage(int(arena.PixelWidth()), int(arena.PixelHeight()))
+	size := float64(arena.TileSize)
+	for ty := 0; ty < arena.Height; ty++ {
+		for tx := 0; tx < arena.Width; tx++ {
+			if !arena.Solid(tx, ty) {
+				continue
+			}
+			x, y := float64(tx)*size, float64(ty)*size
+			ebitenutil.DrawRect(img, x, y, size, size, wallColor)
+			if ty > 0 && !arena.Solid(tx, ty-1) {
+				ebitenutil.DrawRect(img, x, y, size, 2, wallTopColor)
+			}
+		}
+	}
+	return img
+}
+
+var outlineColor = color.RGBA{R: 255, G: 60, B: 60, A: 255}
+
+// drawOutline draws a solid silhouette of sprite one pixel out in every
+// direction, for the sprite to be drawn over.
+func drawOutline(screen, sprite *ebiten.Image, geoM ebiten.GeoM) {
+	op := &ebiten.DrawImageOptions{}
+	r, g, b, _ := outlineColor.RGBA()
+	for _, d := range [][2]float64{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		op.GeoM = geoM
+		op.GeoM.Translate(d[0], d[1])
+		op.ColorM.Reset()
+		op.ColorM.Scale(0, 0, 0, 1)
+		op.ColorM.Translate(float64(r)/0xffff, float64(g)/0xffff, float64(b)/0xffff, 0)
+		screen.DrawImage(sprite, op)
+	}
+}
+
+var (
+	staminaColor      = color.RGBA{R: 120, G: 220, B: 120, A: 255}
+	lowStaminaColor   = color.RGBA{R: 220, G: 120, B: 60, A: 255}
+	staminaBackground = color.RGBA{R: 40, G: 40, B: 40, A: 200}
+)
+
+// drawStamina shows the local player's stamina as a bar in the top left
+// corner. It turns orange while there is too little left to start
+// sprinting.
+func drawStamina(screen *ebiten.Image, stamina int) {
+	const (
+		x, y          = common.ScreenPadding, common.ScreenPadding
+		width, height = 60, 4
+	)
+	clr := staminaColor
+	if stamina < common.MinSprintStamina {
+		clr = lowStaminaColor
+	}
+	ebitenutil.DrawRect(screen, x, y, width, height, staminaBackground)
+	ebitenutil.DrawRect(screen, x, y, float64(width*stamina)/common.MaxStamina, height, clr)
+}
+
+// drawKillFeed lists recent kills in the top right corner, fading each one
+// out before it goes.
+func drawKillFeed(screen *ebiten.Image, kills []state.Kill) {
+	now := time.Now()
+	for i, k := range kills {
+		s := k.String()
+		clr := color.NRGBA{R: 255, G: 255, B: 255, A: uint8(255 * k.Alpha(now))}
+		text.Draw(screen, s, tinyFont, common.ScreenWidth-common.ScreenPadding-len(s)*8, 14+i*12, clr)
+	}
+}
+
+var noticeColor = color.RGBA{R: 255, G: 220, B: 80, A: 255}
+
+// drawNotice shows the latest message from the server operator along the
+// bottom of the screen.
+func drawNotice(screen *ebiten.Image, n state.Notice) {
+	now := time.Now()
+	if !n.Visible(now) {
+		return
+	}
+	text.Draw(screen, n.Text(now), tinyFont, 10, common.ScreenHeight-8, noticeColor)
 }
 
 func (mg *MainGame) Next() Scene {
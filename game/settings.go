@@ -0,0 +1,73 @@
+package game
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const defaultServerAddr = "ws.chriskim.dev:3000"
+
+// Settings are remembered between sessions: in a file on desktop and in
+// localStorage in the browser.
+type Settings struct {
+	ServerAddr string `json:"serverAddr"`
+	Secure     bool   `json:"secure"`
+	// Shown to other players and kept in the match history.
+	Name string `json:"name"`
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		ServerAddr: defaultServerAddr,
+		Secure:     true,
+	}
+}
+
+// LoadSettings returns the saved settings, or the defaults if none were
+// saved or they cannot be read.
+func LoadSettings() Settings {
+	s := DefaultSettings()
+	data, err := loadSettingsData()
+	if err != nil || len(data) == 0 {
+		return s
+	}
+	if err := json.Unmarshal(data, &s); err != nil || s.ServerAddr == "" {
+		return DefaultSettings()
+	}
+	return s
+}
+
+func (s Settings) Save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return saveSettingsData(data)
+}
+
+// WithServer returns s pointed at addr, which may be a bare host:port or
+// carry a ws:// or wss:// scheme. Without a scheme the current Secure
+// setting is kept. A trailing /ws path is dropped since Dial adds it.
+func (s Settings) WithServer(addr string) Settings {
+	addr = strings.TrimSpace(addr)
+	switch {
+	case strings.HasPrefix(addr, "wss://"):
+		s.Secure = true
+		addr = strings.TrimPrefix(addr, "wss://")
+	case strings.HasPrefix(addr, "ws://"):
+		s.Secure = false
+		addr = strings.TrimPrefix(addr, "ws://")
+	}
+	addr = strings.TrimSuffix(strings.TrimSuffix(addr, "/"), "/ws")
+	if addr != "" {
+		s.ServerAddr = addr
+	}
+	return s
+}
+
+func (s Settings) scheme() string {
+	if s.Secure {
+		return "wss://"
+	}
+	return "ws://"
+}
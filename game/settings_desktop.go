@@ -0,0 +1,41 @@
+//go:build !js
+
+package game
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func settingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "oneofthem", "settings.json"), nil
+}
+
+func loadSettingsData() ([]byte, error) {
+	path, err := settingsPath()
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func saveSettingsData(data []byte) error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// QueryParam returns a parameter from the page URL. There is no page on
+// desktop so it always returns "".
+func QueryParam(name string) string {
+	return ""
+}
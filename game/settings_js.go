@@ -0,0 +1,43 @@
+package game
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+const settingsKey = "oneofthem.settings"
+
+func loadSettingsData() ([]byte, error) {
+	storage := js.Global().Get("localStorage")
+	if !storage.Truthy() {
+		return nil, errors.New("localStorage unavailable")
+	}
+	v := storage.Call("getItem", settingsKey)
+	if v.IsNull() {
+		return nil, nil
+	}
+	return []byte(v.String()), nil
+}
+
+func saveSettingsData(data []byte) error {
+	storage := js.Global().Get("localStorage")
+	if !storage.Truthy() {
+		return errors.New("localStorage unavailable")
+	}
+	storage.Call("setItem", settingsKey, string(data))
+	return nil
+}
+
+// QueryParam returns a parameter from the page URL, e.g. ?server=localhost:8080.
+func QueryParam(name string) string {
+	search := js.Global().Get("location").Get("search")
+	if !search.Truthy() {
+		return ""
+	}
+	params := js.Global().Get("URLSearchParams").New(search)
+	v := params.Call("get", name)
+	if v.IsNull() {
+		return ""
+	}
+	return v.String()
+}
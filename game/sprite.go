@@ -106,7 +106,7 @@ func upRestingFrame(clock int) *ebiten.Image {
 	).(*ebiten.Image)
 }
 
-func downRightAttackingFrame(clock int) *ebiten.Image {
+func downRightAttackingFrame(frame int) *ebiten.Image {
 	const (
 		frameNum    = 4
 		frameWidth  = 40
@@ -114,7 +114,7 @@ func downRightAttackingFrame(clock int) *ebiten.Image {
 		frameOX     = 0
 		frameOY     = 160
 	)
-	i := frameNum - (clock+4)/5
+	i := frame % frameNum
 	sx := frameOX + i*frameWidth
 	sy := frameOY
 
@@ -123,7 +123,7 @@ func downRightAttackingFrame(clock int) *ebiten.Image {
 	).(*ebiten.Image)
 }
 
-func downAttackingFrame(clock int) *ebiten.Image {
+func downAttackingFrame(frame int) *ebiten.Image {
 	const (
 		frameNum    = 4
 		frameWidth  = 40
@@ -131,7 +131,7 @@ func downAttackingFrame(clock int) *ebiten.Image {
 		frameOX     = 0
 		frameOY     = 216
 	)
-	i := frameNum - (clock+4)/5
+	i := frame % frameNum
 	sx := frameOX + i*frameWidth
 	sy := frameOY
 
@@ -140,7 +140,7 @@ func downAttackingFrame(clock int) *ebiten.Image {
 	).(*ebiten.Image)
 }
 
-func rightAttackingFrame(clock int) *ebiten.Image {
+func rightAttackingFrame(frame int) *ebiten.Image {
 	const (
 		frameNum    = 4
 		frameWidth  = 40
@@ -148,7 +148,7 @@ func rightAttackingFrame(clock int) *ebiten.Image {
 		frameOX     = 0
 		frameOY     = 272
 	)
-	i := frameNum - (clock+4)/5
+	i := frame % frameNum
 	sx := frameOX + i*frameWidth
 	sy := frameOY
 
@@ -157,7 +157,7 @@ func rightAttackingFrame(clock int) *ebiten.Image {
 	).(*ebiten.Image)
 }
 
-func upRightAttackingFrame(clock int) *ebiten.Image {
+func upRightAttackingFrame(frame int) *ebiten.Image {
 	const (
 		frameNum    = 4
 		frameWidth  = 40
@@ -165,7 +165,7 @@ func upRightAttackingFrame(clock int) *ebiten.Image {
 		frameOX     = 0
 		frameOY     = 328
 	)
-	i := frameNum - (clock+4)/5
+	i := frame % frameNum
 	sx := frameOX + i*frameWidth
 	sy := frameOY
 
@@ -174,7 +174,7 @@ func upRightAttackingFrame(clock int) *ebiten.Image {
 	).(*ebiten.Image)
 }
 
-func upAttackingFrame(clock int) *ebiten.Image {
+func upAttackingFrame(frame int) *ebiten.Image {
 	const (
 		frameNum    = 4
 		frameWidth  = 40
@@ -182,7 +182,7 @@ func upAttackingFrame(clock int) *ebiten.Image {
 		frameOX     = 0
 		frameOY     = 384
 	)
-	i := frameNum - (clock+4)/5
+	i := frame % frameNum
 	sx := frameOX + i*frameWidth
 	sy := frameOY
 
@@ -0,0 +1,56 @@
+package state
+
+import (
+	"math"
+
+	"github.com/kisunji/ebiten-poc/common"
+)
+
+// cameraSmoothing is how much of the way to its target the camera moves
+// each frame.
+const cameraSmoothing = .15
+
+// Camera is the part of the arena shown on screen. It trails the point it
+// follows and stops at the arena's edges. Arenas smaller than the screen
+// are centred.
+type Camera struct {
+	// Top left corner of the view, in world pixels.
+	X, Y   float64
+	placed bool
+}
+
+// Follow moves the camera part of the way towards centring (x, y). The
+// first call jumps straight there.
+func (c *Camera) Follow(x, y float64, arena *common.Arena) {
+	tx := clampView(x-common.ScreenWidth/2, arena.PixelWidth(), common.ScreenWidth)
+	ty := clampView(y-common.ScreenHeight/2, arena.PixelHeight(), common.ScreenHeight)
+	if !c.placed {
+		c.X, c.Y, c.placed = tx, ty, true
+		return
+	}
+	c.X = approach(c.X, tx)
+	c.Y = approach(c.Y, ty)
+}
+
+func approach(from, to float64) float64 {
+	if math.Abs(to-from) < .5 {
+		return to
+	}
+	return from + (to-from)*cameraSmoothing
+}
+
+// clampView keeps a view of size view inside a world of size world, or
+// centres it if the world is smaller.
+func clampView(pos, world, view float64) float64 {
+	if world <= view {
+		return (world - view) / 2
+	}
+	return math.Max(0, math.Min(pos, world-view))
+}
+
+// Visible reports whether any of a w by h box centred on (x, y) is on
+// screen.
+func (c *Camera) Visible(x, y, w, h float64) bool {
+	return x+w/2 >= c.X && x-w/2 <= c.X+common.ScreenWidth &&
+		y+h/2 >= c.Y && y-h/2 <= c.Y+common.ScreenHeight
+}
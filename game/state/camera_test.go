@@ -0,0 +1,113 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kisunji/ebiten-poc/common"
+)
+
+// openArena makes an arena of w by h open 16 pixel tiles.
+func openArena(t *testing.T, w, h int) *common.Arena {
+	t.Helper()
+	data := fmt.Sprintf(`{
+ "orientation": "orthogonal", "width": %d, "height": %d, "tilewidth": 16, "tileheight": 16,
+ "layers": [{"type": "tilelayer", "name": "floor", "data": [%s]}]
+}`, w, h, strings.TrimSuffix(strings.Repeat("1,", w*h), ","))
+	a, err := common.ParseArena("test", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestCameraBounds(t *testing.T) {
+	// twice the screen each way
+	big := openArena(t, 60, 40)
+	small := openArena(t, 10, 10)
+	tests := []struct {
+		name         string
+		arena        *common.Arena
+		x, y         float64
+		wantX, wantY float64
+	}{
+		{"middle", big, 480, 320, 240, 160},
+		{"top left", big, 0, 0, 0, 0},
+		{"left", big, 10, 320, 0, 160},
+		{"top", big, 480, 10, 240, 0},
+		{"right", big, 950, 320, 480, 160},
+		{"bottom", big, 480, 630, 240, 320},
+		{"bottom right", big, 960, 640, 480, 320},
+		{"smaller than the screen", small, 0, 0, -160, -80},
+		{"smaller than the screen, far corner", small, 160, 160, -160, -80},
+	}
+	for _, tt := range tests {
+		var c Camera
+		c.Follow(tt.x, tt.y, tt.arena)
+		if c.X != tt.wantX || c.Y != tt.wantY {
+			t.Errorf("%s: following (%v, %v) got (%v, %v), want (%v, %v)",
+				tt.name, tt.x, tt.y, c.X, c.Y, tt.wantX, tt.wantY)
+		}
+	}
+}
+
+func TestCameraConverges(t *testing.T) {
+	arena := openArena(t, 60, 40)
+	tests := []struct {
+		name         string
+		x, y         float64
+		wantX, wantY float64
+	}{
+		{"down and right", 720, 480, 480, 320},
+		{"up and left", 240, 160, 0, 0},
+		{"into a corner it cannot centre", 2000, -50, 480, 0},
+	}
+	for _, tt := range tests {
+		var c Camera
+		c.Follow(480, 320, arena)
+		lastX, lastY := c.X, c.Y
+		frames := 0
+		for c.X != tt.wantX || c.Y != tt.wantY {
+			c.Follow(tt.x, tt.y, arena)
+			// never overshoots and never stops short
+			if dx := tt.wantX - c.X; dx*(tt.wantX-lastX) < 0 || (dx != 0 && c.X == lastX) {
+				t.Fatalf("%s: x went from %v to %v heading for %v", tt.name, lastX, c.X, tt.wantX)
+			}
+			if dy := tt.wantY - c.Y; dy*(tt.wantY-lastY) < 0 || (dy != 0 && c.Y == lastY) {
+				t.Fatalf("%s: y went from %v to %v heading for %v", tt.name, lastY, c.Y, tt.wantY)
+			}
+			lastX, lastY = c.X, c.Y
+			if frames++; frames > 120 {
+				t.Fatalf("%s: still at (%v, %v) after two seconds", tt.name, c.X, c.Y)
+			}
+		}
+		if frames < 2 {
+			t.Fatalf("%s: jumped straight to the target", tt.name)
+		}
+	}
+}
+
+func TestCameraVisible(t *testing.T) {
+	c := Camera{X: 100, Y: 50}
+	tests := []struct {
+		x, y float64
+		want bool
+	}{
+		{300, 200, true},
+		// partly on screen at each edge
+		{95, 200, true},
+		{585, 200, true},
+		{300, 45, true},
+		{300, 375, true},
+		{80, 200, false},
+		{600, 200, false},
+		{300, 30, false},
+		{300, 390, false},
+	}
+	for _, tt := range tests {
+		if got := c.Visible(tt.x, tt.y, 16, 16); got != tt.want {
+			t.Errorf("box at (%v, %v): got %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
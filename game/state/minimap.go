@@ -0,0 +1,94 @@
+package state
+
+import (
+	"math"
+
+	"github.com/kisunji/ebiten-poc/common"
+)
+
+// MarkKind is what a minimap mark stands for.
+type MarkKind int
+
+const (
+	MarkWall MarkKind = iota
+	MarkCoin
+	// The part of the arena the camera shows, drawn as an outline.
+	MarkView
+	MarkYou
+)
+
+// Mark is a rectangle on the minimap, in minimap pixels.
+type Mark struct {
+	Kind       MarkKind
+	X, Y, W, H float64
+}
+
+// MinimapLayout fits an arena into the largest minimap within a maximum
+// size that keeps the arena's shape.
+type MinimapLayout struct {
+	Arena *common.Arena
+	// Minimap pixels per world pixel.
+	Scale float64
+	// Size of the minimap in pixels.
+	Width, Height int
+}
+
+func NewMinimapLayout(arena *common.Arena, maxWidth, maxHeight float64) MinimapLayout {
+	scale := math.Min(maxWidth/arena.PixelWidth(), maxHeight/arena.PixelHeight())
+	return MinimapLayout{
+		Arena:  arena,
+		Scale:  scale,
+		Width:  int(math.Ceil(arena.PixelWidth() * scale)),
+		Height: int(math.Ceil(arena.PixelHeight() * scale)),
+	}
+}
+
+// Walls returns a mark for each wall tile. Walls do not change during a
+// match, so they only need drawing once.
+func (l MinimapLayout) Walls() []Mark {
+	var marks []Mark
+	tile := float64(l.Arena.TileSize) * l.Scale
+	for ty := 0; ty < l.Arena.Height; ty++ {
+		for tx := 0; tx < l.Arena.Width; tx++ {
+			if l.Arena.Solid(tx, ty) {
+				marks = append(marks, Mark{Kind: MarkWall, X: float64(tx) * tile, Y: float64(ty) * tile, W: tile, H: tile})
+			}
+		}
+	}
+	return marks
+}
+
+// Marks appends to dst what the minimap shows of m this frame: coins lying
+// around, what the camera sees and, while alive, the local player in slot
+// you. Other characters are left out so the minimap gives nobody away.
+func (l MinimapLayout) Marks(dst []Mark, m *Match, you int32, camera *Camera) []Mark {
+	for _, coin := range m.Coins {
+		if !coin.PickedUp {
+			dst = append(dst, l.dot(MarkCoin, coin.Px, coin.Py, 1))
+		}
+	}
+	dst = append(dst, Mark{
+		Kind: MarkView,
+		X:    camera.X * l.Scale,
+		Y:    camera.Y * l.Scale,
+		W:    common.ScreenWidth * l.Scale,
+		H:    common.ScreenHeight * l.Scale,
+	})
+	if int(you) < len(m.Chars) {
+		if char := m.Chars[you]; char != nil && !char.IsDead {
+			dst = append(dst, l.dot(MarkYou, char.Px, char.Py, 2))
+		}
+	}
+	return dst
+}
+
+// dot marks world position (x, y) with a square size pixels across.
+func (l MinimapLayout) dot(kind MarkKind, x, y, size float64) Mark {
+	return Mark{
+		Kind: kind,
+		X:    math.Round(x*l.Scale - size/2),
+		Y:    math.Round(y*l.Scale - size/2),
+		W:    size,
+		H:    size,
+	}
+}
@@ -0,0 +1,138 @@
+package state
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kisunji/ebiten-poc/common"
+)
+
+func TestMinimapLayout(t *testing.T) {
+	tests := []struct {
+		name                  string
+		w, h                  int
+		wantScale             float64
+		wantWidth, wantHeight int
+	}{
+		{"same shape", 60, 40, .1, 96, 64},
+		{"wide", 120, 40, .05, 96, 32},
+		{"tall", 30, 80, .05, 24, 64},
+	}
+	for _, tt := range tests {
+		l := NewMinimapLayout(openArena(t, tt.w, tt.h), 96, 64)
+		if l.Scale != tt.wantScale || l.Width != tt.wantWidth || l.Height != tt.wantHeight {
+			t.Errorf("%s: got scale %v size %dx%d, want scale %v size %dx%d",
+				tt.name, l.Scale, l.Width, l.Height, tt.wantScale, tt.wantWidth, tt.wantHeight)
+		}
+	}
+}
+
+func TestMinimapWalls(t *testing.T) {
+	// 4 by 2 tiles with walls at (1, 0) and (3, 1)
+	data := `{
+ "orientation": "orthogonal", "width": 4, "height": 2, "tilewidth": 16, "tileheight": 16,
+ "layers": [{"type": "tilelayer", "name": "walls", "data": [0,1,0,0, 0,0,0,1],
+  "properties": [{"name": "solid", "type": "bool", "value": true}]}]
+}`
+	arena, err := common.ParseArena("test", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 64x32 pixels fit 32x16 at half scale
+	l := NewMinimapLayout(arena, 32, 16)
+	want := []Mark{
+		{Kind: MarkWall, X: 8, Y: 0, W: 8, H: 8},
+		{Kind: MarkWall, X: 24, Y: 8, W: 8, H: 8},
+	}
+	if got := l.Walls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got walls %v, want %v", got, want)
+	}
+}
+
+func TestMinimapMarks(t *testing.T) {
+	arena := openArena(t, 60, 40)
+	l := NewMinimapLayout(arena, 96, 64)
+
+	at := func(x, y float64) *common.Char {
+		c := common.NewChar()
+		c.Px, c.Py = x, y
+		return c
+	}
+	// slot 0 is the local player, slot 1 another player, the rest the crowd
+	newMatch := func() *Match {
+		return &Match{
+			Arena: arena,
+			Chars: common.Chars{at(100, 200), at(300, 400), at(500, 600), at(700, 100)},
+			Coins: []*common.Coin{
+				common.NewCoinAt(50, 60),
+				{Px: 70, Py: 80, PickedUp: true},
+				common.NewCoinAt(900, 620),
+			},
+		}
+	}
+	camera := &Camera{X: 240, Y: 160}
+	view := Mark{Kind: MarkView, X: 24, Y: 16, W: 48, H: 32}
+	coins := []Mark{
+		{Kind: MarkCoin, X: 5, Y: 6, W: 1, H: 1},
+		{Kind: MarkCoin, X: 90, Y: 62, W: 1, H: 1},
+	}
+	you := Mark{Kind: MarkYou, X: 9, Y: 19, W: 2, H: 2}
+
+	tests := []struct {
+		name  string
+		match func() *Match
+		slot  int32
+		want  []Mark
+	}{
+		{"alive", newMatch, 0, append(append(coins[:len(coins):len(coins)], view), you)},
+		{"dead", func() *Match {
+			m := newMatch()
+			m.Chars[0].IsDead = true
+			return m
+		}, 0, append(coins[:len(coins):len(coins)], view)},
+		{"no character", func() *Match {
+			m := newMatch()
+			m.Chars[0] = nil
+			return m
+		}, 0, append(coins[:len(coins):len(coins)], view)},
+		{"slot out of range", newMatch, 9, append(coins[:len(coins):len(coins)], view)},
+	}
+	for _, tt := range tests {
+		got := l.Marks(nil, tt.match(), tt.slot, camera)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got marks %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Only the local player may show up on the minimap: anyone else would be
+// given away by their dot moving like a human.
+func TestMinimapHidesOthers(t *testing.T) {
+	arena := openArena(t, 60, 40)
+	l := NewMinimapLayout(arena, 96, 64)
+	m := &Match{Arena: arena}
+	for i := 0; i < 8; i++ {
+		c := common.NewChar()
+		c.Px, c.Py = float64(100*i+50), float64(60*i+50)
+		m.Chars = append(m.Chars, c)
+	}
+	for slot := int32(0); slot < int32(len(m.Chars)); slot++ {
+		var chars []string
+		for _, mark := range l.Marks(nil, m, slot, &Camera{}) {
+			switch mark.Kind {
+			case MarkYou:
+				chars = append(chars, fmt.Sprintf("(%v, %v)", mark.X, mark.Y))
+			case MarkCoin, MarkView:
+			default:
+				t.Errorf("slot %d: unexpected mark %v", slot, mark)
+			}
+		}
+		c := m.Chars[slot]
+		want := fmt.Sprintf("(%v, %v)", c.Px*l.Scale-1, c.Py*l.Scale-1)
+		if got := strings.Join(chars, " "); got != want {
+			t.Errorf("slot %d: got characters at %s, want only %s", slot, got, want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package state
+
+import (
+	"errors"
+
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+// ErrDisconnected is returned by Receive once the connection is lost.
+var ErrDisconnected = errors.New("lost connection to server")
+
+// Receive is what the lobby and match scenes do with the server's messages
+// every frame. It decodes each message waiting on recv and passes it to
+// apply, in order, and returns nil once none are left. It stops with the
+// decode error at a message that cannot be decoded, and with
+// ErrDisconnected if disconnect is signalled once the messages that came
+// before it are applied.
+func Receive(recv <-chan []byte, disconnect <-chan bool, decode func([]byte) (*pb.ServerMessage, error), apply func(*pb.ServerMessage)) error {
+	for {
+		select {
+		case data := <-recv:
+			msg, err := decode(data)
+			if err != nil {
+				return err
+			}
+			apply(msg)
+			continue
+		default:
+		}
+		select {
+		case <-disconnect:
+			return ErrDisconnected
+		default:
+			// no more messages
+			return nil
+		}
+	}
+}
@@ -0,0 +1,488 @@
+// Package state tracks what the client knows about the lobby and the match
+// from server messages. It does not depend on ebiten so it can be tested
+// without a display.
+package state
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+// noticeDuration is how long a server notice stays on screen.
+const noticeDuration = 8 * time.Second
+
+const (
+	// How long a kill stays in the feed, fading out over the last
+	// killFadeDuration.
+	killDuration     = 5 * time.Second
+	killFadeDuration = 2 * time.Second
+	// Most kills shown at once.
+	maxKillFeed = 4
+)
+
+// Kill is one entry of the kill feed.
+type Kill struct {
+	Killer int32
+	Victim int32
+	At     time.Time
+}
+
+func (k Kill) String() string {
+	return fmt.Sprintf("Player %d killed Player %d", k.Killer+1, k.Victim+1)
+}
+
+// Alpha is how opaque the entry is at now, from 1 down to 0 once it has
+// faded out.
+func (k Kill) Alpha(now time.Time) float64 {
+	left := killDuration - now.Sub(k.At)
+	if left >= killFadeDuration {
+		return 1
+	}
+	if left <= 0 {
+		return 0
+	}
+	return float64(left) / float64(killFadeDuration)
+}
+
+// Notice is the latest message from the server operator.
+type Notice struct {
+	Message string
+	At      time.Time
+	// When the server will stop, if it announced a countdown.
+	Deadline time.Time
+}
+
+func newNotice(sn *pb.ServerNotice, now time.Time) Notice {
+	n := Notice{Message: sn.Message, At: now}
+	if sn.Countdown > 0 {
+		n.Deadline = now.Add(time.Duration(sn.Countdown) * time.Second)
+	}
+	return n
+}
+
+// Visible reports whether the notice should still be shown at now. A
+// countdown stays up until it runs out.
+func (n Notice) Visible(now time.Time) bool {
+	if n.Message == "" {
+		return false
+	}
+	if !n.Deadline.IsZero() {
+		return now.Before(n.Deadline)
+	}
+	return now.Sub(n.At) < noticeDuration
+}
+
+// Text is the notice as shown at now, including any countdown.
+func (n Notice) Text(now time.Time) string {
+	if n.Deadline.IsZero() {
+		return n.Message
+	}
+	left := int(math.Ceil(n.Deadline.Sub(now).Seconds()))
+	return fmt.Sprintf("%s (%d:%02d)", n.Message, left/60, left%60)
+}
+
+type Lobby struct {
+	Players []bool
+	YourID  int32
+	HostID  int32
+	// Reason the server refused the connection, if it did.
+	ConnectError string
+	// Set once the host starts the game.
+	Started bool
+	Notice  Notice
+	// Map picked for the match and the maps the host can pick from.
+	Map  string
+	Maps []string
+	// Characters in a match, players included, as the host sized the
+	// room. The room has a player slot for each of Players.
+	CrowdSize int
+	// Size of the match, known once it starts.
+	PlayerSlots, Crowd int
+}
+
+// Room sizes the host can pick from, smallest first.
+var (
+	playerSlotChoices = []int{2, 4, 8, 16, 32, common.MaxClients}
+	crowdChoices      = []int{16, 32, 64, 128, 256, 512}
+)
+
+// NewLobby returns an empty lobby. The server sends how many player
+// slots the room has once connected.
+func NewLobby() *Lobby {
+	return &Lobby{}
+}
+
+// Connected reports whether a player has taken slot i.
+func (l *Lobby) Connected(i int) bool {
+	return i < len(l.Players) && l.Players[i]
+}
+
+// IsHost reports whether this client may start the game.
+func (l *Lobby) IsHost() bool {
+	return l.HostID == l.YourID
+}
+
+// NextMap returns the map after the picked one, wrapping around, or the
+// picked one if there is no other.
+func (l *Lobby) NextMap() string {
+	for i, name := range l.Maps {
+		if name == l.Map {
+			return l.Maps[(i+1)%len(l.Maps)]
+		}
+	}
+	if len(l.Maps) > 0 {
+		return l.Maps[0]
+	}
+	return l.Map
+}
+
+// NextPlayerSlots returns the next room size up that fits everyone
+// connected and the crowd, wrapping around, or the current size if no
+// other does.
+func (l *Lobby) NextPlayerSlots() int {
+	return nextChoice(playerSlotChoices, len(l.Players), func(players int) bool {
+		return l.fits(players, l.CrowdSize)
+	})
+}
+
+// NextCrowd returns the next crowd size up that fits the room, wrapping
+// around, or the current size if no other does.
+func (l *Lobby) NextCrowd() int {
+	return nextChoice(crowdChoices, l.CrowdSize, func(crowd int) bool {
+		return l.fits(len(l.Players), crowd)
+	})
+}
+
+// fits reports whether the server would accept a room of players player
+// slots and crowd characters.
+func (l *Lobby) fits(players, crowd int) bool {
+	for i := players; i < len(l.Players); i++ {
+		if l.Players[i] {
+			return false
+		}
+	}
+	return players >= 1 && players <= common.MaxClients && crowd >= players && crowd+players <= common.MaxChars
+}
+
+// nextChoice returns the first of choices above current that ok accepts,
+// wrapping around, or current if there is none.
+func nextChoice(choices []int, current int, ok func(int) bool) int {
+	start := sort.SearchInts(choices, current+1)
+	for i := range choices {
+		if c := choices[(start+i)%len(choices)]; c != current && ok(c) {
+			return c
+		}
+	}
+	return current
+}
+
+// Apply updates the lobby from a server message.
+func (l *Lobby) Apply(msg *pb.ServerMessage) {
+	switch buf := msg.Content.(type) {
+	case *pb.ServerMessage_ConnectResponse:
+		log.Println("connected")
+		if buf.ConnectResponse.IsHost {
+			log.Println("is host")
+			l.HostID = buf.ConnectResponse.ClientSlot
+		}
+		l.YourID = buf.ConnectResponse.ClientSlot
+	case *pb.ServerMessage_ConnectError:
+		log.Println(buf.ConnectError.Message)
+		l.ConnectError = buf.ConnectError.Message
+	case *pb.ServerMessage_UpdateLobby:
+		l.Players = buf.UpdateLobby.ConnectedSlots
+		l.HostID = buf.UpdateLobby.HostSlot
+		l.Map = buf.UpdateLobby.Map
+		l.Maps = buf.UpdateLobby.Maps
+		l.CrowdSize = int(buf.UpdateLobby.Crowd)
+	case *pb.ServerMessage_GameStart:
+		l.Started = true
+		l.Map = buf.GameStart.GetMap()
+		l.PlayerSlots = int(buf.GameStart.GetPlayers())
+		l.Crowd = int(buf.GameStart.GetCrowd())
+	case *pb.ServerMessage_PlayerDisconnected:
+		if int(buf.PlayerDisconnected.Id) < len(l.Players) {
+			l.Players[buf.PlayerDisconnected.Id] = false
+		}
+	case *pb.ServerMessage_NewHost:
+		l.HostID = buf.NewHost.Id
+	case *pb.ServerMessage_ServerNotice:
+		l.Notice = newNotice(buf.ServerNotice, time.Now())
+	case *pb.ServerMessage_UpdateEntity:
+		// just suppress
+	default:
+		log.Printf("Unknown message type %T\n", buf)
+	}
+}
+
+type Match struct {
+	Chars      common.Chars
+	Coins      []*common.Coin
+	StartTime  int64 // unixtime
+	Duration   time.Duration
+	EndMessage string
+	Notice     Notice
+	// Recent kills, oldest first.
+	KillFeed []Kill
+	// Until when each player is stunned and outlined for killing an AI.
+	StunnedUntil  []time.Time
+	RevealedUntil []time.Time
+	// Attack phase lengths the server plays with.
+	Timing common.AttackTiming
+	// Map the match is played on.
+	Arena *common.Arena
+	// How far the local player can see, in pixels. Zero means no limit.
+	Vision float64
+	// Power-ups by index, and until when each kind the local player
+	// collected lasts.
+	Pickups     []*common.Pickup
+	EffectUntil [common.NumPickupKinds]time.Time
+}
+
+// NewMatch returns a match of the default size, until Resize is told
+// otherwise.
+func NewMatch() *Match {
+	m := &Match{
+		Timing: common.DefaultAttackTiming,
+		Arena:  common.Arenas[common.DefaultArena],
+	}
+	m.Resize(common.DefaultPlayers, common.DefaultCrowd)
+	return m
+}
+
+// Resize makes room for the number of player slots and characters the
+// server announced when the match started, dropping any characters
+// already known. Zero sizes, from servers that do not announce them,
+// leave the match as it is.
+func (m *Match) Resize(players, crowd int) {
+	if players <= 0 || crowd <= 0 {
+		return
+	}
+	m.Chars = make(common.Chars, crowd)
+	m.StunnedUntil = make([]time.Time, players)
+	m.RevealedUntil = make([]time.Time, players)
+}
+
+// SetArena switches to the named map. Maps this client does not know
+// leave the current one in place; the server still decides where
+// everyone is.
+func (m *Match) SetArena(name string) {
+	if a, ok := common.Arenas[name]; ok {
+		m.Arena = a
+	}
+}
+
+// Stunned reports whether character i is stunned at now.
+func (m *Match) Stunned(i int, now time.Time) bool {
+	return i < len(m.StunnedUntil) && now.Before(m.StunnedUntil[i])
+}
+
+// Revealed reports whether character i is outlined at now.
+func (m *Match) Revealed(i int, now time.Time) bool {
+	return i < len(m.RevealedUntil) && now.Before(m.RevealedUntil[i])
+}
+
+// Over reports whether the server has ended the match.
+func (m *Match) Over() bool {
+	return m.EndMessage != ""
+}
+
+// Apply updates the match from a server message.
+func (m *Match) Apply(msg *pb.ServerMessage) {
+	switch content := msg.Content.(type) {
+	case *pb.ServerMessage_UpdateEntity:
+		m.updateChar(content.UpdateEntity)
+	case *pb.ServerMessage_UpdateEntities:
+		for _, ue := range content.UpdateEntities.UpdateEntity {
+			m.updateChar(ue)
+		}
+	case *pb.ServerMessage_NewCoin:
+		nc := content.NewCoin
+		// indexes missed along the way hold coins that are already gone
+		for len(m.Coins) <= int(nc.Index) {
+			m.Coins = append(m.Coins, &common.Coin{PickedUp: true})
+		}
+		m.Coins[nc.Index] = &common.Coin{
+			Px:          nc.Px,
+			Py:          nc.Py,
+			FrameOffset: int(nc.FrameOffset),
+		}
+	case *pb.ServerMessage_NewPickup:
+		np := content.NewPickup
+		for len(m.Pickups) <= int(np.Index) {
+			m.Pickups = append(m.Pickups, nil)
+		}
+		m.Pickups[np.Index] = &common.Pickup{Kind: common.PickupKind(np.Kind), Px: np.Px, Py: np.Py}
+	case *pb.ServerMessage_PickupGot:
+		m.pickupGot(content.PickupGot, time.Now())
+	case *pb.ServerMessage_CoinGot:
+		// todo: sometimes server sends messages from last game
+		if int(content.CoinGot.Index) >= len(m.Coins) {
+			return
+		}
+		m.Coins[content.CoinGot.Index].PickedUp = true
+	case *pb.ServerMessage_CoinGone:
+		if int(content.CoinGone.Index) >= len(m.Coins) {
+			return
+		}
+		m.Coins[content.CoinGone.Index].PickedUp = true
+	case *pb.ServerMessage_TimeSync:
+		m.StartTime = content.TimeSync.StartTime
+		m.Duration = time.Duration(content.TimeSync.Duration) * time.Minute
+		m.Vision = content.TimeSync.Vision
+		if at := content.TimeSync.AttackTiming; at != nil {
+			m.setTiming(common.AttackTiming{
+				WindUp:   int(at.WindUp),
+				Active:   int(at.Active),
+				Recovery: int(at.Recovery),
+				Cooldown: int(at.Cooldown),
+			})
+		}
+	case *pb.ServerMessage_GameEnd:
+		m.EndMessage = endMessage(content.GameEnd)
+	case *pb.ServerMessage_PlayerKilled:
+		m.addKill(content.PlayerKilled, time.Now())
+	case *pb.ServerMessage_PlayerPenalized:
+		pp, now := content.PlayerPenalized, time.Now()
+		if int(pp.Index) >= len(m.StunnedUntil) {
+			return
+		}
+		m.StunnedUntil[pp.Index] = now.Add(time.Duration(pp.StunMillis) * time.Millisecond)
+		m.RevealedUntil[pp.Index] = now.Add(time.Duration(pp.RevealMillis) * time.Millisecond)
+	case *pb.ServerMessage_ServerNotice:
+		m.Notice = newNotice(content.ServerNotice, time.Now())
+	case *pb.ServerMessage_PlayerDisconnected:
+		// maybe kill animation?
+		if int(content.PlayerDisconnected.Id) < len(m.Chars) {
+			m.Chars[content.PlayerDisconnected.Id] = nil
+		}
+	default:
+		log.Printf("Unknown message type %T\n", content)
+	}
+}
+
+func (m *Match) updateChar(ue *pb.UpdateEntity) {
+	if int(ue.Index) >= len(m.Chars) {
+		// the codec only checks against MaxChars
+		return
+	}
+	if ue.Hidden {
+		// out of sight; the server sends it again when it comes back
+		m.Chars[ue.Index] = nil
+		return
+	}
+	m.Chars.UpdateFromData(ue)
+	m.Chars[ue.Index].Timing = m.Timing
+}
+
+func (m *Match) pickupGot(pg *pb.PickupGot, now time.Time) {
+	if int(pg.Index) >= len(m.Pickups) || m.Pickups[pg.Index] == nil {
+		return
+	}
+	p := m.Pickups[pg.Index]
+	p.PickedUp = true
+	if pg.Yours && pg.DurationMillis > 0 {
+		m.EffectUntil[p.Kind] = now.Add(time.Duration(pg.DurationMillis) * time.Millisecond)
+	}
+}
+
+// Effects returns the kinds of power-up working for the local player at
+// now, with the time each has left.
+func (m *Match) Effects(now time.Time) []Effect {
+	var effects []Effect
+	for kind, until := range m.EffectUntil {
+		if now.Before(until) {
+			effects = append(effects, Effect{Kind: common.PickupKind(kind), Left: until.Sub(now)})
+		}
+	}
+	return effects
+}
+
+// Effect is a power-up working for the local player.
+type Effect struct {
+	Kind common.PickupKind
+	Left time.Duration
+}
+
+// setTiming switches every character to the server's attack timing.
+func (m *Match) setTiming(t common.AttackTiming) {
+	m.Timing = t
+	for _, char := range m.Chars {
+		if char != nil {
+			char.Timing = t
+		}
+	}
+}
+
+func (m *Match) addKill(pk *pb.PlayerKilled, now time.Time) {
+	m.KillFeed = append(m.KillFeed, Kill{Killer: pk.Killer, Victim: pk.Victim, At: now})
+	if len(m.KillFeed) > maxKillFeed {
+		m.KillFeed = m.KillFeed[len(m.KillFeed)-maxKillFeed:]
+	}
+}
+
+// VisibleKills returns the kills still in the feed at now.
+func (m *Match) VisibleKills(now time.Time) []Kill {
+	var kills []Kill
+	for _, k := range m.KillFeed {
+		if k.Alpha(now) > 0 {
+			kills = append(kills, k)
+		}
+	}
+	return kills
+}
+
+// Step advances animations and movement by one frame.
+func (m *Match) Step() {
+	for _, char := range m.Chars {
+		if char == nil {
+			continue
+		}
+		char.Attack()
+		char.UseStamina()
+		char.Move(m.Arena)
+	}
+}
+
+// endMessage describes the result of a match. A match decided by the last
+// survivor carries no scores.
+func endMessage(ge *pb.GameEnd) string {
+	var sb strings.Builder
+	sb.WriteString("GAME OVER\n")
+	if len(ge.Score) == 0 {
+		sb.WriteString(fmt.Sprintf("Player %d wins!\n", ge.Survivor+1))
+		for i, kills := range ge.Kills {
+			if kills > 0 {
+				sb.WriteString(fmt.Sprintf("Player %d: %s\n", i+1, plural(kills, "kill")))
+			}
+		}
+		return sb.String()
+	}
+	for i, score := range ge.Score {
+		var kills int32
+		if i < len(ge.Kills) {
+			kills = ge.Kills[i]
+		}
+		switch {
+		case kills > 0:
+			sb.WriteString(fmt.Sprintf("Player %d: %d, %s\n", i+1, score, plural(kills, "kill")))
+		case score > 0:
+			sb.WriteString(fmt.Sprintf("Player %d: %d\n", i+1, score))
+		}
+	}
+	return sb.String()
+}
+
+func plural(n int32, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
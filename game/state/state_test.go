@@ -0,0 +1,556 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+	"github.com/kisunji/ebiten-poc/server"
+	"github.com/kisunji/ebiten-poc/transport"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestLobbyApply(t *testing.T) {
+	l := NewLobby()
+	l.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_ConnectResponse{
+		ConnectResponse: &pb.ConnectResponse{ClientSlot: 1},
+	}})
+	l.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_UpdateLobby{
+		UpdateLobby: &pb.UpdateLobby{ConnectedSlots: []bool{true, true, false, false}, HostSlot: 0},
+	}})
+	if l.YourID != 1 || l.IsHost() || !l.Players[0] || !l.Players[1] {
+		t.Fatalf("unexpected lobby %+v", l)
+	}
+
+	l.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_PlayerDisconnected{
+		PlayerDisconnected: &pb.PlayerDisconnected{Id: 0},
+	}})
+	l.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_NewHost{
+		NewHost: &pb.NewHost{Id: 1},
+	}})
+	if l.Players[0] || !l.IsHost() {
+		t.Fatalf("host did not migrate: %+v", l)
+	}
+
+	// out of range ids are ignored
+	l.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_PlayerDisconnected{
+		PlayerDisconnected: &pb.PlayerDisconnected{Id: 99},
+	}})
+
+	l.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_GameStart{
+		GameStart: &pb.GameStart{Players: 4, Crowd: 50},
+	}})
+	if !l.Started || l.PlayerSlots != 4 || l.Crowd != 50 {
+		t.Fatalf("game did not start: %+v", l)
+	}
+}
+
+func TestLobbyConnectError(t *testing.T) {
+	l := NewLobby()
+	l.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_ConnectError{
+		ConnectError: &pb.ConnectError{Message: "all rooms are full"},
+	}})
+	if l.ConnectError != "all rooms are full" {
+		t.Fatalf("got %q", l.ConnectError)
+	}
+}
+
+func TestMatchApply(t *testing.T) {
+	m := NewMatch()
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_UpdateEntity{
+		UpdateEntity: &pb.UpdateEntity{Index: 2, Px: 100, Py: 100, Vx: 1, Speed: 2},
+	}})
+	m.Step()
+	if c := m.Chars[2]; c == nil || c.Px <= 100 || c.Py != 100 {
+		t.Fatalf("unexpected char %+v", c)
+	}
+
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_UpdateEntity{
+		UpdateEntity: &pb.UpdateEntity{Index: 2, Px: 102, Py: 100, IsDead: true},
+	}})
+	if !m.Chars[2].IsDead {
+		t.Fatal("kill was not applied")
+	}
+
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_NewCoin{
+		NewCoin: &pb.NewCoin{Index: 0, Px: 5, Py: 5},
+	}})
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_CoinGot{
+		CoinGot: &pb.CoinGot{Index: 0},
+	}})
+	// left over from a previous match
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_CoinGot{
+		CoinGot: &pb.CoinGot{Index: 3},
+	}})
+	if len(m.Coins) != 1 || !m.Coins[0].PickedUp {
+		t.Fatalf("unexpected coins %+v", m.Coins)
+	}
+	// a collected coin's index is reused, and a missed one stays empty
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_NewCoin{
+		NewCoin: &pb.NewCoin{Index: 0, Px: 7, Py: 7},
+	}})
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_NewCoin{
+		NewCoin: &pb.NewCoin{Index: 2, Px: 9, Py: 9},
+	}})
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_CoinGone{
+		CoinGone: &pb.CoinGone{Index: 2},
+	}})
+	if len(m.Coins) != 3 || m.Coins[0].PickedUp || m.Coins[0].Px != 7 || !m.Coins[1].PickedUp || !m.Coins[2].PickedUp {
+		t.Fatalf("unexpected coins %+v", m.Coins)
+	}
+
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_PlayerDisconnected{
+		PlayerDisconnected: &pb.PlayerDisconnected{Id: 2},
+	}})
+	if m.Chars[2] != nil {
+		t.Fatal("disconnected player still shown")
+	}
+}
+
+func TestMatchEndMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		end  *pb.GameEnd
+		want string
+	}{
+		{"first player survives", &pb.GameEnd{Survivor: 0}, "Player 1 wins!"},
+		{"last player survives", &pb.GameEnd{Survivor: 3}, "Player 4 wins!"},
+		{"timeout", &pb.GameEnd{Score: []int32{0, 2, 0, 1}}, "Player 2: 2\nPlayer 4: 1"},
+		{"survivor with kills", &pb.GameEnd{Survivor: 1, Kills: []int32{0, 2, 1}}, "Player 2 wins!\nPlayer 2: 2 kills\nPlayer 3: 1 kill\n"},
+		{"timeout with kills", &pb.GameEnd{Score: []int32{0, 2, 0}, Kills: []int32{1, 0, 0}}, "Player 1: 0, 1 kill\nPlayer 2: 2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMatch()
+			m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_GameEnd{GameEnd: tt.end}})
+			if !m.Over() || !strings.Contains(m.EndMessage, tt.want) {
+				t.Fatalf("got %q, want it to contain %q", m.EndMessage, tt.want)
+			}
+		})
+	}
+}
+
+func TestKillFeed(t *testing.T) {
+	m := NewMatch()
+	start := time.Now()
+	for i := int32(0); i < maxKillFeed+1; i++ {
+		m.addKill(&pb.PlayerKilled{Killer: 0, Victim: i + 1}, start.Add(time.Duration(i)*time.Second))
+	}
+	if len(m.KillFeed) != maxKillFeed || m.KillFeed[0].Victim != 2 {
+		t.Fatalf("feed not trimmed to the newest kills: %+v", m.KillFeed)
+	}
+	if got := m.KillFeed[0].String(); got != "Player 1 killed Player 3" {
+		t.Fatalf("got %q", got)
+	}
+
+	k := m.KillFeed[0]
+	if a := k.Alpha(k.At.Add(killDuration - killFadeDuration)); a != 1 {
+		t.Errorf("faded early: %v", a)
+	}
+	if a := k.Alpha(k.At.Add(killDuration - killFadeDuration/2)); a != .5 {
+		t.Errorf("halfway through fading got %v", a)
+	}
+	if a := k.Alpha(k.At.Add(killDuration)); a != 0 {
+		t.Errorf("still visible: %v", a)
+	}
+	if got := m.VisibleKills(k.At.Add(killDuration)); len(got) != maxKillFeed-1 {
+		t.Errorf("got %d visible kills, want %d", len(got), maxKillFeed-1)
+	}
+}
+
+func TestMatchPenalty(t *testing.T) {
+	m := NewMatch()
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_PlayerPenalized{
+		PlayerPenalized: &pb.PlayerPenalized{Index: 1, StunMillis: 1000, RevealMillis: 3000},
+	}})
+	now := time.Now()
+	if !m.Stunned(1, now) || !m.Revealed(1, now) || m.Stunned(0, now) {
+		t.Fatal("penalty not applied to player 2 alone")
+	}
+	if later := now.Add(2 * time.Second); m.Stunned(1, later) || !m.Revealed(1, later) {
+		t.Fatal("stun should end before the reveal")
+	}
+	if m.Revealed(common.MaxChars-1, now) {
+		t.Fatal("AI revealed")
+	}
+}
+
+func TestMatchAttackTiming(t *testing.T) {
+	m := NewMatch()
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_UpdateEntity{
+		UpdateEntity: &pb.UpdateEntity{Index: 2},
+	}})
+	timing := &pb.AttackTiming{WindUp: 1, Active: 2, Recovery: 3, Cooldown: 4}
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_TimeSync{
+		TimeSync: &pb.TimeSync{AttackTiming: timing},
+	}})
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_UpdateEntity{
+		UpdateEntity: &pb.UpdateEntity{Index: 5, AttackPhase: int32(common.AttackActive), AttackFrame: 2},
+	}})
+	want := common.AttackTiming{WindUp: 1, Active: 2, Recovery: 3, Cooldown: 4}
+	if m.Chars[2].Timing != want || m.Chars[5].Timing != want {
+		t.Fatalf("got timings %+v and %+v, want %+v", m.Chars[2].Timing, m.Chars[5].Timing, want)
+	}
+
+	// the client plays the attack out between server updates
+	for _, phase := range []common.AttackPhase{common.AttackActive, common.AttackRecovery} {
+		m.Step()
+		if m.Chars[5].AttackPhase != phase {
+			t.Fatalf("got phase %d, want %d", m.Chars[5].AttackPhase, phase)
+		}
+	}
+}
+
+func TestMatchResize(t *testing.T) {
+	m := NewMatch()
+	m.Resize(16, 200)
+	m.Resize(0, 0)
+	if len(m.Chars) != 200 || len(m.StunnedUntil) != 16 || len(m.RevealedUntil) != 16 {
+		t.Fatalf("got %d characters and %d players", len(m.Chars), len(m.StunnedUntil))
+	}
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_UpdateEntity{
+		UpdateEntity: &pb.UpdateEntity{Index: 150, Px: 100, Py: 100},
+	}})
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_PlayerPenalized{
+		PlayerPenalized: &pb.PlayerPenalized{Index: 12, StunMillis: 1000},
+	}})
+	if m.Chars[150] == nil || !m.Stunned(12, time.Now()) {
+		t.Fatal("updates beyond the default size were lost")
+	}
+
+	// past the end of the match but within what the codec allows
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_UpdateEntity{
+		UpdateEntity: &pb.UpdateEntity{Index: 300},
+	}})
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_PlayerPenalized{
+		PlayerPenalized: &pb.PlayerPenalized{Index: 20},
+	}})
+}
+
+func TestMatchPickups(t *testing.T) {
+	m := NewMatch()
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_NewPickup{
+		NewPickup: &pb.NewPickup{Index: 2, Kind: int32(common.PickupReach), Px: 100, Py: 100},
+	}})
+	if len(m.Pickups) != 3 || m.Pickups[2].Kind != common.PickupReach {
+		t.Fatalf("got pickups %v", m.Pickups)
+	}
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_PickupGot{
+		PickupGot: &pb.PickupGot{Index: 2, Yours: true, DurationMillis: 5000},
+	}})
+	effects := m.Effects(time.Now())
+	if !m.Pickups[2].PickedUp || len(effects) != 1 || effects[0].Kind != common.PickupReach {
+		t.Fatalf("got effects %v", effects)
+	}
+	if len(m.Effects(time.Now().Add(6*time.Second))) != 0 {
+		t.Fatal("effect did not wear off")
+	}
+
+	// someone else's, and one never announced
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_NewPickup{
+		NewPickup: &pb.NewPickup{Index: 0, Kind: int32(common.PickupSpeed)},
+	}})
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_PickupGot{PickupGot: &pb.PickupGot{Index: 0}}})
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_PickupGot{PickupGot: &pb.PickupGot{Index: 1}}})
+	if !m.Pickups[0].PickedUp || len(m.Effects(time.Now())) != 1 {
+		t.Fatal("another player's pickup changed the local effects")
+	}
+}
+
+func TestMatchVision(t *testing.T) {
+	m := NewMatch()
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_TimeSync{
+		TimeSync: &pb.TimeSync{Vision: 120},
+	}})
+	if m.Vision != 120 {
+		t.Fatalf("got vision %v, want 120", m.Vision)
+	}
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_UpdateEntity{
+		UpdateEntity: &pb.UpdateEntity{Index: 3, Px: 100, Py: 100},
+	}})
+	m.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_UpdateEntity{
+		UpdateEntity: &pb.UpdateEntity{Index: 3, Hidden: true},
+	}})
+	if m.Chars[3] != nil {
+		t.Fatalf("hidden character still there: %+v", m.Chars[3])
+	}
+	// stepping must cope with the gap
+	m.Step()
+}
+
+// player runs a connection the way game.Client does, pumping what the
+// server sends into recv, and receives it each frame like the Lobby and
+// MainGame scenes: into a Lobby until the game starts and into a Match
+// afterwards.
+type player struct {
+	t          *testing.T
+	conn       transport.Conn
+	recv       chan []byte
+	disconnect chan bool
+	lobby      *Lobby
+	match      *Match
+	// Why Receive stopped, once it has.
+	err error
+}
+
+func join(t *testing.T, rooms *server.Rooms) *player {
+	serverEnd, clientEnd := transport.Pipe()
+	rooms.Accept(serverEnd, t.Name(), "")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		clientEnd.Close(transport.StatusNormalClosure, "")
+	})
+	p := &player{
+		t:          t,
+		conn:       clientEnd,
+		recv:       make(chan []byte, 256),
+		disconnect: make(chan bool, 1),
+		lobby:      NewLobby(),
+		match:      NewMatch(),
+	}
+	go func() {
+		defer func() { p.disconnect <- true }()
+		for {
+			data, err := clientEnd.Read(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case p.recv <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return p
+}
+
+func (p *player) send(m *pb.ClientMessage) {
+	p.t.Helper()
+	data, err := proto.Marshal(m)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if err := p.conn.Write(context.Background(), data); err != nil {
+		p.t.Fatal(err)
+	}
+}
+
+func (p *player) apply(msg *pb.ServerMessage) {
+	if p.lobby.Started {
+		p.match.Apply(msg)
+		return
+	}
+	p.lobby.Apply(msg)
+	p.match.SetArena(p.lobby.Map)
+	p.match.Resize(p.lobby.PlayerSlots, p.lobby.Crowd)
+}
+
+// until receives server messages a frame at a time until done reports
+// true.
+func (p *player) until(desc string, done func() bool) {
+	p.t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !done() {
+		if p.err != nil {
+			p.t.Fatalf("waiting for %s: %v", desc, p.err)
+		}
+		if time.Now().After(deadline) {
+			p.t.Fatalf("timed out waiting for %s", desc)
+		}
+		p.err = Receive(p.recv, p.disconnect, common.DecodeServerMessage, p.apply)
+		time.Sleep(time.Second / 60)
+	}
+}
+
+func TestScenesAgainstServer(t *testing.T) {
+	cfg := server.DefaultConfig()
+	cfg.PlayerSlots = 2
+	cfg.CrowdSize = 40
+	cfg.MaxRooms = 1
+	// shorter than Validate allows, to reach GameEnd quickly
+	cfg.MatchLength = 300 * time.Millisecond
+	rooms := server.NewRooms(cfg)
+
+	host := join(t, rooms)
+	host.until("host connected", func() bool { return host.lobby.Connected(0) })
+	guest := join(t, rooms)
+	guest.until("guest connected", func() bool { return guest.lobby.Connected(1) })
+	host.until("guest in host lobby", func() bool { return host.lobby.Connected(1) })
+	if !host.lobby.IsHost() || guest.lobby.IsHost() || guest.lobby.YourID != 1 {
+		t.Fatalf("host %+v, guest %+v", host.lobby, guest.lobby)
+	}
+
+	late := join(t, rooms)
+	late.until("room full", func() bool { return late.lobby.ConnectError != "" })
+	late.until("hang up", func() bool { return errors.Is(late.err, ErrDisconnected) })
+
+	// the host sizes the room for everyone
+	if host.lobby.CrowdSize != cfg.CrowdSize || len(host.lobby.Players) != cfg.PlayerSlots {
+		t.Fatalf("host sees %d players and %d characters", len(host.lobby.Players), host.lobby.CrowdSize)
+	}
+	crowd := host.lobby.NextCrowd()
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_SetCapacity{
+		SetCapacity: &pb.SetCapacity{Players: int32(cfg.PlayerSlots), Crowd: int32(crowd)},
+	}})
+	guest.until("room resized", func() bool { return guest.lobby.CrowdSize == crowd })
+
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_SelectMap{
+		SelectMap: &pb.SelectMap{Name: host.lobby.NextMap()},
+	}})
+	guest.until("map picked", func() bool { return guest.lobby.Map != common.DefaultArena })
+	picked := guest.lobby.Map
+
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_StartGame{}})
+	guest.until("game start", func() bool { return guest.lobby.Started })
+	if guest.match.Arena.Name != picked {
+		t.Fatalf("playing on %s, want %s", guest.match.Arena.Name, picked)
+	}
+	guest.send(&pb.ClientMessage{Content: &pb.ClientMessage_WorldUpdate{}})
+	guest.until("time sync", func() bool { return guest.match.StartTime != 0 })
+	// the crowd, then a decoy slot per player
+	if len(guest.match.Chars) != crowd+cfg.PlayerSlots || len(guest.match.StunnedUntil) != cfg.PlayerSlots {
+		t.Fatalf("got %d characters and %d players, want %d and %d",
+			len(guest.match.Chars), len(guest.match.StunnedUntil), crowd+cfg.PlayerSlots, cfg.PlayerSlots)
+	}
+	for i, c := range guest.match.Chars[:crowd] {
+		if c == nil {
+			t.Fatalf("char %d missing after world update", i)
+		}
+	}
+
+	guest.until("game end", guest.match.Over)
+	host.until("game start", func() bool { return host.lobby.Started })
+	host.until("game end", host.match.Over)
+	if host.match.EndMessage != guest.match.EndMessage {
+		t.Fatalf("host saw %q, guest saw %q", host.match.EndMessage, guest.match.EndMessage)
+	}
+}
+
+func TestLobbyNextSize(t *testing.T) {
+	l := NewLobby()
+	l.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_UpdateLobby{
+		UpdateLobby: &pb.UpdateLobby{ConnectedSlots: make([]bool, 8), Crowd: 32},
+	}})
+	l.Players[0] = true
+	if got := l.NextPlayerSlots(); got != 16 {
+		t.Fatalf("after 8 players got %d", got)
+	}
+	if got := l.NextCrowd(); got != 64 {
+		t.Fatalf("after a crowd of 32 got %d", got)
+	}
+
+	// 64 players need a bigger crowd, so it wraps around to 2
+	l.Players = make([]bool, 32)
+	l.Players[0] = true
+	if got := l.NextPlayerSlots(); got != 2 {
+		t.Fatalf("after 32 players got %d", got)
+	}
+	// a crowd of 16 is too small for 32 players
+	l.CrowdSize = 512
+	if got := l.NextCrowd(); got != 32 {
+		t.Fatalf("after a crowd of 512 got %d", got)
+	}
+
+	// the room cannot shrink past a connected player
+	l.CrowdSize = 32
+	l.Players[5] = true
+	if got := l.NextPlayerSlots(); got != 8 {
+		t.Fatalf("with slot 5 taken got %d", got)
+	}
+}
+
+func TestLobbyNextMap(t *testing.T) {
+	l := NewLobby()
+	if l.NextMap() != "" {
+		t.Fatal("picked a map without any to pick from")
+	}
+	l.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_UpdateLobby{
+		UpdateLobby: &pb.UpdateLobby{Map: "b", Maps: []string{"a", "b", "c"}},
+	}})
+	for _, want := range []string{"c", "a", "b"} {
+		if l.Map = l.NextMap(); l.Map != want {
+			t.Fatalf("got %s, want %s", l.Map, want)
+		}
+	}
+}
+
+func TestNotice(t *testing.T) {
+	l := NewLobby()
+	if l.Notice.Visible(time.Now()) {
+		t.Fatal("empty notice is visible")
+	}
+	l.Apply(&pb.ServerMessage{Content: &pb.ServerMessage_ServerNotice{
+		ServerNotice: &pb.ServerNotice{Message: "restarting soon"},
+	}})
+	if !l.Notice.Visible(time.Now()) || l.Notice.Text(time.Now()) != "restarting soon" {
+		t.Fatalf("got notice %+v", l.Notice)
+	}
+	if l.Notice.Visible(l.Notice.At.Add(noticeDuration)) {
+		t.Fatal("notice never expires")
+	}
+}
+
+func TestNoticeCountdown(t *testing.T) {
+	now := time.Now()
+	n := newNotice(&pb.ServerNotice{Message: "server is shutting down", Countdown: 90}, now)
+	if got := n.Text(now.Add(500 * time.Millisecond)); got != "server is shutting down (1:30)" {
+		t.Fatalf("got %q", got)
+	}
+	if !n.Visible(now.Add(noticeDuration * 2)) {
+		t.Fatal("countdown hidden before it ran out")
+	}
+	if n.Visible(now.Add(90 * time.Second)) {
+		t.Fatal("countdown shown after it ran out")
+	}
+}
+
+func TestReceive(t *testing.T) {
+	encode := func(m *pb.ServerMessage) []byte {
+		data, err := proto.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	recv, disconnect := make(chan []byte, 4), make(chan bool, 1)
+	m := NewMatch()
+	receive := func() error {
+		return Receive(recv, disconnect, common.DecodeServerMessage, m.Apply)
+	}
+	if err := receive(); err != nil {
+		t.Fatalf("nothing waiting: %v", err)
+	}
+
+	recv <- encode(&pb.ServerMessage{Content: &pb.ServerMessage_ServerNotice{
+		ServerNotice: &pb.ServerNotice{Message: "restarting"},
+	}})
+	recv <- encode(&pb.ServerMessage{Content: &pb.ServerMessage_GameEnd{GameEnd: &pb.GameEnd{}}})
+	if err := receive(); err != nil || m.Notice.Message != "restarting" || !m.Over() {
+		t.Fatalf("got %v, notice %v, over %v", err, m.Notice, m.Over())
+	}
+
+	// a bad message stops it, leaving the rest for nobody
+	recv <- []byte{0xff}
+	recv <- encode(&pb.ServerMessage{Content: &pb.ServerMessage_CoinGot{CoinGot: &pb.CoinGot{}}})
+	if err := receive(); err == nil || errors.Is(err, ErrDisconnected) {
+		t.Fatalf("got %v, want a decode error", err)
+	}
+	if len(recv) != 1 {
+		t.Fatalf("%d messages left, want 1", len(recv))
+	}
+	<-recv
+
+	// what arrived before the connection was lost still counts
+	m = NewMatch()
+	recv <- encode(&pb.ServerMessage{Content: &pb.ServerMessage_GameEnd{GameEnd: &pb.GameEnd{}}})
+	disconnect <- true
+	if err := receive(); !errors.Is(err, ErrDisconnected) || !m.Over() {
+		t.Fatalf("got %v, over %v, want %v", err, m.Over(), ErrDisconnected)
+	}
+}
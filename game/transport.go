@@ -0,0 +1,38 @@
+package game
+
+import (
+	"context"
+
+	"github.com/kisunji/ebiten-poc/transport"
+	"nhooyr.io/websocket"
+)
+
+// wsConn adapts an nhooyr websocket to transport.Conn.
+type wsConn struct {
+	conn *websocket.Conn
+}
+
+func (c *wsConn) Read(ctx context.Context) ([]byte, error) {
+	_, buf, err := c.conn.Read(ctx)
+	if code := websocket.CloseStatus(err); code != -1 {
+		return nil, &transport.CloseError{Code: transport.StatusCode(code), Reason: err.Error()}
+	}
+	return buf, err
+}
+
+func (c *wsConn) Write(ctx context.Context, data []byte) error {
+	w, err := c.conn.Writer(ctx, websocket.MessageBinary)
+	if err != nil {
+		return err
+	}
+	w.Write(data)
+	return w.Close()
+}
+
+func (c *wsConn) Ping(ctx context.Context) error {
+	return c.conn.Ping(ctx)
+}
+
+func (c *wsConn) Close(code transport.StatusCode, reason string) error {
+	return c.conn.Close(websocket.StatusCode(code), reason)
+}
@@ -1,6 +1,6 @@
 module github.com/kisunji/ebiten-poc
 
-go 1.15
+go 1.21
 
 require (
 	github.com/golang/protobuf v1.4.1
@@ -8,5 +8,15 @@ require (
 	github.com/hajimehoshi/ebiten/v2 v2.0.1
 	golang.org/x/image v0.0.0-20200927104501-e162460cd6b5
 	google.golang.org/protobuf v1.25.0
+	gopkg.in/yaml.v3 v3.0.1
 	nhooyr.io/websocket v1.8.6
 )
+
+require (
+	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20200707082815-5321531c36a2 // indirect
+	github.com/klauspost/compress v1.10.3 // indirect
+	golang.org/x/exp v0.0.0-20190731235908-ec7cb31e5a56 // indirect
+	golang.org/x/mobile v0.0.0-20200801112145-973feb4309de // indirect
+	golang.org/x/sys v0.0.0-20201009025420-dfb3f7c4e634 // indirect
+	golang.org/x/text v0.3.2 // indirect
+)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -8,7 +9,15 @@ import (
 	"github.com/kisunji/ebiten-poc/game"
 )
 
+var serverAddr = flag.String("server", "", "server address, e.g. localhost:8080 or wss://ws.chriskim.dev:3000")
+
 func main() {
+	flag.Parse()
+	addr := *serverAddr
+	if addr == "" {
+		addr = game.QueryParam("server")
+	}
+
 	c := game.NewClient()
 
 	ebiten.SetRunnableOnUnfocused(true)
@@ -17,8 +26,9 @@ func main() {
 	ebiten.SetWindowTitle(common.GameTitle)
 
 	g := &game.Game{
-		Client: c,
-		Scene:  game.SceneStartMenu,
+		Client:     c,
+		Scene:      game.SceneStartMenu,
+		ServerAddr: addr,
 	}
 	if err := ebiten.RunGame(g); err != nil {
 		log.Fatal(err)
@@ -34,6 +34,8 @@ type ClientMessage struct {
 	//	*ClientMessage_Input
 	//	*ClientMessage_StartGame
 	//	*ClientMessage_WorldUpdate
+	//	*ClientMessage_SelectMap
+	//	*ClientMessage_SetCapacity
 	Content isClientMessage_Content `protobuf_oneof:"content"`
 }
 
@@ -97,6 +99,20 @@ func (x *ClientMessage) GetWorldUpdate() *WorldUpdate {
 	return nil
 }
 
+func (x *ClientMessage) GetSelectMap() *SelectMap {
+	if x, ok := x.GetContent().(*ClientMessage_SelectMap); ok {
+		return x.SelectMap
+	}
+	return nil
+}
+
+func (x *ClientMessage) GetSetCapacity() *SetCapacity {
+	if x, ok := x.GetContent().(*ClientMessage_SetCapacity); ok {
+		return x.SetCapacity
+	}
+	return nil
+}
+
 type isClientMessage_Content interface {
 	isClientMessage_Content()
 }
@@ -113,12 +129,24 @@ type ClientMessage_WorldUpdate struct {
 	WorldUpdate *WorldUpdate `protobuf:"bytes,3,opt,name=worldUpdate,proto3,oneof"`
 }
 
+type ClientMessage_SelectMap struct {
+	SelectMap *SelectMap `protobuf:"bytes,4,opt,name=selectMap,proto3,oneof"`
+}
+
+type ClientMessage_SetCapacity struct {
+	SetCapacity *SetCapacity `protobuf:"bytes,5,opt,name=setCapacity,proto3,oneof"`
+}
+
 func (*ClientMessage_Input) isClientMessage_Content() {}
 
 func (*ClientMessage_StartGame) isClientMessage_Content() {}
 
 func (*ClientMessage_WorldUpdate) isClientMessage_Content() {}
 
+func (*ClientMessage_SelectMap) isClientMessage_Content() {}
+
+func (*ClientMessage_SetCapacity) isClientMessage_Content() {}
+
 type Input struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -129,6 +157,8 @@ type Input struct {
 	LeftPressed   bool `protobuf:"varint,3,opt,name=LeftPressed,proto3" json:"LeftPressed,omitempty"`
 	RightPressed  bool `protobuf:"varint,4,opt,name=RightPressed,proto3" json:"RightPressed,omitempty"`
 	ActionPressed bool `protobuf:"varint,5,opt,name=ActionPressed,proto3" json:"ActionPressed,omitempty"`
+	// Held to run faster while stamina lasts.
+	SprintPressed bool `protobuf:"varint,6,opt,name=SprintPressed,proto3" json:"SprintPressed,omitempty"`
 }
 
 func (x *Input) Reset() {
@@ -198,6 +228,13 @@ func (x *Input) GetActionPressed() bool {
 	return false
 }
 
+func (x *Input) GetSprintPressed() bool {
+	if x != nil {
+		return x.SprintPressed
+	}
+	return false
+}
+
 type StartGame struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -274,6 +311,111 @@ func (*WorldUpdate) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{3}
 }
 
+// Sent by the host to pick the map for the next match.
+type SelectMap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *SelectMap) Reset() {
+	*x = SelectMap{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SelectMap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelectMap) ProtoMessage() {}
+
+func (x *SelectMap) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelectMap.ProtoReflect.Descriptor instead.
+func (*SelectMap) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SelectMap) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Sent by the host to resize the room before a match.
+type SetCapacity struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Players int32 `protobuf:"varint,1,opt,name=players,proto3" json:"players,omitempty"`
+	// Characters in a match, players included.
+	Crowd int32 `protobuf:"varint,2,opt,name=crowd,proto3" json:"crowd,omitempty"`
+}
+
+func (x *SetCapacity) Reset() {
+	*x = SetCapacity{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetCapacity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetCapacity) ProtoMessage() {}
+
+func (x *SetCapacity) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetCapacity.ProtoReflect.Descriptor instead.
+func (*SetCapacity) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SetCapacity) GetPlayers() int32 {
+	if x != nil {
+		return x.Players
+	}
+	return 0
+}
+
+func (x *SetCapacity) GetCrowd() int32 {
+	if x != nil {
+		return x.Crowd
+	}
+	return 0
+}
+
 type ServerMessage struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -292,13 +434,19 @@ type ServerMessage struct {
 	//	*ServerMessage_CoinGot
 	//	*ServerMessage_GameEnd
 	//	*ServerMessage_TimeSync
+	//	*ServerMessage_ServerNotice
+	//	*ServerMessage_PlayerKilled
+	//	*ServerMessage_PlayerPenalized
+	//	*ServerMessage_NewPickup
+	//	*ServerMessage_PickupGot
+	//	*ServerMessage_CoinGone
 	Content isServerMessage_Content `protobuf_oneof:"content"`
 }
 
 func (x *ServerMessage) Reset() {
 	*x = ServerMessage{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[4]
+		mi := &file_message_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -311,7 +459,7 @@ func (x *ServerMessage) String() string {
 func (*ServerMessage) ProtoMessage() {}
 
 func (x *ServerMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[4]
+	mi := &file_message_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -324,7 +472,7 @@ func (x *ServerMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerMessage.ProtoReflect.Descriptor instead.
 func (*ServerMessage) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{4}
+	return file_message_proto_rawDescGZIP(), []int{6}
 }
 
 func (m *ServerMessage) GetContent() isServerMessage_Content {
@@ -418,6 +566,48 @@ func (x *ServerMessage) GetTimeSync() *TimeSync {
 	return nil
 }
 
+func (x *ServerMessage) GetServerNotice() *ServerNotice {
+	if x, ok := x.GetContent().(*ServerMessage_ServerNotice); ok {
+		return x.ServerNotice
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetPlayerKilled() *PlayerKilled {
+	if x, ok := x.GetContent().(*ServerMessage_PlayerKilled); ok {
+		return x.PlayerKilled
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetPlayerPenalized() *PlayerPenalized {
+	if x, ok := x.GetContent().(*ServerMessage_PlayerPenalized); ok {
+		return x.PlayerPenalized
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetNewPickup() *NewPickup {
+	if x, ok := x.GetContent().(*ServerMessage_NewPickup); ok {
+		return x.NewPickup
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetPickupGot() *PickupGot {
+	if x, ok := x.GetContent().(*ServerMessage_PickupGot); ok {
+		return x.PickupGot
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetCoinGone() *CoinGone {
+	if x, ok := x.GetContent().(*ServerMessage_CoinGone); ok {
+		return x.CoinGone
+	}
+	return nil
+}
+
 type isServerMessage_Content interface {
 	isServerMessage_Content()
 }
@@ -470,6 +660,30 @@ type ServerMessage_TimeSync struct {
 	TimeSync *TimeSync `protobuf:"bytes,12,opt,name=timeSync,proto3,oneof"`
 }
 
+type ServerMessage_ServerNotice struct {
+	ServerNotice *ServerNotice `protobuf:"bytes,13,opt,name=serverNotice,proto3,oneof"`
+}
+
+type ServerMessage_PlayerKilled struct {
+	PlayerKilled *PlayerKilled `protobuf:"bytes,14,opt,name=playerKilled,proto3,oneof"`
+}
+
+type ServerMessage_PlayerPenalized struct {
+	PlayerPenalized *PlayerPenalized `protobuf:"bytes,15,opt,name=playerPenalized,proto3,oneof"`
+}
+
+type ServerMessage_NewPickup struct {
+	NewPickup *NewPickup `protobuf:"bytes,16,opt,name=newPickup,proto3,oneof"`
+}
+
+type ServerMessage_PickupGot struct {
+	PickupGot *PickupGot `protobuf:"bytes,17,opt,name=pickupGot,proto3,oneof"`
+}
+
+type ServerMessage_CoinGone struct {
+	CoinGone *CoinGone `protobuf:"bytes,18,opt,name=coinGone,proto3,oneof"`
+}
+
 func (*ServerMessage_ConnectResponse) isServerMessage_Content() {}
 
 func (*ServerMessage_ConnectError) isServerMessage_Content() {}
@@ -494,6 +708,18 @@ func (*ServerMessage_GameEnd) isServerMessage_Content() {}
 
 func (*ServerMessage_TimeSync) isServerMessage_Content() {}
 
+func (*ServerMessage_ServerNotice) isServerMessage_Content() {}
+
+func (*ServerMessage_PlayerKilled) isServerMessage_Content() {}
+
+func (*ServerMessage_PlayerPenalized) isServerMessage_Content() {}
+
+func (*ServerMessage_NewPickup) isServerMessage_Content() {}
+
+func (*ServerMessage_PickupGot) isServerMessage_Content() {}
+
+func (*ServerMessage_CoinGone) isServerMessage_Content() {}
+
 type ConnectResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -506,7 +732,7 @@ type ConnectResponse struct {
 func (x *ConnectResponse) Reset() {
 	*x = ConnectResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[5]
+		mi := &file_message_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -519,7 +745,7 @@ func (x *ConnectResponse) String() string {
 func (*ConnectResponse) ProtoMessage() {}
 
 func (x *ConnectResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[5]
+	mi := &file_message_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -532,7 +758,7 @@ func (x *ConnectResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConnectResponse.ProtoReflect.Descriptor instead.
 func (*ConnectResponse) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{5}
+	return file_message_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ConnectResponse) GetClientSlot() int32 {
@@ -560,7 +786,7 @@ type ConnectError struct {
 func (x *ConnectError) Reset() {
 	*x = ConnectError{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[6]
+		mi := &file_message_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -573,7 +799,7 @@ func (x *ConnectError) String() string {
 func (*ConnectError) ProtoMessage() {}
 
 func (x *ConnectError) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[6]
+	mi := &file_message_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -586,7 +812,7 @@ func (x *ConnectError) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConnectError.ProtoReflect.Descriptor instead.
 func (*ConnectError) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{6}
+	return file_message_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ConnectError) GetMessage() string {
@@ -607,7 +833,7 @@ type PlayerDisconnected struct {
 func (x *PlayerDisconnected) Reset() {
 	*x = PlayerDisconnected{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[7]
+		mi := &file_message_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -620,7 +846,7 @@ func (x *PlayerDisconnected) String() string {
 func (*PlayerDisconnected) ProtoMessage() {}
 
 func (x *PlayerDisconnected) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[7]
+	mi := &file_message_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -633,7 +859,7 @@ func (x *PlayerDisconnected) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerDisconnected.ProtoReflect.Descriptor instead.
 func (*PlayerDisconnected) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{7}
+	return file_message_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *PlayerDisconnected) GetId() int32 {
@@ -654,7 +880,7 @@ type NewHost struct {
 func (x *NewHost) Reset() {
 	*x = NewHost{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[8]
+		mi := &file_message_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -667,7 +893,7 @@ func (x *NewHost) String() string {
 func (*NewHost) ProtoMessage() {}
 
 func (x *NewHost) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[8]
+	mi := &file_message_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -680,7 +906,7 @@ func (x *NewHost) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NewHost.ProtoReflect.Descriptor instead.
 func (*NewHost) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{8}
+	return file_message_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *NewHost) GetId() int32 {
@@ -697,12 +923,19 @@ type UpdateLobby struct {
 
 	ConnectedSlots []bool `protobuf:"varint,1,rep,packed,name=connectedSlots,proto3" json:"connectedSlots,omitempty"`
 	HostSlot       int32  `protobuf:"varint,2,opt,name=hostSlot,proto3" json:"hostSlot,omitempty"`
+	// Map the next match is played on.
+	Map string `protobuf:"bytes,3,opt,name=map,proto3" json:"map,omitempty"`
+	// Maps the host can pick from.
+	Maps []string `protobuf:"bytes,4,rep,name=maps,proto3" json:"maps,omitempty"`
+	// Characters in a match, players included. The room has a player slot
+	// for each of connectedSlots.
+	Crowd int32 `protobuf:"varint,5,opt,name=crowd,proto3" json:"crowd,omitempty"`
 }
 
 func (x *UpdateLobby) Reset() {
 	*x = UpdateLobby{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[9]
+		mi := &file_message_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -715,7 +948,7 @@ func (x *UpdateLobby) String() string {
 func (*UpdateLobby) ProtoMessage() {}
 
 func (x *UpdateLobby) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[9]
+	mi := &file_message_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -728,7 +961,7 @@ func (x *UpdateLobby) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateLobby.ProtoReflect.Descriptor instead.
 func (*UpdateLobby) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{9}
+	return file_message_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *UpdateLobby) GetConnectedSlots() []bool {
@@ -745,16 +978,43 @@ func (x *UpdateLobby) GetHostSlot() int32 {
 	return 0
 }
 
+func (x *UpdateLobby) GetMap() string {
+	if x != nil {
+		return x.Map
+	}
+	return ""
+}
+
+func (x *UpdateLobby) GetMaps() []string {
+	if x != nil {
+		return x.Maps
+	}
+	return nil
+}
+
+func (x *UpdateLobby) GetCrowd() int32 {
+	if x != nil {
+		return x.Crowd
+	}
+	return 0
+}
+
 type GameStart struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Map string `protobuf:"bytes,1,opt,name=map,proto3" json:"map,omitempty"`
+	// Sizes of the match: player slots, and character slots for the crowd
+	// followed by a decoy for each player.
+	Players int32 `protobuf:"varint,2,opt,name=players,proto3" json:"players,omitempty"`
+	Crowd   int32 `protobuf:"varint,3,opt,name=crowd,proto3" json:"crowd,omitempty"`
 }
 
 func (x *GameStart) Reset() {
 	*x = GameStart{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[10]
+		mi := &file_message_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -767,7 +1027,7 @@ func (x *GameStart) String() string {
 func (*GameStart) ProtoMessage() {}
 
 func (x *GameStart) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[10]
+	mi := &file_message_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -780,7 +1040,28 @@ func (x *GameStart) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GameStart.ProtoReflect.Descriptor instead.
 func (*GameStart) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{10}
+	return file_message_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GameStart) GetMap() string {
+	if x != nil {
+		return x.Map
+	}
+	return ""
+}
+
+func (x *GameStart) GetPlayers() int32 {
+	if x != nil {
+		return x.Players
+	}
+	return 0
+}
+
+func (x *GameStart) GetCrowd() int32 {
+	if x != nil {
+		return x.Crowd
+	}
+	return 0
 }
 
 type UpdateEntity struct {
@@ -788,22 +1069,32 @@ type UpdateEntity struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Index       int32   `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
-	Fx          int32   `protobuf:"varint,2,opt,name=Fx,proto3" json:"Fx,omitempty"`
-	Fy          int32   `protobuf:"varint,3,opt,name=Fy,proto3" json:"Fy,omitempty"`
-	Vx          int32   `protobuf:"varint,4,opt,name=vx,proto3" json:"vx,omitempty"`
-	Vy          int32   `protobuf:"varint,5,opt,name=vy,proto3" json:"vy,omitempty"`
-	Px          float64 `protobuf:"fixed64,6,opt,name=Px,proto3" json:"Px,omitempty"`
-	Py          float64 `protobuf:"fixed64,7,opt,name=Py,proto3" json:"Py,omitempty"`
-	Speed       int32   `protobuf:"varint,8,opt,name=speed,proto3" json:"speed,omitempty"`
-	AttackFrame int32   `protobuf:"varint,9,opt,name=attackFrame,proto3" json:"attackFrame,omitempty"`
-	IsDead      bool    `protobuf:"varint,10,opt,name=isDead,proto3" json:"isDead,omitempty"`
+	Index int32   `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Fx    int32   `protobuf:"varint,2,opt,name=Fx,proto3" json:"Fx,omitempty"`
+	Fy    int32   `protobuf:"varint,3,opt,name=Fy,proto3" json:"Fy,omitempty"`
+	Vx    int32   `protobuf:"varint,4,opt,name=vx,proto3" json:"vx,omitempty"`
+	Vy    int32   `protobuf:"varint,5,opt,name=vy,proto3" json:"vy,omitempty"`
+	Px    float64 `protobuf:"fixed64,6,opt,name=Px,proto3" json:"Px,omitempty"`
+	Py    float64 `protobuf:"fixed64,7,opt,name=Py,proto3" json:"Py,omitempty"`
+	Speed int32   `protobuf:"varint,8,opt,name=speed,proto3" json:"speed,omitempty"`
+	// Ticks left in attackPhase.
+	AttackFrame int32 `protobuf:"varint,9,opt,name=attackFrame,proto3" json:"attackFrame,omitempty"`
+	IsDead      bool  `protobuf:"varint,10,opt,name=isDead,proto3" json:"isDead,omitempty"`
+	// 0 idle, 1 wind-up, 2 active, 3 recovery, 4 cooldown.
+	AttackPhase int32 `protobuf:"varint,11,opt,name=attackPhase,proto3" json:"attackPhase,omitempty"`
+	// 0 to MaxStamina; spent while sprinting.
+	Stamina int32 `protobuf:"varint,12,opt,name=stamina,proto3" json:"stamina,omitempty"`
+	// Out of sight of the receiving player, or gone; the other fields are
+	// not set.
+	Hidden bool `protobuf:"varint,13,opt,name=hidden,proto3" json:"hidden,omitempty"`
+	// Extra pixels per tick from a speed pickup.
+	Boost int32 `protobuf:"varint,14,opt,name=boost,proto3" json:"boost,omitempty"`
 }
 
 func (x *UpdateEntity) Reset() {
 	*x = UpdateEntity{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[11]
+		mi := &file_message_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -816,7 +1107,7 @@ func (x *UpdateEntity) String() string {
 func (*UpdateEntity) ProtoMessage() {}
 
 func (x *UpdateEntity) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[11]
+	mi := &file_message_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -829,7 +1120,7 @@ func (x *UpdateEntity) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateEntity.ProtoReflect.Descriptor instead.
 func (*UpdateEntity) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{11}
+	return file_message_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *UpdateEntity) GetIndex() int32 {
@@ -902,6 +1193,34 @@ func (x *UpdateEntity) GetIsDead() bool {
 	return false
 }
 
+func (x *UpdateEntity) GetAttackPhase() int32 {
+	if x != nil {
+		return x.AttackPhase
+	}
+	return 0
+}
+
+func (x *UpdateEntity) GetStamina() int32 {
+	if x != nil {
+		return x.Stamina
+	}
+	return 0
+}
+
+func (x *UpdateEntity) GetHidden() bool {
+	if x != nil {
+		return x.Hidden
+	}
+	return false
+}
+
+func (x *UpdateEntity) GetBoost() int32 {
+	if x != nil {
+		return x.Boost
+	}
+	return 0
+}
+
 type UpdateEntities struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -913,7 +1232,7 @@ type UpdateEntities struct {
 func (x *UpdateEntities) Reset() {
 	*x = UpdateEntities{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[12]
+		mi := &file_message_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -926,7 +1245,7 @@ func (x *UpdateEntities) String() string {
 func (*UpdateEntities) ProtoMessage() {}
 
 func (x *UpdateEntities) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[12]
+	mi := &file_message_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -939,7 +1258,7 @@ func (x *UpdateEntities) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateEntities.ProtoReflect.Descriptor instead.
 func (*UpdateEntities) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{12}
+	return file_message_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *UpdateEntities) GetUpdateEntity() []*UpdateEntity {
@@ -949,6 +1268,8 @@ func (x *UpdateEntities) GetUpdateEntity() []*UpdateEntity {
 	return nil
 }
 
+// A coin appeared. It takes the place of any collected or expired coin
+// with the same index.
 type NewCoin struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -963,7 +1284,7 @@ type NewCoin struct {
 func (x *NewCoin) Reset() {
 	*x = NewCoin{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[13]
+		mi := &file_message_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -976,7 +1297,7 @@ func (x *NewCoin) String() string {
 func (*NewCoin) ProtoMessage() {}
 
 func (x *NewCoin) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[13]
+	mi := &file_message_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -989,7 +1310,7 @@ func (x *NewCoin) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NewCoin.ProtoReflect.Descriptor instead.
 func (*NewCoin) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{13}
+	return file_message_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *NewCoin) GetIndex() int32 {
@@ -1031,7 +1352,7 @@ type CoinGot struct {
 func (x *CoinGot) Reset() {
 	*x = CoinGot{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[14]
+		mi := &file_message_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1044,7 +1365,7 @@ func (x *CoinGot) String() string {
 func (*CoinGot) ProtoMessage() {}
 
 func (x *CoinGot) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[14]
+	mi := &file_message_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1057,7 +1378,7 @@ func (x *CoinGot) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CoinGot.ProtoReflect.Descriptor instead.
 func (*CoinGot) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{14}
+	return file_message_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *CoinGot) GetIndex() int32 {
@@ -1067,32 +1388,588 @@ func (x *CoinGot) GetIndex() int32 {
 	return 0
 }
 
+// A coin expired without anyone collecting it.
+type CoinGone struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+}
+
+func (x *CoinGone) Reset() {
+	*x = CoinGone{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CoinGone) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoinGone) ProtoMessage() {}
+
+func (x *CoinGone) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoinGone.ProtoReflect.Descriptor instead.
+func (*CoinGone) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CoinGone) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+// A power-up appeared. It takes the place of any collected pickup with
+// the same index.
+type NewPickup struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// One of common.PickupKind.
+	Kind int32   `protobuf:"varint,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Px   float64 `protobuf:"fixed64,3,opt,name=Px,proto3" json:"Px,omitempty"`
+	Py   float64 `protobuf:"fixed64,4,opt,name=Py,proto3" json:"Py,omitempty"`
+}
+
+func (x *NewPickup) Reset() {
+	*x = NewPickup{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewPickup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewPickup) ProtoMessage() {}
+
+func (x *NewPickup) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewPickup.ProtoReflect.Descriptor instead.
+func (*NewPickup) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *NewPickup) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *NewPickup) GetKind() int32 {
+	if x != nil {
+		return x.Kind
+	}
+	return 0
+}
+
+func (x *NewPickup) GetPx() float64 {
+	if x != nil {
+		return x.Px
+	}
+	return 0
+}
+
+func (x *NewPickup) GetPy() float64 {
+	if x != nil {
+		return x.Py
+	}
+	return 0
+}
+
+// A power-up was collected.
+type PickupGot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Set only in the message to the player who collected it.
+	Yours bool `protobuf:"varint,2,opt,name=yours,proto3" json:"yours,omitempty"`
+	// How long its effect lasts, or 0 if it is over at once.
+	DurationMillis int32 `protobuf:"varint,3,opt,name=durationMillis,proto3" json:"durationMillis,omitempty"`
+}
+
+func (x *PickupGot) Reset() {
+	*x = PickupGot{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PickupGot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PickupGot) ProtoMessage() {}
+
+func (x *PickupGot) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PickupGot.ProtoReflect.Descriptor instead.
+func (*PickupGot) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PickupGot) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *PickupGot) GetYours() bool {
+	if x != nil {
+		return x.Yours
+	}
+	return false
+}
+
+func (x *PickupGot) GetDurationMillis() int32 {
+	if x != nil {
+		return x.DurationMillis
+	}
+	return 0
+}
+
 type GameEnd struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Survivor int32   `protobuf:"varint,1,opt,name=survivor,proto3" json:"survivor,omitempty"`
-	Score    []int32 `protobuf:"varint,2,rep,packed,name=score,proto3" json:"score,omitempty"`
+	Survivor int32   `protobuf:"varint,1,opt,name=survivor,proto3" json:"survivor,omitempty"`
+	Score    []int32 `protobuf:"varint,2,rep,packed,name=score,proto3" json:"score,omitempty"`
+	// Players each player killed, by slot.
+	Kills []int32 `protobuf:"varint,3,rep,packed,name=kills,proto3" json:"kills,omitempty"`
+}
+
+func (x *GameEnd) Reset() {
+	*x = GameEnd{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GameEnd) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameEnd) ProtoMessage() {}
+
+func (x *GameEnd) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameEnd.ProtoReflect.Descriptor instead.
+func (*GameEnd) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GameEnd) GetSurvivor() int32 {
+	if x != nil {
+		return x.Survivor
+	}
+	return 0
+}
+
+func (x *GameEnd) GetScore() []int32 {
+	if x != nil {
+		return x.Score
+	}
+	return nil
+}
+
+func (x *GameEnd) GetKills() []int32 {
+	if x != nil {
+		return x.Kills
+	}
+	return nil
+}
+
+type TimeSync struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StartTime    int64         `protobuf:"varint,1,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	Duration     int32         `protobuf:"varint,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	AttackTiming *AttackTiming `protobuf:"bytes,3,opt,name=attackTiming,proto3" json:"attackTiming,omitempty"`
+	// How far players can see, in pixels. Zero means no limit.
+	Vision float64 `protobuf:"fixed64,4,opt,name=vision,proto3" json:"vision,omitempty"`
+}
+
+func (x *TimeSync) Reset() {
+	*x = TimeSync{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TimeSync) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeSync) ProtoMessage() {}
+
+func (x *TimeSync) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeSync.ProtoReflect.Descriptor instead.
+func (*TimeSync) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *TimeSync) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *TimeSync) GetDuration() int32 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *TimeSync) GetAttackTiming() *AttackTiming {
+	if x != nil {
+		return x.AttackTiming
+	}
+	return nil
+}
+
+func (x *TimeSync) GetVision() float64 {
+	if x != nil {
+		return x.Vision
+	}
+	return 0
+}
+
+// Length of each attack phase, in ticks.
+type AttackTiming struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	WindUp   int32 `protobuf:"varint,1,opt,name=windUp,proto3" json:"windUp,omitempty"`
+	Active   int32 `protobuf:"varint,2,opt,name=active,proto3" json:"active,omitempty"`
+	Recovery int32 `protobuf:"varint,3,opt,name=recovery,proto3" json:"recovery,omitempty"`
+	Cooldown int32 `protobuf:"varint,4,opt,name=cooldown,proto3" json:"cooldown,omitempty"`
+}
+
+func (x *AttackTiming) Reset() {
+	*x = AttackTiming{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AttackTiming) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttackTiming) ProtoMessage() {}
+
+func (x *AttackTiming) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttackTiming.ProtoReflect.Descriptor instead.
+func (*AttackTiming) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *AttackTiming) GetWindUp() int32 {
+	if x != nil {
+		return x.WindUp
+	}
+	return 0
+}
+
+func (x *AttackTiming) GetActive() int32 {
+	if x != nil {
+		return x.Active
+	}
+	return 0
+}
+
+func (x *AttackTiming) GetRecovery() int32 {
+	if x != nil {
+		return x.Recovery
+	}
+	return 0
+}
+
+func (x *AttackTiming) GetCooldown() int32 {
+	if x != nil {
+		return x.Cooldown
+	}
+	return 0
+}
+
+// A player was killed by another.
+type PlayerKilled struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Killer int32 `protobuf:"varint,1,opt,name=killer,proto3" json:"killer,omitempty"`
+	Victim int32 `protobuf:"varint,2,opt,name=victim,proto3" json:"victim,omitempty"`
+	// World update the kill happened on.
+	Tick int64 `protobuf:"varint,3,opt,name=tick,proto3" json:"tick,omitempty"`
+	// Where the victim fell. Zero for players who could not see the victim
+	// when the mode limits vision.
+	Px float64 `protobuf:"fixed64,4,opt,name=Px,proto3" json:"Px,omitempty"`
+	Py float64 `protobuf:"fixed64,5,opt,name=Py,proto3" json:"Py,omitempty"`
+}
+
+func (x *PlayerKilled) Reset() {
+	*x = PlayerKilled{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlayerKilled) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerKilled) ProtoMessage() {}
+
+func (x *PlayerKilled) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerKilled.ProtoReflect.Descriptor instead.
+func (*PlayerKilled) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *PlayerKilled) GetKiller() int32 {
+	if x != nil {
+		return x.Killer
+	}
+	return 0
+}
+
+func (x *PlayerKilled) GetVictim() int32 {
+	if x != nil {
+		return x.Victim
+	}
+	return 0
+}
+
+func (x *PlayerKilled) GetTick() int64 {
+	if x != nil {
+		return x.Tick
+	}
+	return 0
+}
+
+func (x *PlayerKilled) GetPx() float64 {
+	if x != nil {
+		return x.Px
+	}
+	return 0
+}
+
+func (x *PlayerKilled) GetPy() float64 {
+	if x != nil {
+		return x.Py
+	}
+	return 0
+}
+
+// A player was penalized for killing an AI.
+type PlayerPenalized struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Coins taken off the player's score.
+	ScoreLoss int32 `protobuf:"varint,2,opt,name=scoreLoss,proto3" json:"scoreLoss,omitempty"`
+	// How long the player cannot move or attack.
+	StunMillis int32 `protobuf:"varint,3,opt,name=stunMillis,proto3" json:"stunMillis,omitempty"`
+	// How long the player is outlined.
+	RevealMillis int32 `protobuf:"varint,4,opt,name=revealMillis,proto3" json:"revealMillis,omitempty"`
+}
+
+func (x *PlayerPenalized) Reset() {
+	*x = PlayerPenalized{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlayerPenalized) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerPenalized) ProtoMessage() {}
+
+func (x *PlayerPenalized) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerPenalized.ProtoReflect.Descriptor instead.
+func (*PlayerPenalized) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *PlayerPenalized) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *PlayerPenalized) GetScoreLoss() int32 {
+	if x != nil {
+		return x.ScoreLoss
+	}
+	return 0
+}
+
+func (x *PlayerPenalized) GetStunMillis() int32 {
+	if x != nil {
+		return x.StunMillis
+	}
+	return 0
+}
+
+func (x *PlayerPenalized) GetRevealMillis() int32 {
+	if x != nil {
+		return x.RevealMillis
+	}
+	return 0
+}
+
+// Text from the server operator, shown to every player in the room.
+type ServerNotice struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// Seconds until the server stops, or 0.
+	Countdown int32 `protobuf:"varint,2,opt,name=countdown,proto3" json:"countdown,omitempty"`
 }
 
-func (x *GameEnd) Reset() {
-	*x = GameEnd{}
+func (x *ServerNotice) Reset() {
+	*x = ServerNotice{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[15]
+		mi := &file_message_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GameEnd) String() string {
+func (x *ServerNotice) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GameEnd) ProtoMessage() {}
+func (*ServerNotice) ProtoMessage() {}
 
-func (x *GameEnd) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[15]
+func (x *ServerNotice) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1103,51 +1980,141 @@ func (x *GameEnd) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GameEnd.ProtoReflect.Descriptor instead.
-func (*GameEnd) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use ServerNotice.ProtoReflect.Descriptor instead.
+func (*ServerNotice) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *GameEnd) GetSurvivor() int32 {
+func (x *ServerNotice) GetMessage() string {
 	if x != nil {
-		return x.Survivor
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ServerNotice) GetCountdown() int32 {
+	if x != nil {
+		return x.Countdown
 	}
 	return 0
 }
 
-func (x *GameEnd) GetScore() []int32 {
+// Broadcast over UDP by servers on the local network.
+type Announcement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version  string      `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Name     string      `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Port     int32       `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	Secure   bool        `protobuf:"varint,4,opt,name=secure,proto3" json:"secure,omitempty"`
+	MaxRooms int32       `protobuf:"varint,5,opt,name=maxRooms,proto3" json:"maxRooms,omitempty"`
+	Rooms    []*RoomInfo `protobuf:"bytes,6,rep,name=rooms,proto3" json:"rooms,omitempty"`
+}
+
+func (x *Announcement) Reset() {
+	*x = Announcement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Announcement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Announcement) ProtoMessage() {}
+
+func (x *Announcement) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Announcement.ProtoReflect.Descriptor instead.
+func (*Announcement) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Announcement) GetVersion() string {
 	if x != nil {
-		return x.Score
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Announcement) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Announcement) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Announcement) GetSecure() bool {
+	if x != nil {
+		return x.Secure
+	}
+	return false
+}
+
+func (x *Announcement) GetMaxRooms() int32 {
+	if x != nil {
+		return x.MaxRooms
+	}
+	return 0
+}
+
+func (x *Announcement) GetRooms() []*RoomInfo {
+	if x != nil {
+		return x.Rooms
 	}
 	return nil
 }
 
-type TimeSync struct {
+type RoomInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	StartTime int64 `protobuf:"varint,1,opt,name=startTime,proto3" json:"startTime,omitempty"`
-	Duration  int32 `protobuf:"varint,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	Id      int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Players int32 `protobuf:"varint,2,opt,name=players,proto3" json:"players,omitempty"`
+	Slots   int32 `protobuf:"varint,3,opt,name=slots,proto3" json:"slots,omitempty"`
+	Running bool  `protobuf:"varint,4,opt,name=running,proto3" json:"running,omitempty"`
 }
 
-func (x *TimeSync) Reset() {
-	*x = TimeSync{}
+func (x *RoomInfo) Reset() {
+	*x = RoomInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[16]
+		mi := &file_message_proto_msgTypes[27]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *TimeSync) String() string {
+func (x *RoomInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TimeSync) ProtoMessage() {}
+func (*RoomInfo) ProtoMessage() {}
 
-func (x *TimeSync) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[16]
+func (x *RoomInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[27]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1158,30 +2125,44 @@ func (x *TimeSync) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TimeSync.ProtoReflect.Descriptor instead.
-func (*TimeSync) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use RoomInfo.ProtoReflect.Descriptor instead.
+func (*RoomInfo) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *TimeSync) GetStartTime() int64 {
+func (x *RoomInfo) GetId() int32 {
 	if x != nil {
-		return x.StartTime
+		return x.Id
 	}
 	return 0
 }
 
-func (x *TimeSync) GetDuration() int32 {
+func (x *RoomInfo) GetPlayers() int32 {
 	if x != nil {
-		return x.Duration
+		return x.Players
 	}
 	return 0
 }
 
+func (x *RoomInfo) GetSlots() int32 {
+	if x != nil {
+		return x.Slots
+	}
+	return 0
+}
+
+func (x *RoomInfo) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
 var File_message_proto protoreflect.FileDescriptor
 
 var file_message_proto_rawDesc = []byte{
 	0x0a, 0x0d, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
-	0x02, 0x70, 0x62, 0x22, 0xa1, 0x01, 0x0a, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x65,
+	0x02, 0x70, 0x62, 0x22, 0x85, 0x02, 0x0a, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x65,
 	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x21, 0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x01,
 	0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x48,
 	0x00, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x2d, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72,
@@ -1190,116 +2171,230 @@ var file_message_proto_rawDesc = []byte{
 	0x61, 0x72, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x12, 0x33, 0x0a, 0x0b, 0x77, 0x6f, 0x72, 0x6c, 0x64,
 	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70,
 	0x62, 0x2e, 0x57, 0x6f, 0x72, 0x6c, 0x64, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52,
-	0x0b, 0x77, 0x6f, 0x72, 0x6c, 0x64, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x42, 0x09, 0x0a, 0x07,
-	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0xb3, 0x01, 0x0a, 0x05, 0x49, 0x6e, 0x70, 0x75,
-	0x74, 0x12, 0x1c, 0x0a, 0x09, 0x55, 0x70, 0x50, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x55, 0x70, 0x50, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x12,
-	0x20, 0x0a, 0x0b, 0x44, 0x6f, 0x77, 0x6e, 0x50, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x44, 0x6f, 0x77, 0x6e, 0x50, 0x72, 0x65, 0x73, 0x73, 0x65,
-	0x64, 0x12, 0x20, 0x0a, 0x0b, 0x4c, 0x65, 0x66, 0x74, 0x50, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x4c, 0x65, 0x66, 0x74, 0x50, 0x72, 0x65, 0x73,
-	0x73, 0x65, 0x64, 0x12, 0x22, 0x0a, 0x0c, 0x52, 0x69, 0x67, 0x68, 0x74, 0x50, 0x72, 0x65, 0x73,
-	0x73, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x52, 0x69, 0x67, 0x68, 0x74,
-	0x50, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x0d, 0x41, 0x63, 0x74, 0x69, 0x6f,
-	0x6e, 0x50, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d,
-	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x22, 0x0b, 0x0a,
-	0x09, 0x53, 0x74, 0x61, 0x72, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x22, 0x0d, 0x0a, 0x0b, 0x57, 0x6f,
-	0x72, 0x6c, 0x64, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x22, 0x87, 0x05, 0x0a, 0x0d, 0x53, 0x65,
-	0x72, 0x76, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x3f, 0x0a, 0x0f, 0x63,
-	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
-	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x0f, 0x63, 0x6f, 0x6e,
-	0x6e, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x0c,
-	0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x45,
-	0x72, 0x72, 0x6f, 0x72, 0x48, 0x00, 0x52, 0x0c, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x45,
-	0x72, 0x72, 0x6f, 0x72, 0x12, 0x33, 0x0a, 0x0b, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4c, 0x6f,
-	0x62, 0x62, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x55,
-	0x70, 0x64, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x62, 0x62, 0x79, 0x48, 0x00, 0x52, 0x0b, 0x75, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x62, 0x62, 0x79, 0x12, 0x2d, 0x0a, 0x09, 0x67, 0x61, 0x6d,
-	0x65, 0x53, 0x74, 0x61, 0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x70,
-	0x62, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x72, 0x74, 0x48, 0x00, 0x52, 0x09, 0x67,
-	0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x36, 0x0a, 0x0c, 0x75, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10,
-	0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79,
-	0x48, 0x00, 0x52, 0x0c, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79,
-	0x12, 0x48, 0x0a, 0x12, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x6e,
-	0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70,
-	0x62, 0x2e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
-	0x63, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x12, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x44, 0x69,
-	0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x27, 0x0a, 0x07, 0x6e, 0x65,
-	0x77, 0x48, 0x6f, 0x73, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62,
-	0x2e, 0x4e, 0x65, 0x77, 0x48, 0x6f, 0x73, 0x74, 0x48, 0x00, 0x52, 0x07, 0x6e, 0x65, 0x77, 0x48,
-	0x6f, 0x73, 0x74, 0x12, 0x3c, 0x0a, 0x0e, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74,
-	0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x70, 0x62,
-	0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x48,
-	0x00, 0x52, 0x0e, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65,
-	0x73, 0x12, 0x27, 0x0a, 0x07, 0x6e, 0x65, 0x77, 0x43, 0x6f, 0x69, 0x6e, 0x18, 0x09, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x4e, 0x65, 0x77, 0x43, 0x6f, 0x69, 0x6e, 0x48,
-	0x00, 0x52, 0x07, 0x6e, 0x65, 0x77, 0x43, 0x6f, 0x69, 0x6e, 0x12, 0x27, 0x0a, 0x07, 0x63, 0x6f,
-	0x69, 0x6e, 0x47, 0x6f, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62,
-	0x2e, 0x43, 0x6f, 0x69, 0x6e, 0x47, 0x6f, 0x74, 0x48, 0x00, 0x52, 0x07, 0x63, 0x6f, 0x69, 0x6e,
-	0x47, 0x6f, 0x74, 0x12, 0x27, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x45, 0x6e, 0x64, 0x18, 0x0b,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x6e,
-	0x64, 0x48, 0x00, 0x52, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x45, 0x6e, 0x64, 0x12, 0x2a, 0x0a, 0x08,
-	0x74, 0x69, 0x6d, 0x65, 0x53, 0x79, 0x6e, 0x63, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
-	0x2e, 0x70, 0x62, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x79, 0x6e, 0x63, 0x48, 0x00, 0x52, 0x08,
-	0x74, 0x69, 0x6d, 0x65, 0x53, 0x79, 0x6e, 0x63, 0x42, 0x09, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74,
-	0x65, 0x6e, 0x74, 0x22, 0x49, 0x0a, 0x0f, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
-	0x53, 0x6c, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x63, 0x6c, 0x69, 0x65,
-	0x6e, 0x74, 0x53, 0x6c, 0x6f, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x69, 0x73, 0x48, 0x6f, 0x73, 0x74,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x69, 0x73, 0x48, 0x6f, 0x73, 0x74, 0x22, 0x28,
-	0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x18,
-	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x24, 0x0a, 0x12, 0x50, 0x6c, 0x61, 0x79,
-	0x65, 0x72, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x0e,
-	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x22, 0x19,
-	0x0a, 0x07, 0x4e, 0x65, 0x77, 0x48, 0x6f, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x22, 0x51, 0x0a, 0x0b, 0x55, 0x70, 0x64,
-	0x61, 0x74, 0x65, 0x4c, 0x6f, 0x62, 0x62, 0x79, 0x12, 0x26, 0x0a, 0x0e, 0x63, 0x6f, 0x6e, 0x6e,
-	0x65, 0x63, 0x74, 0x65, 0x64, 0x53, 0x6c, 0x6f, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x08,
-	0x52, 0x0e, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x53, 0x6c, 0x6f, 0x74, 0x73,
-	0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x53, 0x6c, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x53, 0x6c, 0x6f, 0x74, 0x22, 0x0b, 0x0a, 0x09,
-	0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x72, 0x74, 0x22, 0xd4, 0x01, 0x0a, 0x0c, 0x55, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e,
-	0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78,
-	0x12, 0x0e, 0x0a, 0x02, 0x46, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x46, 0x78,
-	0x12, 0x0e, 0x0a, 0x02, 0x46, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x46, 0x79,
-	0x12, 0x0e, 0x0a, 0x02, 0x76, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x76, 0x78,
-	0x12, 0x0e, 0x0a, 0x02, 0x76, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x76, 0x79,
-	0x12, 0x0e, 0x0a, 0x02, 0x50, 0x78, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x02, 0x50, 0x78,
-	0x12, 0x0e, 0x0a, 0x02, 0x50, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x02, 0x50, 0x79,
-	0x12, 0x14, 0x0a, 0x05, 0x73, 0x70, 0x65, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x05, 0x73, 0x70, 0x65, 0x65, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x61, 0x74, 0x74, 0x61, 0x63, 0x6b,
-	0x46, 0x72, 0x61, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x61, 0x74, 0x74,
-	0x61, 0x63, 0x6b, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x69, 0x73, 0x44, 0x65,
-	0x61, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x69, 0x73, 0x44, 0x65, 0x61, 0x64,
-	0x22, 0x46, 0x0a, 0x0e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x69,
-	0x65, 0x73, 0x12, 0x34, 0x0a, 0x0c, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69,
-	0x74, 0x79, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x52, 0x0c, 0x75, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x22, 0x61, 0x0a, 0x07, 0x4e, 0x65, 0x77, 0x43,
-	0x6f, 0x69, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x50, 0x78, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x02, 0x50, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x50, 0x79, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x02, 0x50, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x46, 0x72, 0x61,
-	0x6d, 0x65, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b,
-	0x46, 0x72, 0x61, 0x6d, 0x65, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22, 0x1f, 0x0a, 0x07, 0x43,
-	0x6f, 0x69, 0x6e, 0x47, 0x6f, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x22, 0x3b, 0x0a, 0x07,
-	0x47, 0x61, 0x6d, 0x65, 0x45, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x75, 0x72, 0x76, 0x69,
-	0x76, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x73, 0x75, 0x72, 0x76, 0x69,
-	0x76, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20, 0x03,
-	0x28, 0x05, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x22, 0x44, 0x0a, 0x08, 0x54, 0x69, 0x6d,
-	0x65, 0x53, 0x79, 0x6e, 0x63, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69,
-	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54,
-	0x69, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42,
-	0x22, 0x5a, 0x20, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b, 0x69,
-	0x73, 0x75, 0x6e, 0x6a, 0x69, 0x2f, 0x65, 0x62, 0x69, 0x74, 0x65, 0x6e, 0x2d, 0x70, 0x6f, 0x63,
-	0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x0b, 0x77, 0x6f, 0x72, 0x6c, 0x64, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x2d, 0x0a, 0x09,
+	0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x4d, 0x61, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x4d, 0x61, 0x70, 0x48, 0x00,
+	0x52, 0x09, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x4d, 0x61, 0x70, 0x12, 0x33, 0x0a, 0x0b, 0x73,
+	0x65, 0x74, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74,
+	0x79, 0x48, 0x00, 0x52, 0x0b, 0x73, 0x65, 0x74, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79,
+	0x42, 0x09, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0xd9, 0x01, 0x0a, 0x05,
+	0x49, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x55, 0x70, 0x50, 0x72, 0x65, 0x73, 0x73,
+	0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x55, 0x70, 0x50, 0x72, 0x65, 0x73,
+	0x73, 0x65, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x44, 0x6f, 0x77, 0x6e, 0x50, 0x72, 0x65, 0x73, 0x73,
+	0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x44, 0x6f, 0x77, 0x6e, 0x50, 0x72,
+	0x65, 0x73, 0x73, 0x65, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x4c, 0x65, 0x66, 0x74, 0x50, 0x72, 0x65,
+	0x73, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x4c, 0x65, 0x66, 0x74,
+	0x50, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x12, 0x22, 0x0a, 0x0c, 0x52, 0x69, 0x67, 0x68, 0x74,
+	0x50, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x52,
+	0x69, 0x67, 0x68, 0x74, 0x50, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x0d, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0d, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x65, 0x73, 0x73, 0x65,
+	0x64, 0x12, 0x24, 0x0a, 0x0d, 0x53, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x50, 0x72, 0x65, 0x73, 0x73,
+	0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x53, 0x70, 0x72, 0x69, 0x6e, 0x74,
+	0x50, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x22, 0x0b, 0x0a, 0x09, 0x53, 0x74, 0x61, 0x72, 0x74,
+	0x47, 0x61, 0x6d, 0x65, 0x22, 0x0d, 0x0a, 0x0b, 0x57, 0x6f, 0x72, 0x6c, 0x64, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x22, 0x1f, 0x0a, 0x09, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x4d, 0x61, 0x70,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x22, 0x3d, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x43, 0x61, 0x70, 0x61, 0x63,
+	0x69, 0x74, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x12, 0x14, 0x0a,
+	0x05, 0x63, 0x72, 0x6f, 0x77, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x72,
+	0x6f, 0x77, 0x64, 0x22, 0xc2, 0x07, 0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x3f, 0x0a, 0x0f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70,
+	0x62, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x48, 0x00,
+	0x52, 0x0c, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x33,
+	0x0a, 0x0b, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x62, 0x62, 0x79, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4c,
+	0x6f, 0x62, 0x62, 0x79, 0x48, 0x00, 0x52, 0x0b, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4c, 0x6f,
+	0x62, 0x62, 0x79, 0x12, 0x2d, 0x0a, 0x09, 0x67, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x72, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x61, 0x6d, 0x65,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x48, 0x00, 0x52, 0x09, 0x67, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61,
+	0x72, 0x74, 0x12, 0x36, 0x0a, 0x0c, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69,
+	0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x48, 0x00, 0x52, 0x0c, 0x75, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x48, 0x0a, 0x12, 0x70, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x48, 0x00,
+	0x52, 0x12, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x12, 0x27, 0x0a, 0x07, 0x6e, 0x65, 0x77, 0x48, 0x6f, 0x73, 0x74, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x4e, 0x65, 0x77, 0x48, 0x6f,
+	0x73, 0x74, 0x48, 0x00, 0x52, 0x07, 0x6e, 0x65, 0x77, 0x48, 0x6f, 0x73, 0x74, 0x12, 0x3c, 0x0a,
+	0x0e, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x48, 0x00, 0x52, 0x0e, 0x75, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x07, 0x6e,
+	0x65, 0x77, 0x43, 0x6f, 0x69, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70,
+	0x62, 0x2e, 0x4e, 0x65, 0x77, 0x43, 0x6f, 0x69, 0x6e, 0x48, 0x00, 0x52, 0x07, 0x6e, 0x65, 0x77,
+	0x43, 0x6f, 0x69, 0x6e, 0x12, 0x27, 0x0a, 0x07, 0x63, 0x6f, 0x69, 0x6e, 0x47, 0x6f, 0x74, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x69, 0x6e, 0x47,
+	0x6f, 0x74, 0x48, 0x00, 0x52, 0x07, 0x63, 0x6f, 0x69, 0x6e, 0x47, 0x6f, 0x74, 0x12, 0x27, 0x0a,
+	0x07, 0x67, 0x61, 0x6d, 0x65, 0x45, 0x6e, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b,
+	0x2e, 0x70, 0x62, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x6e, 0x64, 0x48, 0x00, 0x52, 0x07, 0x67,
+	0x61, 0x6d, 0x65, 0x45, 0x6e, 0x64, 0x12, 0x2a, 0x0a, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x53, 0x79,
+	0x6e, 0x63, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x53, 0x79, 0x6e, 0x63, 0x48, 0x00, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x53, 0x79,
+	0x6e, 0x63, 0x12, 0x36, 0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4e, 0x6f, 0x74, 0x69,
+	0x63, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x4e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x4e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x12, 0x36, 0x0a, 0x0c, 0x70, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x4b, 0x69, 0x6c, 0x6c, 0x65, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x4b, 0x69, 0x6c, 0x6c,
+	0x65, 0x64, 0x48, 0x00, 0x52, 0x0c, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x4b, 0x69, 0x6c, 0x6c,
+	0x65, 0x64, 0x12, 0x3f, 0x0a, 0x0f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x50, 0x65, 0x6e, 0x61,
+	0x6c, 0x69, 0x7a, 0x65, 0x64, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x62,
+	0x2e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x50, 0x65, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64,
+	0x48, 0x00, 0x52, 0x0f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x50, 0x65, 0x6e, 0x61, 0x6c, 0x69,
+	0x7a, 0x65, 0x64, 0x12, 0x2d, 0x0a, 0x09, 0x6e, 0x65, 0x77, 0x50, 0x69, 0x63, 0x6b, 0x75, 0x70,
+	0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x4e, 0x65, 0x77, 0x50,
+	0x69, 0x63, 0x6b, 0x75, 0x70, 0x48, 0x00, 0x52, 0x09, 0x6e, 0x65, 0x77, 0x50, 0x69, 0x63, 0x6b,
+	0x75, 0x70, 0x12, 0x2d, 0x0a, 0x09, 0x70, 0x69, 0x63, 0x6b, 0x75, 0x70, 0x47, 0x6f, 0x74, 0x18,
+	0x11, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x69, 0x63, 0x6b, 0x75,
+	0x70, 0x47, 0x6f, 0x74, 0x48, 0x00, 0x52, 0x09, 0x70, 0x69, 0x63, 0x6b, 0x75, 0x70, 0x47, 0x6f,
+	0x74, 0x12, 0x2a, 0x0a, 0x08, 0x63, 0x6f, 0x69, 0x6e, 0x47, 0x6f, 0x6e, 0x65, 0x18, 0x12, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x69, 0x6e, 0x47, 0x6f, 0x6e,
+	0x65, 0x48, 0x00, 0x52, 0x08, 0x63, 0x6f, 0x69, 0x6e, 0x47, 0x6f, 0x6e, 0x65, 0x42, 0x09, 0x0a,
+	0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x49, 0x0a, 0x0f, 0x43, 0x6f, 0x6e, 0x6e,
+	0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x63,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x6c, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0a, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x6c, 0x6f, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x69,
+	0x73, 0x48, 0x6f, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x69, 0x73, 0x48,
+	0x6f, 0x73, 0x74, 0x22, 0x28, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x45, 0x72,
+	0x72, 0x6f, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x24, 0x0a,
+	0x12, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x65, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x02, 0x69, 0x64, 0x22, 0x19, 0x0a, 0x07, 0x4e, 0x65, 0x77, 0x48, 0x6f, 0x73, 0x74, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x22, 0x8d,
+	0x01, 0x0a, 0x0b, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x62, 0x62, 0x79, 0x12, 0x26,
+	0x0a, 0x0e, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x53, 0x6c, 0x6f, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x08, 0x52, 0x0e, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x53, 0x6c, 0x6f, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x53, 0x6c,
+	0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x53, 0x6c,
+	0x6f, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x61, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6d, 0x61, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x61, 0x70, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x04, 0x6d, 0x61, 0x70, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x72, 0x6f, 0x77,
+	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x72, 0x6f, 0x77, 0x64, 0x22, 0x4d,
+	0x0a, 0x09, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6d,
+	0x61, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6d, 0x61, 0x70, 0x12, 0x18, 0x0a,
+	0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07,
+	0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x72, 0x6f, 0x77, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x72, 0x6f, 0x77, 0x64, 0x22, 0xbe, 0x02,
+	0x0a, 0x0c, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x46, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x02, 0x46, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x46, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x02, 0x46, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x76, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x02, 0x76, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x76, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x02, 0x76, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x50, 0x78, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x02, 0x50, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x50, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x02, 0x50, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x70, 0x65, 0x65, 0x64, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x70, 0x65, 0x65, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x61, 0x74,
+	0x74, 0x61, 0x63, 0x6b, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0b, 0x61, 0x74, 0x74, 0x61, 0x63, 0x6b, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x69, 0x73, 0x44, 0x65, 0x61, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x69, 0x73,
+	0x44, 0x65, 0x61, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x61, 0x74, 0x74, 0x61, 0x63, 0x6b, 0x50, 0x68,
+	0x61, 0x73, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x61, 0x74, 0x74, 0x61, 0x63,
+	0x6b, 0x50, 0x68, 0x61, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x74, 0x61, 0x6d, 0x69, 0x6e,
+	0x61, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x73, 0x74, 0x61, 0x6d, 0x69, 0x6e, 0x61,
+	0x12, 0x16, 0x0a, 0x06, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x6f, 0x6f, 0x73,
+	0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x62, 0x6f, 0x6f, 0x73, 0x74, 0x22, 0x46,
+	0x0a, 0x0e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73,
+	0x12, 0x34, 0x0a, 0x0c, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x52, 0x0c, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x22, 0x61, 0x0a, 0x07, 0x4e, 0x65, 0x77, 0x43, 0x6f, 0x69,
+	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x50, 0x78, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x02, 0x50, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x50, 0x79, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x02, 0x50, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x46, 0x72, 0x61, 0x6d, 0x65,
+	0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x46, 0x72,
+	0x61, 0x6d, 0x65, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22, 0x1f, 0x0a, 0x07, 0x43, 0x6f, 0x69,
+	0x6e, 0x47, 0x6f, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x22, 0x20, 0x0a, 0x08, 0x43, 0x6f,
+	0x69, 0x6e, 0x47, 0x6f, 0x6e, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x22, 0x55, 0x0a, 0x09,
+	0x4e, 0x65, 0x77, 0x50, 0x69, 0x63, 0x6b, 0x75, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12,
+	0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6b,
+	0x69, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x50, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x02, 0x50, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x50, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x02, 0x50, 0x79, 0x22, 0x5f, 0x0a, 0x09, 0x50, 0x69, 0x63, 0x6b, 0x75, 0x70, 0x47, 0x6f, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x14, 0x0a, 0x05, 0x79, 0x6f, 0x75, 0x72, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x79, 0x6f, 0x75, 0x72, 0x73, 0x12, 0x26, 0x0a, 0x0e,
+	0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x69,
+	0x6c, 0x6c, 0x69, 0x73, 0x22, 0x51, 0x0a, 0x07, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x6e, 0x64, 0x12,
+	0x1a, 0x0a, 0x08, 0x73, 0x75, 0x72, 0x76, 0x69, 0x76, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x73, 0x75, 0x72, 0x76, 0x69, 0x76, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x63, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x05, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x6b, 0x69, 0x6c, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x05,
+	0x52, 0x05, 0x6b, 0x69, 0x6c, 0x6c, 0x73, 0x22, 0x92, 0x01, 0x0a, 0x08, 0x54, 0x69, 0x6d, 0x65,
+	0x53, 0x79, 0x6e, 0x63, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69,
+	0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x34,
+	0x0a, 0x0c, 0x61, 0x74, 0x74, 0x61, 0x63, 0x6b, 0x54, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x74, 0x74, 0x61, 0x63, 0x6b,
+	0x54, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x52, 0x0c, 0x61, 0x74, 0x74, 0x61, 0x63, 0x6b, 0x54, 0x69,
+	0x6d, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x76, 0x0a, 0x0c,
+	0x41, 0x74, 0x74, 0x61, 0x63, 0x6b, 0x54, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a, 0x06,
+	0x77, 0x69, 0x6e, 0x64, 0x55, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x77, 0x69,
+	0x6e, 0x64, 0x55, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x1a, 0x0a, 0x08,
+	0x72, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x72, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6f, 0x6c,
+	0x64, 0x6f, 0x77, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x63, 0x6f, 0x6f, 0x6c,
+	0x64, 0x6f, 0x77, 0x6e, 0x22, 0x72, 0x0a, 0x0c, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x4b, 0x69,
+	0x6c, 0x6c, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6b, 0x69, 0x6c, 0x6c, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6b, 0x69, 0x6c, 0x6c, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06,
+	0x76, 0x69, 0x63, 0x74, 0x69, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x76, 0x69,
+	0x63, 0x74, 0x69, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x63, 0x6b, 0x12, 0x0e, 0x0a, 0x02, 0x50, 0x78, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x02, 0x50, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x50, 0x79, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x02, 0x50, 0x79, 0x22, 0x89, 0x01, 0x0a, 0x0f, 0x50, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x50, 0x65, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x4c, 0x6f, 0x73, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x4c, 0x6f, 0x73, 0x73,
+	0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x74, 0x75, 0x6e, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x74, 0x75, 0x6e, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73,
+	0x12, 0x22, 0x0a, 0x0c, 0x72, 0x65, 0x76, 0x65, 0x61, 0x6c, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x72, 0x65, 0x76, 0x65, 0x61, 0x6c, 0x4d, 0x69,
+	0x6c, 0x6c, 0x69, 0x73, 0x22, 0x46, 0x0a, 0x0c, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4e, 0x6f,
+	0x74, 0x69, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x09, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x22, 0xa8, 0x01, 0x0a,
+	0x0c, 0x41, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x06, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x52, 0x6f,
+	0x6f, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x52, 0x6f,
+	0x6f, 0x6d, 0x73, 0x12, 0x22, 0x0a, 0x05, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x18, 0x06, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x6f, 0x6f, 0x6d, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x05, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x22, 0x64, 0x0a, 0x08, 0x52, 0x6f, 0x6f, 0x6d, 0x49,
+	0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x12, 0x14, 0x0a,
+	0x05, 0x73, 0x6c, 0x6f, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x6c,
+	0x6f, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x42, 0x22, 0x5a,
+	0x20, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b, 0x69, 0x73, 0x75,
+	0x6e, 0x6a, 0x69, 0x2f, 0x65, 0x62, 0x69, 0x74, 0x65, 0x6e, 0x2d, 0x70, 0x6f, 0x63, 0x2f, 0x70,
+	0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1314,48 +2409,69 @@ func file_message_proto_rawDescGZIP() []byte {
 	return file_message_proto_rawDescData
 }
 
-var file_message_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_message_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
 var file_message_proto_goTypes = []interface{}{
 	(*ClientMessage)(nil),      // 0: pb.ClientMessage
 	(*Input)(nil),              // 1: pb.Input
 	(*StartGame)(nil),          // 2: pb.StartGame
 	(*WorldUpdate)(nil),        // 3: pb.WorldUpdate
-	(*ServerMessage)(nil),      // 4: pb.ServerMessage
-	(*ConnectResponse)(nil),    // 5: pb.ConnectResponse
-	(*ConnectError)(nil),       // 6: pb.ConnectError
-	(*PlayerDisconnected)(nil), // 7: pb.PlayerDisconnected
-	(*NewHost)(nil),            // 8: pb.NewHost
-	(*UpdateLobby)(nil),        // 9: pb.UpdateLobby
-	(*GameStart)(nil),          // 10: pb.GameStart
-	(*UpdateEntity)(nil),       // 11: pb.UpdateEntity
-	(*UpdateEntities)(nil),     // 12: pb.UpdateEntities
-	(*NewCoin)(nil),            // 13: pb.NewCoin
-	(*CoinGot)(nil),            // 14: pb.CoinGot
-	(*GameEnd)(nil),            // 15: pb.GameEnd
-	(*TimeSync)(nil),           // 16: pb.TimeSync
+	(*SelectMap)(nil),          // 4: pb.SelectMap
+	(*SetCapacity)(nil),        // 5: pb.SetCapacity
+	(*ServerMessage)(nil),      // 6: pb.ServerMessage
+	(*ConnectResponse)(nil),    // 7: pb.ConnectResponse
+	(*ConnectError)(nil),       // 8: pb.ConnectError
+	(*PlayerDisconnected)(nil), // 9: pb.PlayerDisconnected
+	(*NewHost)(nil),            // 10: pb.NewHost
+	(*UpdateLobby)(nil),        // 11: pb.UpdateLobby
+	(*GameStart)(nil),          // 12: pb.GameStart
+	(*UpdateEntity)(nil),       // 13: pb.UpdateEntity
+	(*UpdateEntities)(nil),     // 14: pb.UpdateEntities
+	(*NewCoin)(nil),            // 15: pb.NewCoin
+	(*CoinGot)(nil),            // 16: pb.CoinGot
+	(*CoinGone)(nil),           // 17: pb.CoinGone
+	(*NewPickup)(nil),          // 18: pb.NewPickup
+	(*PickupGot)(nil),          // 19: pb.PickupGot
+	(*GameEnd)(nil),            // 20: pb.GameEnd
+	(*TimeSync)(nil),           // 21: pb.TimeSync
+	(*AttackTiming)(nil),       // 22: pb.AttackTiming
+	(*PlayerKilled)(nil),       // 23: pb.PlayerKilled
+	(*PlayerPenalized)(nil),    // 24: pb.PlayerPenalized
+	(*ServerNotice)(nil),       // 25: pb.ServerNotice
+	(*Announcement)(nil),       // 26: pb.Announcement
+	(*RoomInfo)(nil),           // 27: pb.RoomInfo
 }
 var file_message_proto_depIdxs = []int32{
 	1,  // 0: pb.ClientMessage.input:type_name -> pb.Input
 	2,  // 1: pb.ClientMessage.startGame:type_name -> pb.StartGame
 	3,  // 2: pb.ClientMessage.worldUpdate:type_name -> pb.WorldUpdate
-	5,  // 3: pb.ServerMessage.connectResponse:type_name -> pb.ConnectResponse
-	6,  // 4: pb.ServerMessage.connectError:type_name -> pb.ConnectError
-	9,  // 5: pb.ServerMessage.updateLobby:type_name -> pb.UpdateLobby
-	10, // 6: pb.ServerMessage.gameStart:type_name -> pb.GameStart
-	11, // 7: pb.ServerMessage.updateEntity:type_name -> pb.UpdateEntity
-	7,  // 8: pb.ServerMessage.playerDisconnected:type_name -> pb.PlayerDisconnected
-	8,  // 9: pb.ServerMessage.newHost:type_name -> pb.NewHost
-	12, // 10: pb.ServerMessage.updateEntities:type_name -> pb.UpdateEntities
-	13, // 11: pb.ServerMessage.newCoin:type_name -> pb.NewCoin
-	14, // 12: pb.ServerMessage.coinGot:type_name -> pb.CoinGot
-	15, // 13: pb.ServerMessage.gameEnd:type_name -> pb.GameEnd
-	16, // 14: pb.ServerMessage.timeSync:type_name -> pb.TimeSync
-	11, // 15: pb.UpdateEntities.updateEntity:type_name -> pb.UpdateEntity
-	16, // [16:16] is the sub-list for method output_type
-	16, // [16:16] is the sub-list for method input_type
-	16, // [16:16] is the sub-list for extension type_name
-	16, // [16:16] is the sub-list for extension extendee
-	0,  // [0:16] is the sub-list for field type_name
+	4,  // 3: pb.ClientMessage.selectMap:type_name -> pb.SelectMap
+	5,  // 4: pb.ClientMessage.setCapacity:type_name -> pb.SetCapacity
+	7,  // 5: pb.ServerMessage.connectResponse:type_name -> pb.ConnectResponse
+	8,  // 6: pb.ServerMessage.connectError:type_name -> pb.ConnectError
+	11, // 7: pb.ServerMessage.updateLobby:type_name -> pb.UpdateLobby
+	12, // 8: pb.ServerMessage.gameStart:type_name -> pb.GameStart
+	13, // 9: pb.ServerMessage.updateEntity:type_name -> pb.UpdateEntity
+	9,  // 10: pb.ServerMessage.playerDisconnected:type_name -> pb.PlayerDisconnected
+	10, // 11: pb.ServerMessage.newHost:type_name -> pb.NewHost
+	14, // 12: pb.ServerMessage.updateEntities:type_name -> pb.UpdateEntities
+	15, // 13: pb.ServerMessage.newCoin:type_name -> pb.NewCoin
+	16, // 14: pb.ServerMessage.coinGot:type_name -> pb.CoinGot
+	20, // 15: pb.ServerMessage.gameEnd:type_name -> pb.GameEnd
+	21, // 16: pb.ServerMessage.timeSync:type_name -> pb.TimeSync
+	25, // 17: pb.ServerMessage.serverNotice:type_name -> pb.ServerNotice
+	23, // 18: pb.ServerMessage.playerKilled:type_name -> pb.PlayerKilled
+	24, // 19: pb.ServerMessage.playerPenalized:type_name -> pb.PlayerPenalized
+	18, // 20: pb.ServerMessage.newPickup:type_name -> pb.NewPickup
+	19, // 21: pb.ServerMessage.pickupGot:type_name -> pb.PickupGot
+	17, // 22: pb.ServerMessage.coinGone:type_name -> pb.CoinGone
+	13, // 23: pb.UpdateEntities.updateEntity:type_name -> pb.UpdateEntity
+	22, // 24: pb.TimeSync.attackTiming:type_name -> pb.AttackTiming
+	27, // 25: pb.Announcement.rooms:type_name -> pb.RoomInfo
+	26, // [26:26] is the sub-list for method output_type
+	26, // [26:26] is the sub-list for method input_type
+	26, // [26:26] is the sub-list for extension type_name
+	26, // [26:26] is the sub-list for extension extendee
+	0,  // [0:26] is the sub-list for field type_name
 }
 
 func init() { file_message_proto_init() }
@@ -1413,7 +2529,7 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ServerMessage); i {
+			switch v := v.(*SelectMap); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1425,7 +2541,7 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ConnectResponse); i {
+			switch v := v.(*SetCapacity); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1437,7 +2553,7 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ConnectError); i {
+			switch v := v.(*ServerMessage); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1449,7 +2565,7 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PlayerDisconnected); i {
+			switch v := v.(*ConnectResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1461,7 +2577,7 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NewHost); i {
+			switch v := v.(*ConnectError); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1473,7 +2589,7 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateLobby); i {
+			switch v := v.(*PlayerDisconnected); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1485,7 +2601,7 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GameStart); i {
+			switch v := v.(*NewHost); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1497,7 +2613,7 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateEntity); i {
+			switch v := v.(*UpdateLobby); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1509,7 +2625,7 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateEntities); i {
+			switch v := v.(*GameStart); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1521,7 +2637,7 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NewCoin); i {
+			switch v := v.(*UpdateEntity); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1533,7 +2649,7 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CoinGot); i {
+			switch v := v.(*UpdateEntities); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1545,7 +2661,7 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GameEnd); i {
+			switch v := v.(*NewCoin); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1557,6 +2673,66 @@ func file_message_proto_init() {
 			}
 		}
 		file_message_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CoinGot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CoinGone); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewPickup); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PickupGot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GameEnd); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*TimeSync); i {
 			case 0:
 				return &v.state
@@ -1568,13 +2744,87 @@ func file_message_proto_init() {
 				return nil
 			}
 		}
+		file_message_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AttackTiming); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PlayerKilled); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PlayerPenalized); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerNotice); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Announcement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RoomInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_message_proto_msgTypes[0].OneofWrappers = []interface{}{
 		(*ClientMessage_Input)(nil),
 		(*ClientMessage_StartGame)(nil),
 		(*ClientMessage_WorldUpdate)(nil),
+		(*ClientMessage_SelectMap)(nil),
+		(*ClientMessage_SetCapacity)(nil),
 	}
-	file_message_proto_msgTypes[4].OneofWrappers = []interface{}{
+	file_message_proto_msgTypes[6].OneofWrappers = []interface{}{
 		(*ServerMessage_ConnectResponse)(nil),
 		(*ServerMessage_ConnectError)(nil),
 		(*ServerMessage_UpdateLobby)(nil),
@@ -1587,6 +2837,12 @@ func file_message_proto_init() {
 		(*ServerMessage_CoinGot)(nil),
 		(*ServerMessage_GameEnd)(nil),
 		(*ServerMessage_TimeSync)(nil),
+		(*ServerMessage_ServerNotice)(nil),
+		(*ServerMessage_PlayerKilled)(nil),
+		(*ServerMessage_PlayerPenalized)(nil),
+		(*ServerMessage_NewPickup)(nil),
+		(*ServerMessage_PickupGot)(nil),
+		(*ServerMessage_CoinGone)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1594,7 +2850,7 @@ func file_message_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_message_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   17,
+			NumMessages:   28,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
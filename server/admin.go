@@ -0,0 +1,311 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kisunji/ebiten-poc/pb"
+	"github.com/kisunji/ebiten-poc/transport"
+)
+
+// maxNoticeLength limits server notices to what fits on screen.
+const maxNoticeLength = 200
+
+// WorldSnapshot is a copy of a room's world for the admin API.
+type WorldSnapshot struct {
+	Room        int            `json:"room"`
+	Running     bool           `json:"running"`
+	Tick        int64          `json:"tick"`
+	HostSlot    int32          `json:"host_slot"`
+	PlayerSlots []bool         `json:"player_slots"`
+	Score       []int32        `json:"score"`
+	Chars       []CharSnapshot `json:"chars"`
+	Coins       []CoinSnapshot `json:"coins"`
+}
+
+type CharSnapshot struct {
+	Index       int     `json:"index"`
+	Player      bool    `json:"player"`
+	Px          float64 `json:"px"`
+	Py          float64 `json:"py"`
+	Fx          int     `json:"fx"`
+	Fy          int     `json:"fy"`
+	Vx          int     `json:"vx"`
+	Vy          int     `json:"vy"`
+	Speed       int     `json:"speed"`
+	Stamina     int     `json:"stamina"`
+	AttackPhase int     `json:"attack_phase"`
+	AttackFrame int     `json:"attack_frame"`
+	IsDead      bool    `json:"is_dead"`
+}
+
+type CoinSnapshot struct {
+	Index    int     `json:"index"`
+	Px       float64 `json:"px"`
+	Py       float64 `json:"py"`
+	PickedUp bool    `json:"picked_up"`
+}
+
+// Snapshot copies the room's world.
+func (h *Hub) Snapshot() WorldSnapshot {
+	var s WorldSnapshot
+	h.do(func() {
+		w := h.world
+		s = WorldSnapshot{
+			Room:        h.ID,
+			Running:     w.Running,
+			Tick:        w.tick,
+			HostSlot:    w.HostSlot,
+			PlayerSlots: append([]bool(nil), w.PlayerSlots...),
+			Score:       append([]int32(nil), w.Score...),
+			Chars:       []CharSnapshot{},
+			Coins:       []CoinSnapshot{},
+		}
+		for i, c := range w.Chars {
+			if c == nil {
+				continue
+			}
+			s.Chars = append(s.Chars, CharSnapshot{
+				Index:       i,
+				Player:      i < len(w.PlayerSlots) && w.PlayerSlots[i],
+				Px:          c.Px,
+				Py:          c.Py,
+				Fx:          c.Fx,
+				Fy:          c.Fy,
+				Vx:          c.Vx,
+				Vy:          c.Vy,
+				Speed:       c.Speed,
+				Stamina:     c.Stamina,
+				AttackPhase: int(c.AttackPhase),
+				AttackFrame: c.AttackFrame,
+				IsDead:      c.IsDead,
+			})
+		}
+		for i, c := range w.Coins {
+			s.Coins = append(s.Coins, CoinSnapshot{
+				Index:    i,
+				Px:       c.Px,
+				Py:       c.Py,
+				PickedUp: c.PickedUp,
+			})
+		}
+	})
+	return s
+}
+
+// EndMatch ends the running match as if time ran out. It reports whether
+// a match was running.
+func (h *Hub) EndMatch() bool {
+	var ok bool
+	h.do(func() {
+		if !h.world.Running {
+			return
+		}
+		h.world.end(EndAdmin)
+		h.matchOver()
+		// a draining room was only waiting for the match
+		if h.draining {
+			h.shutdown()
+		}
+		ok = true
+	})
+	return ok
+}
+
+// Kick disconnects the player in slot. It reports whether the slot was
+// taken.
+func (h *Hub) Kick(slot int32, reason string) bool {
+	var ok bool
+	h.do(func() {
+		for c, s := range h.clients {
+			if s == slot {
+				h.clientLog(c).Info("player kicked by admin", "reason", reason)
+				h.kick(c, transport.StatusPolicyViolation, reason)
+				ok = true
+				return
+			}
+		}
+	})
+	return ok
+}
+
+// Notice shows message to every player in the room.
+func (h *Hub) Notice(message string) {
+	h.do(func() {
+		h.sendToAll(&pb.ServerMessage{
+			Content: &pb.ServerMessage_ServerNotice{
+				ServerNotice: &pb.ServerNotice{
+					Message: message,
+				},
+			},
+		})
+	})
+}
+
+func (rs *Rooms) hub(id int) *Hub {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for _, h := range rs.hubs {
+		if h.ID == id {
+			return h
+		}
+	}
+	return nil
+}
+
+func (rs *Rooms) allHubs() []*Hub {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return append([]*Hub(nil), rs.hubs...)
+}
+
+// AdminHandler serves the admin API under /admin/. Every request must
+// carry "Authorization: Bearer <admin_token>"; with no token configured
+// every request is refused.
+//
+//	GET  /admin/rooms                list rooms
+//	GET  /admin/rooms/{id}           world snapshot
+//	POST /admin/rooms/{id}/end       end the running match
+//	POST /admin/rooms/{id}/kick      kick {"slot": n, "reason": "..."}
+//	POST /admin/rooms/{id}/notice    notice {"message": "..."} to one room
+//	POST /admin/notice               notice {"message": "..."} to every room
+func (rs *Rooms) AdminHandler() http.Handler {
+	token := rs.cfg.AdminToken
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/rooms", rs.adminRooms)
+	mux.HandleFunc("/admin/rooms/", rs.adminRoom)
+	mux.HandleFunc("/admin/notice", rs.adminNotice)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		got := strings.TrimPrefix(auth, "Bearer ")
+		if token == "" || got == auth || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (rs *Rooms) adminRooms(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, rs.Info())
+}
+
+func (rs *Rooms) adminRoom(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/admin/rooms/"), "/")
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+	h := rs.hub(id)
+	if h == nil {
+		http.Error(w, "no such room", http.StatusNotFound)
+		return
+	}
+	if len(parts) == 1 {
+		if allowMethod(w, r, http.MethodGet) {
+			writeJSON(w, h.Snapshot())
+		}
+		return
+	}
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	switch parts[1] {
+	case "end":
+		if !h.EndMatch() {
+			http.Error(w, "no match running", http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "kick":
+		var req struct {
+			Slot   *int32 `json:"slot"`
+			Reason string `json:"reason"`
+		}
+		if err := readJSON(r, &req); err != nil || req.Slot == nil {
+			http.Error(w, "body must be {\"slot\": n}", http.StatusBadRequest)
+			return
+		}
+		if req.Reason == "" {
+			req.Reason = "kicked by admin"
+		}
+		if !h.Kick(*req.Slot, req.Reason) {
+			http.Error(w, "no player in slot", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "notice":
+		message, err := readNotice(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.Notice(message)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (rs *Rooms) adminNotice(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	message, err := readNotice(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, h := range rs.allHubs() {
+		h.Notice(message)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func readNotice(r *http.Request) (string, error) {
+	var req struct {
+		Message string `json:"message"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return "", err
+	}
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" {
+		return "", errors.New("message must be set")
+	}
+	if len(req.Message) > maxNoticeLength {
+		return "", errors.New("message is too long")
+	}
+	return req.Message, nil
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 4096))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("admin: encoding response", "err", err)
+	}
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+	"github.com/kisunji/ebiten-poc/transport"
+)
+
+const testAdminToken = "0123456789abcdef"
+
+func adminRequest(t *testing.T, rs *Rooms, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+testAdminToken)
+	rec := httptest.NewRecorder()
+	rs.AdminHandler().ServeHTTP(rec, r)
+	return rec
+}
+
+// newAdminRoom starts a room with two players in the lobby.
+func newAdminRoom(t *testing.T) (*Rooms, *testPlayer, *testPlayer) {
+	cfg := DefaultConfig()
+	cfg.MaxRooms = 1
+	cfg.AdminToken = testAdminToken
+	rs := NewRooms(cfg)
+	host := connectPlayer(t, rs)
+	host.expect("connect response", isConnectResponse)
+	guest := connectPlayer(t, rs)
+	guest.expect("connect response", isConnectResponse)
+	return rs, host, guest
+}
+
+func TestAdminRequiresToken(t *testing.T) {
+	for _, token := range []string{"", testAdminToken} {
+		cfg := DefaultConfig()
+		cfg.AdminToken = token
+		rs := NewRooms(cfg)
+		for _, header := range []string{"", "Bearer", "Bearer wrong", testAdminToken} {
+			r := httptest.NewRequest("GET", "/admin/rooms", nil)
+			if header != "" {
+				r.Header.Set("Authorization", header)
+			}
+			rec := httptest.NewRecorder()
+			rs.AdminHandler().ServeHTTP(rec, r)
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("token %q, header %q: got status %d", token, header, rec.Code)
+			}
+		}
+	}
+}
+
+func TestAdminListAndSnapshot(t *testing.T) {
+	rs, host, _ := newAdminRoom(t)
+
+	rec := adminRequest(t, rs, "GET", "/admin/rooms", "")
+	var rooms []RoomInfo
+	if err := json.NewDecoder(rec.Body).Decode(&rooms); err != nil {
+		t.Fatal(err)
+	}
+	if len(rooms) != 1 || rooms[0].Players != 2 || len(rooms[0].Connected) != 2 || rooms[0].HostSlot != 0 {
+		t.Fatalf("got rooms %+v", rooms)
+	}
+
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_StartGame{}})
+	host.expect("game start", func(m *pb.ServerMessage) bool {
+		return m.GetGameStart() != nil
+	})
+	rec = adminRequest(t, rs, "GET", "/admin/rooms/1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+	}
+	var snap WorldSnapshot
+	if err := json.NewDecoder(rec.Body).Decode(&snap); err != nil {
+		t.Fatal(err)
+	}
+	if !snap.Running || len(snap.Chars) != common.DefaultCrowd || !snap.Chars[1].Player || snap.Chars[2].Player {
+		t.Fatalf("got snapshot %+v", snap)
+	}
+
+	if rec := adminRequest(t, rs, "GET", "/admin/rooms/9", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing room: got status %d", rec.Code)
+	}
+	if rec := adminRequest(t, rs, "POST", "/admin/rooms/1", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST snapshot: got status %d", rec.Code)
+	}
+}
+
+func TestAdminEndMatch(t *testing.T) {
+	rs, host, guest := newAdminRoom(t)
+	if rec := adminRequest(t, rs, "POST", "/admin/rooms/1/end", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("ending idle room: got status %d", rec.Code)
+	}
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_StartGame{}})
+	host.expect("game start", func(m *pb.ServerMessage) bool {
+		return m.GetGameStart() != nil
+	})
+	if rec := adminRequest(t, rs, "POST", "/admin/rooms/1/end", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+	}
+	for _, p := range []*testPlayer{host, guest} {
+		p.expect("game end", func(m *pb.ServerMessage) bool {
+			return m.GetGameEnd() != nil
+		})
+	}
+	if rs.Info()[0].Running {
+		t.Fatal("room still running")
+	}
+}
+
+func TestAdminKick(t *testing.T) {
+	rs, host, guest := newAdminRoom(t)
+	if rec := adminRequest(t, rs, "POST", "/admin/rooms/1/kick", `{"slot": 5}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("kicking empty slot: got status %d", rec.Code)
+	}
+	if rec := adminRequest(t, rs, "POST", "/admin/rooms/1/kick", `{}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("kick without slot: got status %d", rec.Code)
+	}
+	if rec := adminRequest(t, rs, "POST", "/admin/rooms/1/kick", `{"slot": 1, "reason": "afk"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+	}
+	host.expect("guest disconnected", func(m *pb.ServerMessage) bool {
+		pd := m.GetPlayerDisconnected()
+		return pd != nil && pd.Id == 1
+	})
+	for range guest.msgs {
+	}
+	_, err := guest.conn.Read(context.Background())
+	var ce *transport.CloseError
+	if !errors.As(err, &ce) || ce.Code != transport.StatusPolicyViolation || ce.Reason != "afk" {
+		t.Fatalf("got %v, want policy violation close", err)
+	}
+}
+
+func TestAdminNotice(t *testing.T) {
+	rs, host, guest := newAdminRoom(t)
+	if rec := adminRequest(t, rs, "POST", "/admin/notice", `{"message": "  "}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty notice: got status %d", rec.Code)
+	}
+	if rec := adminRequest(t, rs, "POST", "/admin/notice", `{"message": "restarting soon"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+	}
+	for _, p := range []*testPlayer{host, guest} {
+		n := p.expect("notice", func(m *pb.ServerMessage) bool {
+			return m.GetServerNotice() != nil
+		}).GetServerNotice()
+		if n.Message != "restarting soon" {
+			t.Fatalf("got notice %q", n.Message)
+		}
+	}
+}
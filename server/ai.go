@@ -1,70 +1,77 @@
 package server
 
 import (
-	"log"
 	"math"
 	"math/rand"
 	"time"
 
 	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
 )
 
 type AIData struct {
-	Id           int32
-	UpPressed    bool
-	DownPressed  bool
-	LeftPressed  bool
-	RightPressed bool
+	Id int32
+	// Walk in a new direction if set, otherwise stand still.
+	Walk bool
 }
 
 type AI struct {
 	*common.Char
 
-	id      int32
-	stop    bool
-	killSig chan struct{}
+	id int32
 }
 
+// RunAI decides when an AI walks or rests and tells the hub on aiChan.
+// The hub picks the direction since only it may read the Char.
 // RunAI should be run in a goroutine
 func (w *World) RunAI(ai *AI, aiChan chan AIData) {
-	moveTimer := time.NewTimer(time.Duration(rand.Intn(5000)) * time.Millisecond)
-	sleepTimer := time.NewTimer(time.Duration(rand.Intn(10000)) * time.Millisecond)
+	w.metrics.AIGoroutines.Inc()
+	defer w.metrics.AIGoroutines.Dec()
+	moveTimer := w.clock.NewTimer(time.Duration(rand.Intn(5000)) * time.Millisecond)
+	sleepTimer := w.clock.NewTimer(time.Duration(rand.Intn(10000)) * time.Millisecond)
 	sleepTimer.Stop()
+	defer func() {
+		moveTimer.Stop()
+		sleepTimer.Stop()
+	}()
 	for {
+		var data AIData
 		select {
-		case <-moveTimer.C:
-			aiChan <- nextMovement(ai)
+		case <-moveTimer.C():
+			data = AIData{Id: ai.id, Walk: true}
 			sleepTimer.Reset(time.Duration(rand.Intn(5000)) * time.Millisecond)
-		case <-sleepTimer.C:
-			aiChan <- AIData{
-				Id:           ai.id,
-				UpPressed:    false,
-				DownPressed:  false,
-				LeftPressed:  false,
-				RightPressed: false,
-			}
+		case <-sleepTimer.C():
+			data = AIData{Id: ai.id}
 			moveTimer.Reset(time.Duration(rand.Intn(5000)) * time.Millisecond)
-		case <-ai.killSig:
-			log.Printf("stopping ai %d\n", ai.id)
+		case <-w.killSig:
+			w.log.Debug("stopping ai", "ai", ai.id)
+			return
+		}
+		select {
+		case aiChan <- data:
+		case <-w.killSig:
+			w.log.Debug("stopping ai", "ai", ai.id)
 			return
 		}
 	}
 }
 
-func nextMovement(ai *AI) AIData {
-	aiData := AIData{Id: ai.id}
-	biasx := (ai.Px/float64(common.ScreenWidth) - .5) * 2
-	biasy := (ai.Py/float64(common.ScreenHeight) - .5) * 2
+// nextMovement picks a direction, biased back towards the middle of the
+// arena.
+func nextMovement(c *common.Char, arena *common.Arena) *pb.Input {
+	input := &pb.Input{}
+	biasx := (c.Px/arena.PixelWidth() - .5) * 2
+	biasy := (c.Py/arena.PixelHeight() - .5) * 2
 	if x := math.Round(rand.NormFloat64()*.5 - biasx); x < 0 {
-		aiData.LeftPressed = true
+		input.LeftPressed = true
 	} else if x > 0 {
-		aiData.RightPressed = true
+		input.RightPressed = true
 	}
 
 	if y := math.Round(rand.NormFloat64()*.5 - biasy); y < 0 {
-		aiData.UpPressed = true
+		input.UpPressed = true
 	} else if y > 0 {
-		aiData.DownPressed = true
+		input.DownPressed = true
 	}
-	return aiData
+	return input
 }
@@ -1,55 +1,37 @@
 package server
 
 import (
-	"log"
-	"net/http"
+	"context"
+	"log/slog"
 	"time"
 
-	"github.com/gorilla/websocket"
+	"github.com/kisunji/ebiten-poc/transport"
 )
 
-const (
-	// Time allowed to write a message to the peer.
-	writeWait = 1000 * time.Millisecond
-
-	// Time allowed to read the next pong message from the peer.
-	pongWait = 10 * time.Second
-
-	// Send pings to peer with this period. Must be less than pongWait.
-	pingPeriod = 5 * time.Second
-
-	// Maximum message size allowed from peer.
-	maxMessageSize = 128
-)
-
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		if len(r.Header["Origin"]) == 0 {
-			return true
-		}
-		if r.Header["Origin"][0] == "https://kisunji.github.io" {
-			return true
-		}
-		return false
-	},
-}
-
 type clientData struct {
 	client *Client
 	data   []byte
 }
 
-// Client is a middleman between the websocket connection and the hub.
+// Client is a middleman between the connection and the hub.
 type Client struct {
 	Hub *Hub
-	// The websocket connection.
-	Conn *websocket.Conn
+	// The connection to the player, a websocket or an in-memory pipe.
+	Conn transport.Conn
 	// Buffered channel of outbound messages.
 	Send chan []byte
 	// Client slot
 	clientSlot int32
+	// Chosen by the player, possibly empty. See common.CleanName.
+	name string
+	// Connection timeouts and limits.
+	cfg     Config
+	metrics *Metrics
+	// Logs with the remote address and, once joined, the room and slot.
+	log *slog.Logger
+	// Sent to the peer when the hub closes Send.
+	closeCode   transport.StatusCode
+	closeReason string
 }
 
 func (c *Client) ClientSlot() int32 {
@@ -60,70 +42,67 @@ func (c *Client) SendMessage(m []byte) {
 	c.Send <- m
 }
 
-// ReadPump pumps messages from the websocket connection to the Hub.
+// ReadPump pumps messages from the connection to the Hub.
 //
 // The application runs ReadPump in a per-connection goroutine. The application
 // ensures that there is at most one reader on a connection by executing all
 // reads from this goroutine.
 func (c *Client) ReadPump() {
 	defer func() {
-		c.Hub.unregister <- c
-		c.Conn.Close()
+		select {
+		case c.Hub.unregister <- c:
+		case <-c.Hub.done:
+		}
+		c.Conn.Close(transport.StatusNormalClosure, "")
 	}()
-	c.Conn.SetReadLimit(maxMessageSize)
-	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
-	c.Conn.SetPongHandler(func(string) error {
-		c.Conn.SetReadDeadline(time.Now().Add(pongWait))
-		return nil
-	})
 	for {
-		_, buf, err := c.Conn.ReadMessage()
+		buf, err := c.Conn.Read(context.Background())
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Println(err)
+			if !transport.IsExpectedClose(err) {
+				c.log.Info("connection lost", "err", err)
 			}
 			break
 		}
-		c.Hub.clientData <- clientData{
+		select {
+		case c.Hub.clientData <- clientData{
 			client: c,
 			data:   buf,
+		}:
+		case <-c.Hub.done:
+			return
 		}
 	}
 }
 
-// WritePump pumps messages from the Hub to the websocket connection.
+// WritePump pumps messages from the Hub to the connection.
 //
 // A goroutine running WritePump is started for each connection. The
 // application ensures that there is at most one writer to a connection by
 // executing all writes from this goroutine.
 func (c *Client) WritePump() {
-	ticker := time.NewTicker(pingPeriod)
+	ticker := time.NewTicker(c.cfg.PingPeriod)
+	code, reason := transport.StatusNormalClosure, ""
 	defer func() {
 		ticker.Stop()
-		_ = c.Conn.Close()
+		_ = c.Conn.Close(code, reason)
 	}()
+	ctx := context.Background()
 	for {
 		select {
 		case message, ok := <-c.Send:
-			_ = c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
-				// The hub closed the channel.
-				_ = c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				// The hub closed the channel, after setting the close code.
+				if c.closeCode != 0 {
+					code, reason = c.closeCode, c.closeReason
+				}
 				return
 			}
-
-			w, err := c.Conn.NextWriter(websocket.BinaryMessage)
-			if err != nil {
-				return
-			}
-			w.Write(message)
-
-			if err := w.Close(); err != nil {
+			if err := c.Conn.Write(ctx, message); err != nil {
 				return
 			}
+			c.metrics.BytesSent.Add(uint64(len(message)))
 		case <-ticker.C:
-			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			if err := c.Conn.Ping(ctx); err != nil {
 				return
 			}
 		}
@@ -1,15 +1,21 @@
 package main
 
 import (
+	"context"
 	"flag"
-	"log"
+	"log/slog"
 	"math/rand"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/kisunji/ebiten-poc/server"
 )
 
+var configPath = flag.String("config", "", "path to YAML config file")
 var port = flag.String("port", ":8080", "http service address")
 var insecure = flag.Bool("insecure", false, "listen over insecure ws")
 var cert = flag.String("cert", "", "path to cert file")
@@ -20,25 +26,96 @@ func main() {
 
 	rand.Seed(time.Now().UnixNano())
 
-	hub := server.NewHub()
-	go hub.Run()
-
-	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		hub.ServeWs(w, r)
+	cfg, err := server.LoadConfig(*configPath)
+	if err != nil {
+		fatal("loading config", err)
+	}
+	// flags given on the command line win over the file and environment
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Addr = *port
+		case "insecure":
+			cfg.Insecure = *insecure
+		case "cert":
+			cfg.CertFile = *cert
+		case "key":
+			cfg.KeyFile = *key
+		}
 	})
-	log.Printf("listening on port %s\n", *port)
-	if *insecure {
-		err := http.ListenAndServe(*port, nil)
+	if err := cfg.Validate(); err != nil {
+		fatal("invalid config", err)
+	}
+	slog.SetDefault(server.NewLogger(cfg, os.Stderr))
+
+	// the first signal starts a graceful shutdown, a second one kills
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rooms := server.NewRooms(cfg)
+	if cfg.HistoryFile != "" {
+		history, err := server.OpenHistory(cfg.HistoryFile)
 		if err != nil {
-			log.Fatal("ListenAndServe: ", err)
+			fatal("opening match history", err)
 		}
-	} else {
-		if *cert == "" || *key == "" {
-			log.Fatal("must provide --cert and --key")
+		defer history.Close()
+		rooms.SetHistory(history)
+	}
+
+	if cfg.Discovery {
+		conn, err := net.ListenPacket("udp4", ":0")
+		if err != nil {
+			fatal("discovery", err)
 		}
-		err := http.ListenAndServeTLS(*port, *cert, *key, nil)
+		dest, err := net.ResolveUDPAddr("udp4", cfg.DiscoveryAddr)
 		if err != nil {
-			log.Fatal("ListenAndServeTLS: ", err)
+			fatal("discovery", err)
 		}
+		slog.Info("announcing", "addr", dest)
+		go rooms.Announce(ctx, conn, dest, cfg.DiscoveryInterval)
 	}
+
+	http.HandleFunc("/ws", rooms.ServeWs)
+	http.HandleFunc("/metrics", rooms.ServeMetrics)
+	http.HandleFunc("/matches", rooms.ServeMatches)
+	http.HandleFunc("/leaderboard", rooms.ServeLeaderboard)
+	if cfg.AdminToken != "" {
+		http.Handle("/admin/", rooms.AdminHandler())
+	}
+	srv := &http.Server{Addr: cfg.Addr}
+	go func() {
+		slog.Info("listening", "addr", cfg.Addr, "tls", !cfg.Insecure)
+		if cfg.Insecure {
+			err := srv.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				fatal("ListenAndServe", err)
+			}
+		} else {
+			err := srv.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile)
+			if err != nil && err != http.ErrServerClosed {
+				fatal("ListenAndServeTLS", err)
+			}
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	slog.Info("shutting down, waiting for matches to finish", "timeout", cfg.DrainTimeout.String())
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.DrainTimeout)
+	defer cancel()
+	if err := rooms.Shutdown(drainCtx); err != nil {
+		slog.Warn("ended running matches early", "err", err)
+	}
+	// websockets are closed by now; this only waits on plain HTTP requests
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("http shutdown", "err", err)
+	}
+	slog.Info("server stopped")
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+// CoinPattern decides where and how often coins appear.
+type CoinPattern struct {
+	Name string
+	// Longest wait between spawns.
+	Interval time.Duration
+	// Coins placed at each spawn, as far as max_coins allows.
+	Burst int
+	// Picks where the next coin goes.
+	spot func(w *World) (x, y float64)
+}
+
+const (
+	// Spots the hotspots pattern gathers coins around.
+	numHotspots = 3
+	// How far from its hotspot a coin may land, in pixels.
+	hotspotRadius = 48.0
+)
+
+// CoinPatterns are the coin patterns a server can be configured with, by
+// name.
+var CoinPatterns = map[string]CoinPattern{
+	// One coin at a time, anywhere.
+	"uniform": {
+		Name:     "uniform",
+		Interval: 5 * time.Second,
+		Burst:    1,
+		spot:     func(w *World) (float64, float64) { return w.arena.RandomSpot() },
+	},
+	// Coins keep turning up around a few spots picked for the match.
+	"hotspots": {
+		Name:     "hotspots",
+		Interval: 3 * time.Second,
+		Burst:    1,
+		spot:     (*World).hotspotSpot,
+	},
+	// Now and then a batch of coins appears all over the map at once.
+	"waves": {
+		Name:     "waves",
+		Interval: 20 * time.Second,
+		Burst:    8,
+		spot:     func(w *World) (float64, float64) { return w.arena.RandomSpot() },
+	},
+	// Coins only appear around the middle of the map, drawing everyone in.
+	"center": {
+		Name:     "center",
+		Interval: 4 * time.Second,
+		Burst:    1,
+		spot: func(w *World) (float64, float64) {
+			width, height := w.arena.PixelWidth(), w.arena.PixelHeight()
+			return w.arena.RandomSpotNear(width/2, height/2, math.Min(width, height)/4)
+		},
+	},
+}
+
+func coinPatternNames() []string {
+	names := make([]string, 0, len(CoinPatterns))
+	for name := range CoinPatterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hotspotSpot returns a spot near one of the match's hotspots, picking
+// them the first time.
+func (w *World) hotspotSpot() (float64, float64) {
+	if w.hotspots == nil {
+		for i := 0; i < numHotspots; i++ {
+			x, y := w.arena.RandomSpot()
+			w.hotspots = append(w.hotspots, [2]float64{x, y})
+		}
+	}
+	h := w.hotspots[rand.Intn(len(w.hotspots))]
+	return w.arena.RandomSpotNear(h[0], h[1], hotspotRadius)
+}
+
+// spawnCoins places the pattern's next coins and announces them. Each
+// takes the index of a collected or expired coin if there is any, and
+// none appear while maxCoins are lying around.
+func (w *World) spawnCoins() {
+	if !w.Running {
+		return
+	}
+	for n := 0; n < w.coinPattern.Burst; n++ {
+		index := w.freeCoin()
+		if index < 0 {
+			return
+		}
+		coin := common.NewCoinAt(w.coinPattern.spot(w))
+		w.Coins[index] = coin
+		w.coinUntil[index] = time.Time{}
+		if w.coinLifetime > 0 {
+			w.coinUntil[index] = w.clock.Now().Add(w.coinLifetime)
+		}
+		w.send(&pb.ServerMessage{
+			Content: &pb.ServerMessage_NewCoin{
+				NewCoin: &pb.NewCoin{
+					Index:       int32(index),
+					Px:          coin.Px,
+					Py:          coin.Py,
+					FrameOffset: int32(coin.FrameOffset),
+				},
+			},
+		})
+	}
+}
+
+// freeCoin returns the index for a new coin, or -1 if there is no room.
+func (w *World) freeCoin() int {
+	for i, coin := range w.Coins {
+		if coin.PickedUp {
+			return i
+		}
+	}
+	if len(w.Coins) >= w.maxCoins {
+		return -1
+	}
+	w.Coins = append(w.Coins, nil)
+	w.coinUntil = append(w.coinUntil, time.Time{})
+	return len(w.Coins) - 1
+}
+
+// expireCoins removes coins that have lain uncollected for coinLifetime.
+func (w *World) expireCoins() {
+	now := w.clock.Now()
+	for i, coin := range w.Coins {
+		until := w.coinUntil[i]
+		if coin.PickedUp || until.IsZero() || now.Before(until) {
+			continue
+		}
+		coin.PickedUp = true
+		w.send(&pb.ServerMessage{
+			Content: &pb.ServerMessage_CoinGone{
+				CoinGone: &pb.CoinGone{Index: int32(i)},
+			},
+		})
+	}
+}
+
+// collectCoins gives each coin a player is touching to that player. A coin
+// two players touch goes to the one in the lower slot.
+func (w *World) collectCoins() {
+	w.coinGrid.Clear()
+	var reach float64
+	for i, coin := range w.Coins {
+		if !coin.PickedUp {
+			w.coinGrid.Insert(i, coin.Px, coin.Py)
+			reach = math.Max(reach, coin.PickupRadius)
+		}
+	}
+	for j, isPlayer := range w.PlayerSlots {
+		if !isPlayer {
+			continue
+		}
+		target := w.Chars[j]
+		if target.IsDead {
+			continue
+		}
+		w.near = w.coinGrid.Near(w.near[:0], target.Px, target.Py, reach)
+		sort.Ints(w.near)
+		for _, i := range w.near {
+			coin := w.Coins[i]
+			if coin.PickedUp || !isHit(target.Px, target.Py, coin.Px, coin.Py, coin.PickupRadius) {
+				continue
+			}
+			w.Score[j]++
+			coin.PickedUp = true
+			w.send(&pb.ServerMessage{
+				Content: &pb.ServerMessage_CoinGot{
+					CoinGot: &pb.CoinGot{Index: int32(i)},
+				},
+			})
+		}
+	}
+}
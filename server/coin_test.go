@@ -0,0 +1,122 @@
+package server
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+func TestCoinPatterns(t *testing.T) {
+	for _, name := range coinPatternNames() {
+		t.Run(name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.CoinPattern = name
+			w, _, _ := newTestWorld(t, cfg, 0)
+			pattern := CoinPatterns[name]
+			w.spawnCoins()
+			if len(w.Coins) != pattern.Burst {
+				t.Fatalf("got %d coins, want %d", len(w.Coins), pattern.Burst)
+			}
+			for i := 0; i < 20; i++ {
+				w.spawnCoins()
+			}
+			for _, coin := range w.Coins {
+				if w.arena.Blocked(coin.Px, coin.Py, common.CharRadius) {
+					t.Fatalf("coin at (%v, %v) is in a wall", coin.Px, coin.Py)
+				}
+			}
+		})
+	}
+
+	cfg := DefaultConfig()
+	cfg.CoinPattern = "center"
+	w, _, _ := newTestWorld(t, cfg, 0)
+	for i := 0; i < 20; i++ {
+		w.spawnCoins()
+	}
+	width, height := w.arena.PixelWidth(), w.arena.PixelHeight()
+	for _, coin := range w.Coins {
+		if math.Hypot(coin.Px-width/2, coin.Py-height/2) > math.Min(width, height)/4 {
+			t.Fatalf("coin at (%v, %v) is far from the center", coin.Px, coin.Py)
+		}
+	}
+}
+
+func TestMaxCoins(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CoinPattern = "waves"
+	cfg.MaxCoins = 10
+	w, _, sent := newTestWorld(t, cfg, 0)
+	w.spawnCoins()
+	w.spawnCoins()
+	if len(w.Coins) != cfg.MaxCoins {
+		t.Fatalf("got %d coins, want %d", len(w.Coins), cfg.MaxCoins)
+	}
+	n := len(*sent)
+	w.spawnCoins()
+	if len(*sent) != n {
+		t.Fatal("spawned a coin with no room")
+	}
+
+	// a collected coin makes way for the next
+	w.Coins[4].PickedUp = true
+	w.spawnCoins()
+	if nc := (*sent)[len(*sent)-1].GetNewCoin(); nc == nil || nc.Index != 4 || w.Coins[4].PickedUp {
+		t.Fatalf("new coin did not reuse index 4: %v", nc)
+	}
+	if len(w.Coins) != cfg.MaxCoins {
+		t.Fatalf("got %d coins, want %d", len(w.Coins), cfg.MaxCoins)
+	}
+}
+
+func TestCoinExpiry(t *testing.T) {
+	cfg := DefaultConfig()
+	w, clock, sent := newTestWorld(t, cfg, 0, 1)
+	placeApart(w)
+	w.spawnCoins()
+	clock.Advance(cfg.CoinLifetime / 2)
+	w.spawnCoins()
+
+	clock.Advance(cfg.CoinLifetime / 2)
+	w.update()
+	if !w.Coins[0].PickedUp || w.Coins[1].PickedUp {
+		t.Fatalf("got coins %+v %+v", w.Coins[0], w.Coins[1])
+	}
+	var gone []int32
+	for _, m := range *sent {
+		if cg := m.GetCoinGone(); cg != nil {
+			gone = append(gone, cg.Index)
+		}
+	}
+	if len(gone) != 1 || gone[0] != 0 {
+		t.Fatalf("sent coin gone for %v", gone)
+	}
+	if w.Score[0] != 0 {
+		t.Fatal("expired coin was scored")
+	}
+
+	// the next coin takes its place
+	w.spawnCoins()
+	if nc := (*sent)[len(*sent)-1].GetNewCoin(); nc == nil || nc.Index != 0 {
+		t.Fatalf("new coin did not reuse index 0: %v", nc)
+	}
+
+	// coins without a lifetime stay
+	cfg.CoinLifetime = 0
+	w, clock, sent = newTestWorld(t, cfg, 0, 1)
+	placeApart(w)
+	w.spawnCoins()
+	clock.Advance(time.Hour)
+	w.update()
+	if w.Coins[0].PickedUp {
+		t.Fatal("coin expired")
+	}
+	for _, m := range *sent {
+		if _, ok := m.Content.(*pb.ServerMessage_CoinGone); ok {
+			t.Fatal("sent coin gone")
+		}
+	}
+}
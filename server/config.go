@@ -0,0 +1,390 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is prepended to the names of environment variables that
+// override values from the config file.
+const EnvPrefix = "ONEOFTHEM_"
+
+// Config holds server settings. The zero value is not usable; start from
+// DefaultConfig.
+type Config struct {
+	// Address to listen on, e.g. ":8080".
+	Addr string `yaml:"addr"`
+	// Serve plain ws instead of wss.
+	Insecure bool   `yaml:"insecure"`
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// Origins allowed to open a websocket. "*" allows any origin.
+	// Requests without an Origin header (non-browser clients) are always allowed.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+
+	// World updates per second.
+	TickRate int `yaml:"tick_rate"`
+	// Maximum number of rooms running at once.
+	MaxRooms int `yaml:"max_rooms"`
+	// Number of human players per room, until its host picks another size.
+	PlayerSlots int           `yaml:"player_slots"`
+	MatchLength time.Duration `yaml:"match_length"`
+	// Number of characters in a match, players included, until the
+	// room's host picks another size. Empty player slots and the rest are
+	// played by AIs.
+	CrowdSize int `yaml:"crowd_size"`
+	// Longest wait between power-ups appearing. Zero turns them off.
+	PickupInterval time.Duration `yaml:"pickup_interval"`
+	// Where and how often coins appear, one of the keys of CoinPatterns.
+	CoinPattern string `yaml:"coin_pattern"`
+	// Most coins lying in the arena at once.
+	MaxCoins int `yaml:"max_coins"`
+	// How long a coin lies around before it disappears. Zero keeps coins
+	// until they are collected.
+	CoinLifetime time.Duration `yaml:"coin_lifetime"`
+	// Rules for every room, one of the keys of Modes.
+	Mode string `yaml:"mode"`
+	// Map a room plays on until its host picks another, one of the keys
+	// of common.Arenas.
+	Map string `yaml:"map"`
+
+	// Length of each attack phase, rounded to whole ticks.
+	AttackWindUp   time.Duration `yaml:"attack_wind_up"`
+	AttackActive   time.Duration `yaml:"attack_active"`
+	AttackRecovery time.Duration `yaml:"attack_recovery"`
+	AttackCooldown time.Duration `yaml:"attack_cooldown"`
+
+	// Time allowed to write a message to the peer.
+	WriteWait time.Duration `yaml:"write_wait"`
+	// Time allowed to read the next pong message from the peer.
+	PongWait time.Duration `yaml:"pong_wait"`
+	// Send pings to peer with this period. Must be less than PongWait.
+	PingPeriod time.Duration `yaml:"ping_period"`
+	// Maximum message size allowed from peer.
+	MaxMessageSize int64 `yaml:"max_message_size"`
+
+	// Name shown in LAN server browsers. Defaults to the hostname.
+	Name string `yaml:"name"`
+	// Announce the server on the local network.
+	Discovery bool `yaml:"discovery"`
+	// Where announcements are sent, normally the broadcast address.
+	DiscoveryAddr     string        `yaml:"discovery_addr"`
+	DiscoveryInterval time.Duration `yaml:"discovery_interval"`
+
+	// One of debug, info, warn or error.
+	LogLevel string `yaml:"log_level"`
+	// text (logfmt) or json.
+	LogFormat string `yaml:"log_format"`
+
+	// How long a shutdown waits for running matches to finish before
+	// ending them.
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+
+	// Bearer token for the admin API under /admin/. The API is disabled
+	// when empty.
+	AdminToken string `yaml:"admin_token"`
+
+	// JSON Lines file that finished matches are appended to. Matches are
+	// only kept in memory when empty.
+	HistoryFile string `yaml:"history_file"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Addr:           ":8080",
+		AllowedOrigins: []string{"https://kisunji.github.io"},
+		TickRate:       60,
+		MaxRooms:       8,
+		PlayerSlots:    common.DefaultPlayers,
+		MatchLength:    3 * time.Minute,
+		CrowdSize:      common.DefaultCrowd,
+		PickupInterval: 15 * time.Second,
+		CoinPattern:    "uniform",
+		MaxCoins:       32,
+		CoinLifetime:   30 * time.Second,
+		Mode:           "classic",
+		Map:            common.DefaultArena,
+		AttackWindUp:   150 * time.Millisecond,
+		AttackActive:   50 * time.Millisecond,
+		AttackRecovery: 150 * time.Millisecond,
+		AttackCooldown: 300 * time.Millisecond,
+		WriteWait:      1000 * time.Millisecond,
+		PongWait:       10 * time.Second,
+		PingPeriod:     5 * time.Second,
+		MaxMessageSize: 128,
+
+		DiscoveryAddr:     fmt.Sprintf("255.255.255.255:%d", common.DiscoveryPort),
+		DiscoveryInterval: 2 * time.Second,
+
+		LogLevel:     "info",
+		LogFormat:    "text",
+		DrainTimeout: time.Minute,
+	}
+}
+
+// LoadConfig reads the YAML file at path on top of DefaultConfig and then
+// applies environment overrides. An empty path skips the file.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return cfg, err
+		}
+		defer f.Close()
+		dec := yaml.NewDecoder(f)
+		dec.KnownFields(true)
+		if err := dec.Decode(&cfg); err != nil {
+			return cfg, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	str := func(p *string) func(string) error {
+		return func(v string) error { *p = v; return nil }
+	}
+	integer := func(p *int) func(string) error {
+		return func(v string) error {
+			n, err := strconv.Atoi(v)
+			*p = n
+			return err
+		}
+	}
+	duration := func(p *time.Duration) func(string) error {
+		return func(v string) error {
+			d, err := time.ParseDuration(v)
+			*p = d
+			return err
+		}
+	}
+	vars := map[string]func(string) error{
+		"ADDR":      str(&c.Addr),
+		"CERT_FILE": str(&c.CertFile),
+		"KEY_FILE":  str(&c.KeyFile),
+		"INSECURE": func(v string) error {
+			b, err := strconv.ParseBool(v)
+			c.Insecure = b
+			return err
+		},
+		"ALLOWED_ORIGINS": func(v string) error {
+			c.AllowedOrigins = nil
+			for _, o := range strings.Split(v, ",") {
+				if o = strings.TrimSpace(o); o != "" {
+					c.AllowedOrigins = append(c.AllowedOrigins, o)
+				}
+			}
+			return nil
+		},
+		"TICK_RATE":    integer(&c.TickRate),
+		"MAX_ROOMS":    integer(&c.MaxRooms),
+		"PLAYER_SLOTS": integer(&c.PlayerSlots),
+		"MATCH_LENGTH": duration(&c.MatchLength),
+		"MODE":         str(&c.Mode),
+		"WRITE_WAIT":   duration(&c.WriteWait),
+		"PONG_WAIT":    duration(&c.PongWait),
+		"PING_PERIOD":  duration(&c.PingPeriod),
+		"MAX_MESSAGE_SIZE": func(v string) error {
+			n, err := strconv.ParseInt(v, 10, 64)
+			c.MaxMessageSize = n
+			return err
+		},
+		"NAME": str(&c.Name),
+		"DISCOVERY": func(v string) error {
+			b, err := strconv.ParseBool(v)
+			c.Discovery = b
+			return err
+		},
+		"DISCOVERY_ADDR":     str(&c.DiscoveryAddr),
+		"DISCOVERY_INTERVAL": duration(&c.DiscoveryInterval),
+		"LOG_LEVEL":          str(&c.LogLevel),
+		"LOG_FORMAT":         str(&c.LogFormat),
+		"DRAIN_TIMEOUT":      duration(&c.DrainTimeout),
+		"ADMIN_TOKEN":        str(&c.AdminToken),
+		"HISTORY_FILE":       str(&c.HistoryFile),
+		"ATTACK_WIND_UP":     duration(&c.AttackWindUp),
+		"ATTACK_ACTIVE":      duration(&c.AttackActive),
+		"ATTACK_RECOVERY":    duration(&c.AttackRecovery),
+		"ATTACK_COOLDOWN":    duration(&c.AttackCooldown),
+		"MAP":                str(&c.Map),
+		"CROWD_SIZE":         integer(&c.CrowdSize),
+		"PICKUP_INTERVAL":    duration(&c.PickupInterval),
+		"COIN_PATTERN":       str(&c.CoinPattern),
+		"MAX_COINS":          integer(&c.MaxCoins),
+		"COIN_LIFETIME":      duration(&c.CoinLifetime),
+	}
+	for name, set := range vars {
+		v, ok := lookup(EnvPrefix + name)
+		if !ok {
+			continue
+		}
+		if err := set(v); err != nil {
+			return fmt.Errorf("%s%s: %w", EnvPrefix, name, err)
+		}
+	}
+	return nil
+}
+
+// Validate reports every problem with the config at once.
+func (c Config) Validate() error {
+	var errs []string
+	if c.Addr == "" {
+		errs = append(errs, "addr must be set")
+	}
+	if !c.Insecure && (c.CertFile == "" || c.KeyFile == "") {
+		errs = append(errs, "cert_file and key_file are required unless insecure is set")
+	}
+	for _, o := range c.AllowedOrigins {
+		if o == "*" {
+			continue
+		}
+		u, err := url.Parse(o)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+			errs = append(errs, fmt.Sprintf("allowed origin %q must look like scheme://host[:port]", o))
+		}
+	}
+	if c.TickRate < 1 || c.TickRate > 240 {
+		errs = append(errs, "tick_rate must be between 1 and 240")
+	}
+	if c.MaxRooms < 1 {
+		errs = append(errs, "max_rooms must be at least 1")
+	}
+	errs = append(errs, capacityProblems(c.PlayerSlots, c.CrowdSize)...)
+	if c.PickupInterval < 0 {
+		errs = append(errs, "pickup_interval must not be negative")
+	}
+	if _, ok := CoinPatterns[c.CoinPattern]; !ok {
+		errs = append(errs, fmt.Sprintf("coin_pattern must be one of %s", strings.Join(coinPatternNames(), ", ")))
+	}
+	if c.MaxCoins < 1 || c.MaxCoins > common.MaxCoins {
+		errs = append(errs, fmt.Sprintf("max_coins must be between 1 and %d", common.MaxCoins))
+	}
+	if c.CoinLifetime < 0 {
+		errs = append(errs, "coin_lifetime must not be negative")
+	}
+	if c.MatchLength < time.Minute {
+		errs = append(errs, "match_length must be at least 1m")
+	}
+	if _, ok := Modes[c.Mode]; !ok {
+		errs = append(errs, fmt.Sprintf("mode must be one of %s", strings.Join(modeNames(), ", ")))
+	}
+	if _, ok := common.Arenas[c.Map]; !ok {
+		errs = append(errs, fmt.Sprintf("map must be one of %s", strings.Join(common.ArenaNames(), ", ")))
+	}
+	for _, d := range []time.Duration{c.AttackWindUp, c.AttackActive, c.AttackRecovery, c.AttackCooldown} {
+		if d < 0 || d > 5*time.Second {
+			errs = append(errs, "attack_wind_up, attack_active, attack_recovery and attack_cooldown must be between 0 and 5s")
+			break
+		}
+	}
+	if c.AttackActive <= 0 {
+		errs = append(errs, "attack_active must be positive")
+	}
+	if c.WriteWait <= 0 || c.PongWait <= 0 || c.PingPeriod <= 0 {
+		errs = append(errs, "write_wait, pong_wait and ping_period must be positive")
+	} else if c.PingPeriod >= c.PongWait {
+		errs = append(errs, "ping_period must be less than pong_wait")
+	}
+	if c.MaxMessageSize < 1 {
+		errs = append(errs, "max_message_size must be positive")
+	}
+	if c.Discovery {
+		if _, err := net.ResolveUDPAddr("udp4", c.DiscoveryAddr); err != nil {
+			errs = append(errs, fmt.Sprintf("discovery_addr: %v", err))
+		}
+		if c.DiscoveryInterval < 100*time.Millisecond {
+			errs = append(errs, "discovery_interval must be at least 100ms")
+		}
+		if _, err := c.port(); err != nil {
+			errs = append(errs, fmt.Sprintf("addr: %v", err))
+		}
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs = append(errs, "log_level must be one of debug, info, warn, error")
+	}
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		errs = append(errs, "log_format must be text or json")
+	}
+	if c.DrainTimeout < 0 {
+		errs = append(errs, "drain_timeout must not be negative")
+	}
+	if c.AdminToken != "" && len(c.AdminToken) < 16 {
+		errs = append(errs, "admin_token must be at least 16 characters")
+	}
+	if len(errs) > 0 {
+		return errors.New("invalid config: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// capacityProblems lists what is wrong with a room of players player
+// slots and crowd characters, or nothing if it can be played.
+func capacityProblems(players, crowd int) []string {
+	var errs []string
+	if players < 1 || players > common.MaxClients {
+		errs = append(errs, fmt.Sprintf("player_slots must be between 1 and %d", common.MaxClients))
+	}
+	if crowd < players {
+		errs = append(errs, "crowd_size must be at least player_slots")
+	} else if crowd+players > common.MaxChars {
+		// each player may also have a decoy out
+		errs = append(errs, fmt.Sprintf("crowd_size plus player_slots must be at most %d", common.MaxChars))
+	}
+	return errs
+}
+
+// port returns the numeric port the server listens on.
+func (c Config) port() (int, error) {
+	_, p, err := net.SplitHostPort(c.Addr)
+	if err != nil {
+		return 0, err
+	}
+	return net.LookupPort("tcp", p)
+}
+
+func (c Config) tickDuration() time.Duration {
+	return time.Second / time.Duration(c.TickRate)
+}
+
+// attackTiming converts the attack phases to ticks. The active phase
+// always lasts at least one tick.
+func (c Config) attackTiming() common.AttackTiming {
+	tick := c.tickDuration()
+	ticks := func(d time.Duration) int {
+		return int((d + tick/2) / tick)
+	}
+	t := common.AttackTiming{
+		WindUp:   ticks(c.AttackWindUp),
+		Active:   ticks(c.AttackActive),
+		Recovery: ticks(c.AttackRecovery),
+		Cooldown: ticks(c.AttackCooldown),
+	}
+	if t.Active < 1 {
+		t.Active = 1
+	}
+	return t
+}
+
+func (c Config) originAllowed(origin string) bool {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+addr: ":9000"
+insecure: true
+allowed_origins:
+  - https://example.com
+tick_rate: 30
+pong_wait: 20s
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvPrefix+"MAX_ROOMS", "3")
+	t.Setenv(EnvPrefix+"ALLOWED_ORIGINS", "https://a.example, http://localhost:8000")
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addr != ":9000" || cfg.TickRate != 30 || cfg.PongWait != 20*time.Second {
+		t.Errorf("file values not applied: %+v", cfg)
+	}
+	if cfg.MaxRooms != 3 {
+		t.Errorf("got MaxRooms %d, want 3 from env", cfg.MaxRooms)
+	}
+	if !cfg.originAllowed("http://localhost:8000") || cfg.originAllowed("https://example.com") {
+		t.Errorf("env origins not applied: %v", cfg.AllowedOrigins)
+	}
+	if cfg.PingPeriod != DefaultConfig().PingPeriod {
+		t.Errorf("unset value changed: %v", cfg.PingPeriod)
+	}
+}
+
+func TestLoadConfigUnknownField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("tickrate: 30\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected error for misspelled field")
+	}
+}
+
+func TestLoadConfigBadEnv(t *testing.T) {
+	t.Setenv(EnvPrefix+"TICK_RATE", "fast")
+	_, err := LoadConfig("")
+	if err == nil || !strings.Contains(err.Error(), EnvPrefix+"TICK_RATE") {
+		t.Fatalf("got %v, want error naming the variable", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("default config without TLS files should not validate")
+	}
+	cfg.Insecure = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	cfg.AllowedOrigins = []string{"example.com"}
+	cfg.PingPeriod = cfg.PongWait
+	cfg.PlayerSlots = 0
+	cfg.CrowdSize = common.MaxChars + 1
+	cfg.PickupInterval = -time.Second
+	cfg.CoinPattern = "rain"
+	cfg.MaxCoins = 0
+	cfg.CoinLifetime = -time.Second
+	cfg.AdminToken = "short"
+	cfg.LogLevel = "loud"
+	cfg.LogFormat = "xml"
+	cfg.AttackActive = 0
+	cfg.AttackCooldown = time.Minute
+	cfg.Map = "nowhere"
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{"allowed origin", "ping_period", "player_slots", "crowd_size", "pickup_interval", "coin_pattern", "max_coins", "coin_lifetime", "admin_token", "log_level", "log_format", "attack_active", "attack_cooldown", "map"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("%q missing from %v", want, err)
+		}
+	}
+}
+
+func TestAttackTiming(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TickRate = 20
+	cfg.AttackWindUp = 120 * time.Millisecond
+	cfg.AttackActive = 10 * time.Millisecond
+	cfg.AttackRecovery = 0
+	cfg.AttackCooldown = time.Second
+	want := common.AttackTiming{WindUp: 2, Active: 1, Recovery: 0, Cooldown: 20}
+	if got := cfg.attackTiming(); got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"net"
+	"os"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+// Announce sends an announcement describing the rooms to dest every
+// interval until ctx is done. Call it in a goroutine.
+func (rs *Rooms) Announce(ctx context.Context, conn net.PacketConn, dest net.Addr, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		data, err := common.MarshalAnnouncement(rs.announcement())
+		if err != nil {
+			rs.log.Error("announce: marshaling error", "err", err)
+		} else if _, err := conn.WriteTo(data, dest); err != nil {
+			rs.log.Warn("announce failed", "err", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (rs *Rooms) announcement() *pb.Announcement {
+	name := rs.cfg.Name
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	port, _ := rs.cfg.port()
+	a := &pb.Announcement{
+		Version:  common.Version,
+		Name:     name,
+		Port:     int32(port),
+		Secure:   !rs.cfg.Insecure,
+		MaxRooms: int32(rs.cfg.MaxRooms),
+	}
+	for _, info := range rs.Info() {
+		a.Rooms = append(a.Rooms, &pb.RoomInfo{
+			Id:      int32(info.ID),
+			Players: int32(info.Players),
+			Slots:   int32(info.Slots),
+			Running: info.Running,
+		})
+	}
+	return a
+}
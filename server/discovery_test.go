@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+func TestAnnounceLoopback(t *testing.T) {
+	listener, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sender.Close()
+
+	cfg := DefaultConfig()
+	cfg.Addr = ":9000"
+	cfg.Name = "test"
+	cfg.Insecure = true
+	rs := NewRooms(cfg)
+	c := &Client{clientSlot: -1, Send: make(chan []byte, 256), cfg: cfg, log: rs.log}
+	if !rs.join(c) {
+		t.Fatal("join failed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go rs.Announce(ctx, sender, listener.LocalAddr(), 10*time.Millisecond)
+
+	found := make(chan *pb.Announcement, 1)
+	go common.ListenAnnouncements(ctx, listener, func(from net.Addr, a *pb.Announcement) {
+		select {
+		case found <- a:
+		default:
+		}
+	})
+	select {
+	case a := <-found:
+		if a.Name != "test" || a.Port != 9000 || a.Secure {
+			t.Errorf("unexpected announcement %v", a)
+		}
+		if len(a.Rooms) != 1 || a.Rooms[0].Players != 1 || a.Rooms[0].Slots != int32(cfg.PlayerSlots) {
+			t.Errorf("unexpected rooms %v", a.Rooms)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no announcement received")
+	}
+}
+
+func TestParseAnnouncementRejectsNoise(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("hello"), []byte("OOT1\xff\xff")} {
+		if _, err := common.ParseAnnouncement(data); err == nil {
+			t.Errorf("%q: expected error", data)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Reasons a match ended.
+const (
+	EndLastSurvivor = "last_survivor"
+	EndTimeout      = "timeout"
+	EndAdmin        = "admin"
+	EndShutdown     = "shutdown"
+)
+
+// MatchRecord is the result of a finished match.
+type MatchRecord struct {
+	ID       string    `json:"id"`
+	Room     int       `json:"room"`
+	Mode     string    `json:"mode"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Duration float64   `json:"duration_seconds"`
+	Reason   string    `json:"reason"`
+	// Slot of the winning player, or -1 for a draw.
+	Winner  int32          `json:"winner"`
+	Players []PlayerResult `json:"players"`
+}
+
+// PlayerResult is how one participant did in a match.
+type PlayerResult struct {
+	Slot  int32  `json:"slot"`
+	Name  string `json:"name,omitempty"`
+	Score int32  `json:"score"`
+	Kills int32  `json:"kills"`
+	// Alive at the end of the match.
+	Alive bool `json:"alive"`
+}
+
+// record describes the match that just ended.
+func (w *World) record(room int) MatchRecord {
+	rec := MatchRecord{
+		ID:       w.ID,
+		Room:     room,
+		Mode:     w.mode.Name,
+		Start:    w.startTime,
+		End:      w.endTime,
+		Duration: w.endTime.Sub(w.startTime).Seconds(),
+		Reason:   w.endReason,
+		Winner:   -1,
+		Players:  []PlayerResult{},
+	}
+	for _, slot := range w.participants {
+		rec.Players = append(rec.Players, PlayerResult{
+			Slot:  slot,
+			Name:  w.Names[slot],
+			Score: w.Score[slot],
+			Kills: w.Kills[slot],
+			Alive: !w.Chars[slot].IsDead,
+		})
+	}
+	if w.endReason == EndLastSurvivor {
+		rec.Winner = w.survivor
+		return rec
+	}
+	// otherwise the best score wins, if nobody ties it
+	var best int32
+	for _, p := range rec.Players {
+		switch {
+		case p.Score > best:
+			best = p.Score
+			rec.Winner = p.Slot
+		case p.Score == best:
+			rec.Winner = -1
+		}
+	}
+	return rec
+}
+
+// History keeps finished matches in memory and, when opened with a path,
+// appends each one to a JSON Lines file so it survives restarts.
+type History struct {
+	mu      sync.Mutex
+	file    *os.File
+	matches []MatchRecord
+}
+
+// NewHistory returns a history that is only kept in memory.
+func NewHistory() *History {
+	return &History{}
+}
+
+// OpenHistory loads the matches saved at path and appends new ones to it,
+// creating the file if needed. Lines that cannot be parsed, such as one cut
+// short by a crash, are skipped.
+func OpenHistory(path string) (*History, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	h := &History{file: f}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		var rec MatchRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			slog.Warn("skipping match history line", "path", path, "line", line, "err", err)
+			continue
+		}
+		h.matches = append(h.matches, rec)
+	}
+	if err := sc.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return h, nil
+}
+
+// Add saves a finished match.
+func (h *History) Add(rec MatchRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.matches = append(h.matches, rec)
+	if h.file == nil {
+		return nil
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = h.file.Write(append(data, '\n'))
+	return err
+}
+
+// Recent returns up to limit matches, newest first.
+func (h *History) Recent(limit int) []MatchRecord {
+	return h.find(limit, func(MatchRecord) bool { return true })
+}
+
+// Player returns up to limit matches that name took part in, newest first.
+func (h *History) Player(name string, limit int) []MatchRecord {
+	return h.find(limit, func(rec MatchRecord) bool {
+		for _, p := range rec.Players {
+			if p.Name == name {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// All returns every match, oldest first.
+func (h *History) All() []MatchRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]MatchRecord(nil), h.matches...)
+}
+
+func (h *History) find(limit int, match func(MatchRecord) bool) []MatchRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	found := []MatchRecord{}
+	for i := len(h.matches) - 1; i >= 0 && len(found) < limit; i-- {
+		if match(h.matches[i]) {
+			found = append(found, h.matches[i])
+		}
+	}
+	return found
+}
+
+func (h *History) Close() error {
+	if h.file == nil {
+		return nil
+	}
+	return h.file.Close()
+}
+
+const (
+	defaultMatchLimit = 20
+	maxMatchLimit     = 100
+)
+
+// ServeMatches lists recent matches as JSON, newest first. With a player
+// query parameter only that player's matches are listed; limit caps the
+// number returned.
+func (rs *Rooms) ServeMatches(w http.ResponseWriter, r *http.Request) {
+	rs.allowCORS(w, r)
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if player := r.URL.Query().Get("player"); player != "" {
+		writeJSON(w, rs.history.Player(player, limit))
+		return
+	}
+	writeJSON(w, rs.history.Recent(limit))
+}
+
+func parseLimit(s string) (int, error) {
+	if s == "" {
+		return defaultMatchLimit, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, errors.New("limit must be a positive number")
+	}
+	if n > maxMatchLimit {
+		n = maxMatchLimit
+	}
+	return n, nil
+}
+
+// recordBuffer is how many finished matches can wait to be saved before
+// recordMatch blocks.
+const recordBuffer = 64
+
+// recordMatch queues a finished match to be saved and its players rated.
+// It runs on the hub goroutine, so the file write happens elsewhere.
+func (rs *Rooms) recordMatch(rec MatchRecord) {
+	rs.records <- rec
+}
+
+// saveMatches saves queued matches until Shutdown closes the queue.
+func (rs *Rooms) saveMatches() {
+	defer close(rs.saved)
+	for rec := range rs.records {
+		rs.saveMatch(rec)
+	}
+}
+
+// saveMatch stores a finished match and rates its players.
+func (rs *Rooms) saveMatch(rec MatchRecord) {
+	rs.ratings.Update(rec)
+	if err := rs.history.Add(rec); err != nil {
+		rs.log.Error("saving match", "match", rec.ID, "err", err)
+	}
+}
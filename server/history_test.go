@@ -0,0 +1,244 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+func testRecord(id string, names ...string) MatchRecord {
+	rec := MatchRecord{ID: id, Mode: "classic", Winner: -1}
+	for i, name := range names {
+		rec.Players = append(rec.Players, PlayerResult{Slot: int32(i), Name: name})
+	}
+	return rec
+}
+
+func recordIDs(recs []MatchRecord) []string {
+	ids := []string{}
+	for _, r := range recs {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestHistoryQueries(t *testing.T) {
+	h := NewHistory()
+	h.Add(testRecord("a", "alice", "bob"))
+	h.Add(testRecord("b", "bob"))
+	h.Add(testRecord("c", "carol", "alice"))
+
+	if got := recordIDs(h.Recent(2)); len(got) != 2 || got[0] != "c" || got[1] != "b" {
+		t.Errorf("Recent(2) = %v, want [c b]", got)
+	}
+	if got := recordIDs(h.Player("alice", 10)); len(got) != 2 || got[0] != "c" || got[1] != "a" {
+		t.Errorf("Player(alice) = %v, want [c a]", got)
+	}
+	if got := h.Player("dave", 10); len(got) != 0 {
+		t.Errorf("Player(dave) = %v, want none", got)
+	}
+}
+
+func TestHistoryFileSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "matches.jsonl")
+	h, err := OpenHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	rec := testRecord("a", "alice")
+	rec.Start, rec.End = start, start.Add(time.Minute)
+	if err := h.Add(rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Add(testRecord("b", "bob")); err != nil {
+		t.Fatal(err)
+	}
+	h.Close()
+
+	// a line cut short by a crash is skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id":"c","pla`)
+	f.Close()
+
+	h, err = OpenHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	all := h.All()
+	if got := recordIDs(all); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("reloaded %v, want [a b]", got)
+	}
+	if !all[0].Start.Equal(start) || all[0].Players[0].Name != "alice" {
+		t.Fatalf("reloaded %+v", all[0])
+	}
+}
+
+func TestRecordWinner(t *testing.T) {
+	w, clock, _ := newTestWorld(t, DefaultConfig(), 0, 1, 2)
+	w.Names[0] = "alice"
+	w.Score[0], w.Score[1] = 2, 3
+	w.Kills[1] = 1
+	w.Chars[2].IsDead = true
+	clock.Advance(time.Minute)
+	w.end(EndAdmin)
+
+	rec := w.record(4)
+	if rec.ID != w.ID || rec.Room != 4 || rec.Mode != "classic" || rec.Reason != EndAdmin {
+		t.Fatalf("unexpected record %+v", rec)
+	}
+	if rec.Duration != 60 {
+		t.Errorf("duration %v, want 60", rec.Duration)
+	}
+	if rec.Winner != 1 {
+		t.Errorf("winner %d, want the top scorer 1", rec.Winner)
+	}
+	if len(rec.Players) != 3 {
+		t.Fatalf("got %d players, want 3", len(rec.Players))
+	}
+	if p := rec.Players[0]; p.Name != "alice" || p.Score != 2 || !p.Alive {
+		t.Errorf("player 0: %+v", p)
+	}
+	if p := rec.Players[1]; p.Kills != 1 {
+		t.Errorf("player 1: %+v", p)
+	}
+	if p := rec.Players[2]; p.Alive {
+		t.Errorf("player 2: %+v", p)
+	}
+
+	w.Score[0] = 3
+	if rec := w.record(4); rec.Winner != -1 {
+		t.Errorf("tied match won by %d", rec.Winner)
+	}
+}
+
+func TestRecordLastSurvivor(t *testing.T) {
+	w, _, _ := newTestWorld(t, DefaultConfig(), 0, 3)
+	placeApart(w)
+	w.Score[0] = 5
+	w.Chars[0].IsDead = true
+	w.update()
+
+	rec := w.record(1)
+	if rec.Reason != EndLastSurvivor || rec.Winner != 3 {
+		t.Fatalf("got %s won by %d, want last_survivor won by 3", rec.Reason, rec.Winner)
+	}
+}
+
+func getMatches(t *testing.T, rs *Rooms, query string) ([]MatchRecord, int) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	rs.ServeMatches(rec, httptest.NewRequest(http.MethodGet, "/matches"+query, nil))
+	if rec.Code != http.StatusOK {
+		return nil, rec.Code
+	}
+	var recs []MatchRecord
+	if err := json.Unmarshal(rec.Body.Bytes(), &recs); err != nil {
+		t.Fatal(err)
+	}
+	return recs, rec.Code
+}
+
+func TestServeMatches(t *testing.T) {
+	rs := NewRooms(DefaultConfig())
+	for i := 0; i < maxMatchLimit+5; i++ {
+		rs.history.Add(testRecord("m", "bob"))
+	}
+	rs.history.Add(testRecord("last", "alice"))
+
+	recs, _ := getMatches(t, rs, "")
+	if len(recs) != defaultMatchLimit || recs[0].ID != "last" {
+		t.Errorf("got %d matches starting %v", len(recs), recordIDs(recs[:1]))
+	}
+	if recs, _ := getMatches(t, rs, "?limit=1000"); len(recs) != maxMatchLimit {
+		t.Errorf("limit=1000 returned %d", len(recs))
+	}
+	if recs, _ := getMatches(t, rs, "?player=alice"); len(recs) != 1 || recs[0].ID != "last" {
+		t.Errorf("player=alice returned %v", recordIDs(recs))
+	}
+	if recs, _ := getMatches(t, rs, "?player=nobody"); recs == nil || len(recs) != 0 {
+		t.Errorf("player=nobody returned %v, want an empty list", recs)
+	}
+	if _, code := getMatches(t, rs, "?limit=-1"); code != http.StatusBadRequest {
+		t.Errorf("limit=-1 got status %d", code)
+	}
+}
+
+func TestFinishedMatchIsRecorded(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PlayerSlots = 2
+	cfg.MaxRooms = 1
+	clock := newFakeClock()
+	rs := NewRooms(cfg)
+	rs.clock = clock
+
+	host := connectNamed(t, rs, "  alice<> ")
+	host.expect("connect response", isConnectResponse)
+	guest := connectNamed(t, rs, "bob")
+	guest.expect("connect response", isConnectResponse)
+
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_StartGame{}})
+	host.expect("game start", func(m *pb.ServerMessage) bool {
+		return m.GetGameStart() != nil
+	})
+	rs.hubs[0].do(func() {})
+	clock.Advance(cfg.MatchLength + time.Second)
+	host.expect("game end", func(m *pb.ServerMessage) bool {
+		return m.GetGameEnd() != nil
+	})
+	// shutting down waits for the match to be saved
+	if err := <-startShutdown(rs, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, _ := getMatches(t, rs, "?player=alice")
+	if len(recs) != 1 {
+		t.Fatalf("got %d matches for alice, want 1", len(recs))
+	}
+	rec := recs[0]
+	if rec.Reason != EndTimeout || rec.Room != 1 || rec.ID == "" {
+		t.Fatalf("unexpected record %+v", rec)
+	}
+	if len(rec.Players) != 2 || rec.Players[0].Name != "alice" || rec.Players[1].Name != "bob" {
+		t.Fatalf("unexpected players %+v", rec.Players)
+	}
+}
+
+func TestRecordMatchDoesNotWaitForDisk(t *testing.T) {
+	rs := NewRooms(DefaultConfig())
+	// a slow disk holds up saving
+	rs.history.mu.Lock()
+	for i := 0; i < recordBuffer; i++ {
+		done := make(chan struct{})
+		go func() {
+			rs.recordMatch(testRecord("m", "alice", "bob"))
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("match %d waited for the history", i)
+		}
+	}
+	rs.history.mu.Unlock()
+
+	if err := <-startShutdown(rs, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rs.history.All()); n != recordBuffer {
+		t.Fatalf("saved %d matches, want %d", n, recordBuffer)
+	}
+	if got := ratingOf(t, rs.ratings, "alice"); got.Matches != recordBuffer {
+		t.Fatalf("alice rated %+v", got)
+	}
+}
@@ -1,245 +1,527 @@
 package server
 
 import (
-	"log"
-	"net/http"
+	"log/slog"
+	"math"
+	"time"
 
 	"github.com/kisunji/ebiten-poc/common"
 	"github.com/kisunji/ebiten-poc/pb"
+	"github.com/kisunji/ebiten-poc/transport"
 	"google.golang.org/protobuf/proto"
 )
 
 type Hub struct {
+	// Room number, used in logs.
+	ID  int
+	cfg Config
 	// game engine
 	world *World
 	// Registered clients.
 	clients map[*Client]int32
 	// Inbound messages from the clients.
 	clientData chan clientData
-	// register requests from the clients.
-	register chan *Client
+	// Functions to run on the hub goroutine.
+	calls chan func()
 	// Unregister requests from clients.
 	unregister chan *Client
-	// broadcast messages to all clients
-	broadcast chan []byte
 	// Inputs from AI.
-	AIChan    chan AIData
-	isRunning bool
+	AIChan  chan AIData
+	clock   Clock
+	metrics *Metrics
+	// Logs with the room ID attached.
+	log *slog.Logger
+	// Drives world updates while a match is running.
+	ticker Ticker
+	// Results of decoding client messages.
+	decodeStats common.DecodeStats
+	// Set once the server is shutting down. The room closes as soon as no
+	// match is running.
+	draining bool
+	// Set to stop Run.
+	closed bool
+	// Closed when Run returns.
+	done chan struct{}
+	// Called on the hub goroutine with the result of every finished match.
+	onMatchEnd func(MatchRecord)
+	// Map the next match is played on, picked by the host.
+	mapName string
+	// Size of the room, picked by the host. Kept until the room empties.
+	playerSlots, crowdSize int
 }
 
-// Create new chat hub.
-func NewHub() *Hub {
-	broadcast := make(chan []byte)
-	return &Hub{
-		world:      NewWorld(broadcast),
+// Create new room hub.
+func NewHub(id int, cfg Config) *Hub {
+	return newHub(id, cfg, realClock{}, NewMetrics(), slog.Default())
+}
+
+func newHub(id int, cfg Config, clock Clock, metrics *Metrics, log *slog.Logger) *Hub {
+	h := &Hub{
+		ID:         id,
+		cfg:        cfg,
 		clients:    make(map[*Client]int32),
 		clientData: make(chan clientData),
-		register:   make(chan *Client),
+		calls:      make(chan func()),
 		unregister: make(chan *Client),
 		AIChan:     make(chan AIData),
-		broadcast:  broadcast,
+		clock:      clock,
+		metrics:    metrics,
+		log:        log.With("room", id),
+		done:       make(chan struct{}),
+		mapName:    cfg.Map,
 	}
+	h.playerSlots, h.crowdSize = cfg.PlayerSlots, cfg.CrowdSize
+	h.world = h.newWorld()
+	return h
 }
 
-func (h *Hub) Run() {
-	h.isRunning = true
-	for {
-		select {
-		case client := <-h.register:
-			clientSlot := h.getNextFreeClientSlot()
-			if clientSlot < 0 {
-				resp := &pb.ServerMessage{
-					Content: &pb.ServerMessage_ConnectError{
-						ConnectError: &pb.ConnectError{
-							Message: "all slots are full",
-						},
-					},
-				}
-				data, err := proto.Marshal(resp)
-				if err != nil {
-					log.Fatalln("client connect: marshaling error: ", err)
-				}
-				client.Send <- data
-				return
-			}
-			// state check (running already?)
-			if h.world.Running {
-				resp := &pb.ServerMessage{
-					Content: &pb.ServerMessage_ConnectError{
-						ConnectError: &pb.ConnectError{
-							Message: "game is already running",
-						},
-					},
-				}
-				data, err := proto.Marshal(resp)
-				if err != nil {
-					log.Fatalln("client connect: marshaling error: ", err)
-				}
-				client.Send <- data
-				return
-			}
-			log.Printf("player %d connected\n", clientSlot)
-			client.clientSlot = clientSlot
-			if len(h.clients) == 0 {
-				h.world.HostSlot = clientSlot
-			}
-			h.world.PlayerSlots[clientSlot] = true
-			h.clients[client] = clientSlot
-
-			resp := &pb.ServerMessage{
-				Content: &pb.ServerMessage_ConnectResponse{
-					ConnectResponse: &pb.ConnectResponse{
-						ClientSlot: client.clientSlot,
-						IsHost:     h.world.HostSlot == clientSlot,
-					},
-				},
-			}
-			data, err := proto.Marshal(resp)
-			if err != nil {
-				log.Fatalln("client connect: marshaling error: ", err)
-			}
-			client.Send <- data
+func (h *Hub) newWorld() *World {
+	cfg := h.cfg
+	cfg.PlayerSlots, cfg.CrowdSize = h.playerSlots, h.crowdSize
+	w := NewWorld(cfg, h.clock, h.metrics, h.log, h.sendToAll)
+	w.sendTo = h.sendToSlot
+	return w
+}
 
-			resp = &pb.ServerMessage{
-				Content: &pb.ServerMessage_UpdateLobby{
-					UpdateLobby: &pb.UpdateLobby{
-						ConnectedSlots: h.world.PlayerSlots,
-						HostSlot:       h.world.HostSlot,
-					},
-				},
+// do runs f on the hub goroutine and waits for it to finish. Hub and World
+// state must only be touched from there. f is not run if the hub has
+// stopped.
+func (h *Hub) do(f func()) {
+	done := make(chan struct{})
+	select {
+	case h.calls <- func() {
+		f()
+		close(done)
+	}:
+	case <-h.done:
+		return
+	}
+	<-done
+}
+
+// Join registers client with the hub if the room has a free slot and
+// no game is running. It reports whether the client was accepted.
+func (h *Hub) Join(client *Client) bool {
+	var ok bool
+	h.do(func() {
+		ok = h.join(client)
+	})
+	return ok
+}
+
+// RoomInfo summarizes a hub for listings.
+type RoomInfo struct {
+	ID      int  `json:"id"`
+	Players int  `json:"players"`
+	Slots   int  `json:"slots"`
+	Running bool `json:"running"`
+	// Taken player slots.
+	Connected []int32 `json:"connected"`
+	HostSlot  int32   `json:"host_slot"`
+	// Map the next match is played on.
+	Map string `json:"map"`
+	// Characters in a match, players included.
+	Crowd int `json:"crowd"`
+}
+
+// Info returns a snapshot of the room.
+func (h *Hub) Info() RoomInfo {
+	var info RoomInfo
+	h.do(func() {
+		info = RoomInfo{
+			ID:        h.ID,
+			Players:   len(h.clients),
+			Slots:     h.playerSlots,
+			Running:   h.world.Running,
+			Map:       h.mapName,
+			Crowd:     h.crowdSize,
+			Connected: []int32{},
+			HostSlot:  h.world.HostSlot,
+		}
+		for i, p := range h.world.PlayerSlots {
+			if p {
+				info.Connected = append(info.Connected, int32(i))
 			}
-			h.sendToAll(resp)
+		}
+	})
+	return info
+}
+
+func (h *Hub) join(client *Client) bool {
+	if h.world.Running || h.draining {
+		return false
+	}
+	clientSlot := h.getNextFreeClientSlot()
+	if clientSlot < 0 {
+		return false
+	}
+	client.Hub = h
+	client.clientSlot = clientSlot
+	client.log = client.log.With("room", h.ID, "slot", clientSlot)
+	client.log.Info("player connected")
+	if len(h.clients) == 0 {
+		h.world.HostSlot = clientSlot
+	}
+	h.world.PlayerSlots[clientSlot] = true
+	h.clients[client] = clientSlot
+
+	h.sendTo(client, &pb.ServerMessage{
+		Content: &pb.ServerMessage_ConnectResponse{
+			ConnectResponse: &pb.ConnectResponse{
+				ClientSlot: client.clientSlot,
+				IsHost:     h.world.HostSlot == clientSlot,
+			},
+		},
+	})
+
+	h.sendLobby()
+	return true
+}
+
+// sendLobby tells every client who is in the room, how big it is and
+// which map is picked.
+func (h *Hub) sendLobby() {
+	h.sendToAll(&pb.ServerMessage{
+		Content: &pb.ServerMessage_UpdateLobby{
+			UpdateLobby: &pb.UpdateLobby{
+				ConnectedSlots: h.world.PlayerSlots,
+				HostSlot:       h.world.HostSlot,
+				Map:            h.mapName,
+				Maps:           common.ArenaNames(),
+				Crowd:          int32(h.crowdSize),
+			},
+		},
+	})
+}
+
+func (h *Hub) Run() {
+	defer close(h.done)
+	for !h.closed {
+		var tick <-chan time.Time
+		if h.ticker != nil {
+			tick = h.ticker.C()
+		}
+		select {
+		case f := <-h.calls:
+			f()
 		case client := <-h.unregister:
-			h.disconnect(client)
+			// also closes Send so WritePump returns
+			h.kick(client, transport.StatusNormalClosure, "")
 		case clientMsg := <-h.clientData:
-			msg := &pb.ClientMessage{}
-			err := proto.Unmarshal(clientMsg.data, msg)
-			if err != nil {
-				log.Println("error unmarshalling from client")
-			}
-			switch buf := msg.Content.(type) {
-			case *pb.ClientMessage_Input:
-				char := h.world.Chars[clientMsg.client.clientSlot]
-				char.ProcessInput(buf.Input)
-
-				resp := &pb.ServerMessage{
-					Content: &pb.ServerMessage_UpdateEntity{
-						UpdateEntity: &pb.UpdateEntity{
-							Index:       clientMsg.client.clientSlot,
-							Fx:          int32(char.Fx),
-							Fy:          int32(char.Fy),
-							Vx:          int32(char.Vx),
-							Vy:          int32(char.Vy),
-							Px:          char.Px,
-							Py:          char.Py,
-							Speed:       int32(char.Speed),
-							AttackFrame: int32(char.AttackFrame),
-							IsDead:      char.IsDead,
-						},
-					},
-				}
-				h.sendToAll(resp)
-			case *pb.ClientMessage_StartGame:
-				log.Println("starting!")
-				msg := &pb.ServerMessage{
-					Content: &pb.ServerMessage_GameStart{
-						GameStart: &pb.GameStart{},
-					},
-				}
-				h.sendToAll(msg)
-				if !h.world.Running {
-					h.world.Setup(h.AIChan)
-					go h.world.Run()
-				}
-			case *pb.ClientMessage_WorldUpdate:
-				updateAll := &pb.UpdateEntities{}
-				for i, char := range h.world.Chars {
-					if char == nil {
-						continue
-					}
-					ue := &pb.UpdateEntity{
-						Index:       int32(i),
-						Fx:          int32(char.Fx),
-						Fy:          int32(char.Fy),
-						Vx:          int32(char.Vx),
-						Vy:          int32(char.Vy),
-						Px:          char.Px,
-						Py:          char.Py,
-						Speed:       int32(char.Speed),
-						AttackFrame: int32(char.AttackFrame),
-						IsDead:      char.IsDead,
-					}
-					updateAll.UpdateEntity = append(updateAll.UpdateEntity, ue)
-				}
-				h.sendToAll(&pb.ServerMessage{
-					Content: &pb.ServerMessage_UpdateEntities{
-						UpdateEntities: updateAll,
-					},
-				})
-				ts := &pb.ServerMessage{
-					Content: &pb.ServerMessage_TimeSync{
-						TimeSync: &pb.TimeSync{
-							StartTime: h.world.startTime.Unix(),
-							Duration:  int32(h.world.duration.Minutes()),
-						},
-					},
-				}
-				h.sendToAll(ts)
-			default:
-				h.disconnect(clientMsg.client)
-			}
+			h.handleClientData(clientMsg)
 		case aiInput := <-h.AIChan:
-			char := h.world.Chars[aiInput.Id]
-			input := &pb.Input{
-				UpPressed:    aiInput.UpPressed,
-				DownPressed:  aiInput.DownPressed,
-				LeftPressed:  aiInput.LeftPressed,
-				RightPressed: aiInput.RightPressed,
-			}
-			if char == nil {
-				char = common.NewChar()
-				h.world.Chars[aiInput.Id] = char
+			h.handleAI(aiInput)
+		case <-tick:
+			h.step()
+		case <-h.world.coinDue:
+			h.world.spawnCoins()
+		case <-h.world.pickupDue:
+			h.world.spawnPickup()
+		}
+	}
+}
+
+// step advances the world by one tick.
+func (h *Hub) step() {
+	start := time.Now()
+	h.world.update()
+	h.metrics.TickDuration.Observe(time.Since(start).Seconds())
+	if !h.world.Running {
+		h.matchOver()
+		if h.draining {
+			h.shutdown()
+		}
+	}
+}
+
+// Drain tells players that the server stops at deadline and closes the
+// room as soon as no match is running. A zero deadline sends no countdown.
+func (h *Hub) Drain(deadline time.Time) {
+	h.do(func() {
+		h.draining = true
+		var countdown int32
+		if !deadline.IsZero() {
+			countdown = int32(math.Ceil(deadline.Sub(h.clock.Now()).Seconds()))
+		}
+		h.sendToAll(&pb.ServerMessage{
+			Content: &pb.ServerMessage_ServerNotice{
+				ServerNotice: &pb.ServerNotice{
+					Message:   "server is shutting down",
+					Countdown: countdown,
+				},
+			},
+		})
+		if !h.world.Running {
+			h.shutdown()
+		}
+	})
+}
+
+// Close ends any running match and closes the room.
+func (h *Hub) Close() {
+	h.do(func() {
+		if h.world.Running {
+			h.world.end(EndShutdown)
+			h.matchOver()
+		}
+		h.shutdown()
+	})
+}
+
+// Done is closed once the hub has stopped.
+func (h *Hub) Done() <-chan struct{} {
+	return h.done
+}
+
+// shutdown hangs up on every client, waits for the world's goroutines and
+// stops Run.
+func (h *Hub) shutdown() {
+	for c := range h.clients {
+		delete(h.clients, c)
+		c.closeCode = transport.StatusGoingAway
+		c.closeReason = "server shutting down"
+		close(c.Send)
+	}
+	h.stopTicker()
+	h.world.stop()
+	h.world.wait()
+	h.closed = true
+	h.log.Info("room closed")
+}
+
+// matchOver is called once the world has ended a match with a result.
+func (h *Hub) matchOver() {
+	h.metrics.MatchesCompleted.Inc()
+	h.stopTicker()
+	if h.onMatchEnd != nil {
+		h.onMatchEnd(h.world.record(h.ID))
+	}
+	// the next match starts from a fresh world with the same players
+	h.world.wait()
+	next := h.newWorld()
+	copy(next.PlayerSlots, h.world.PlayerSlots)
+	next.HostSlot = h.world.HostSlot
+	h.world = next
+	h.mapName = h.cfg.Map
+}
+
+func (h *Hub) stopTicker() {
+	if h.ticker != nil {
+		h.ticker.Stop()
+		h.ticker = nil
+	}
+}
+
+func (h *Hub) startGame() {
+	if h.world.Running {
+		return
+	}
+	h.log.Info("starting match")
+	for c, slot := range h.clients {
+		h.world.Names[slot] = c.name
+	}
+	h.sendToAll(&pb.ServerMessage{
+		Content: &pb.ServerMessage_GameStart{
+			GameStart: &pb.GameStart{
+				Map:     h.mapName,
+				Players: int32(len(h.world.PlayerSlots)),
+				Crowd:   int32(len(h.world.Chars)),
+			},
+		},
+	})
+	h.world.arena = common.Arenas[h.mapName]
+	h.world.Setup(h.AIChan)
+	h.ticker = h.clock.NewTicker(h.world.tickLength)
+}
+
+// selectMap switches the next match to the named map if c is the host and
+// the map exists.
+func (h *Hub) selectMap(c *Client, name string) {
+	if h.world.Running || c.clientSlot != h.world.HostSlot {
+		return
+	}
+	if _, ok := common.Arenas[name]; !ok {
+		h.clientLog(c).Warn("unknown map", "map", name)
+		return
+	}
+	h.mapName = name
+	h.sendLobby()
+}
+
+// setCapacity resizes the room if c is the host and the size can be
+// played with everyone already in it.
+func (h *Hub) setCapacity(c *Client, players, crowd int) {
+	if h.world.Running || c.clientSlot != h.world.HostSlot {
+		return
+	}
+	if problems := capacityProblems(players, crowd); len(problems) > 0 {
+		h.clientLog(c).Warn("bad room size", "players", players, "crowd", crowd, "problems", problems)
+		return
+	}
+	for i := players; i < len(h.world.PlayerSlots); i++ {
+		if h.world.PlayerSlots[i] {
+			h.clientLog(c).Warn("room size leaves out a player", "players", players, "slot", i)
+			return
+		}
+	}
+	h.playerSlots, h.crowdSize = players, crowd
+	next := h.newWorld()
+	copy(next.PlayerSlots, h.world.PlayerSlots)
+	next.HostSlot = h.world.HostSlot
+	h.world = next
+	h.log.Info("room resized", "players", players, "crowd", crowd)
+	h.sendLobby()
+}
+
+func (h *Hub) handleAI(aiInput AIData) {
+	char := h.world.Chars[aiInput.Id]
+	if char == nil || char.IsDead || !h.world.Running {
+		// left over from a finished match, killed by a player or a decoy
+		// that is not out
+		return
+	}
+	input := &pb.Input{}
+	if aiInput.Walk {
+		input = nextMovement(char, h.world.arena)
+	}
+	char.ProcessInput(input)
+	h.world.sendEntity(int(aiInput.Id))
+}
+
+// handleClientData processes a single message from a registered client.
+func (h *Hub) handleClientData(clientMsg clientData) {
+	if _, ok := h.clients[clientMsg.client]; !ok {
+		// not registered or already dropped
+		return
+	}
+	msg, err := common.DecodeClientMessage(clientMsg.data)
+	h.decodeStats.Record(err)
+	if err != nil {
+		h.clientLog(clientMsg.client).Warn("dropping connection", "err", err)
+		h.kick(clientMsg.client, transport.StatusUnsupportedData, "malformed message")
+		return
+	}
+	h.metrics.MessagesIn.Inc(messageType(msg))
+	switch buf := msg.Content.(type) {
+	case *pb.ClientMessage_Input:
+		char := h.world.Chars[clientMsg.client.clientSlot]
+		if char == nil {
+			// game has not started yet
+			return
+		}
+		if h.world.stunned(clientMsg.client.clientSlot) {
+			// applied once the stun wears off
+			h.world.heldInput[clientMsg.client.clientSlot] = buf.Input
+			return
+		}
+		char.ProcessInput(buf.Input)
+		h.world.sendEntity(int(clientMsg.client.clientSlot))
+	case *pb.ClientMessage_StartGame:
+		h.startGame()
+	case *pb.ClientMessage_SelectMap:
+		h.selectMap(clientMsg.client, buf.SelectMap.Name)
+	case *pb.ClientMessage_SetCapacity:
+		h.setCapacity(clientMsg.client, int(buf.SetCapacity.Players), int(buf.SetCapacity.Crowd))
+	case *pb.ClientMessage_WorldUpdate:
+		// only what the requesting player can see
+		updateAll := &pb.UpdateEntities{}
+		for i, char := range h.world.Chars {
+			if char == nil || !h.world.visibleTo(clientMsg.client.clientSlot, i) {
+				continue
 			}
-			char.ProcessInput(input)
-			resp := &pb.ServerMessage{
-				Content: &pb.ServerMessage_UpdateEntity{
-					UpdateEntity: &pb.UpdateEntity{
-						Index: aiInput.Id,
-						Fx:    int32(char.Fx),
-						Fy:    int32(char.Fy),
-						Vx:    int32(char.Vx),
-						Vy:    int32(char.Vy),
-						Px:    char.Px,
-						Py:    char.Py,
-						Speed: int32(char.Speed),
+			updateAll.UpdateEntity = append(updateAll.UpdateEntity, entityUpdate(i, char).GetUpdateEntity())
+		}
+		h.sendTo(clientMsg.client, &pb.ServerMessage{
+			Content: &pb.ServerMessage_UpdateEntities{
+				UpdateEntities: updateAll,
+			},
+		})
+		ts := &pb.ServerMessage{
+			Content: &pb.ServerMessage_TimeSync{
+				TimeSync: &pb.TimeSync{
+					StartTime: h.world.startTime.Unix(),
+					Duration:  int32(h.world.duration.Minutes()),
+					AttackTiming: &pb.AttackTiming{
+						WindUp:   int32(h.world.timing.WindUp),
+						Active:   int32(h.world.timing.Active),
+						Recovery: int32(h.world.timing.Recovery),
+						Cooldown: int32(h.world.timing.Cooldown),
 					},
+					Vision: h.world.mode.Vision,
 				},
-			}
-			h.sendToAll(resp)
-		case data, ok := <-h.broadcast:
-			if ok {
-				for c := range h.clients {
-					c.Send <- data
-				}
-			}
+			},
+		}
+		h.sendTo(clientMsg.client, ts)
+	}
+}
+
+// sendTo queues msg for one client.
+func (h *Hub) sendTo(c *Client, msg *pb.ServerMessage) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		h.log.Error("marshaling message", "type", messageType(msg), "err", err)
+		return
+	}
+	h.queue(c, data, messageType(msg))
+}
+
+// sendToSlot queues msg for the client in a player slot, if there is one.
+func (h *Hub) sendToSlot(slot int32, msg *pb.ServerMessage) {
+	for c, s := range h.clients {
+		if s == slot {
+			h.sendTo(c, msg)
+			return
 		}
 	}
 }
 
 func (h *Hub) sendToAll(msg *pb.ServerMessage) {
+	typ := messageType(msg)
 	data, err := proto.Marshal(msg)
 	if err != nil {
-		log.Println("client connect: marshaling error: ", err)
+		h.log.Error("marshaling message", "type", typ, "err", err)
+		return
 	}
 	for c := range h.clients {
-		c.Send <- data
+		h.queue(c, data, typ)
+	}
+}
+
+// queue adds data to a client's send buffer, dropping it if the client has
+// fallen too far behind rather than stalling the room.
+func (h *Hub) queue(c *Client, data []byte, typ string) {
+	select {
+	case c.Send <- data:
+		h.metrics.MessagesOut.Inc(typ)
+	default:
+		h.metrics.SendDrops.Inc()
+		h.clientLog(c).Warn("send buffer full, dropping message", "type", typ)
 	}
 }
 
+// DecodeStats returns counts of decoded and rejected client messages.
+func (h *Hub) DecodeStats() common.DecodeCounts {
+	return h.decodeStats.Counts()
+}
+
+// kick disconnects a registered client and closes its send channel so that
+// WritePump sends a close message with code and reason and shuts down the
+// connection.
+func (h *Hub) kick(client *Client, code transport.StatusCode, reason string) {
+	if _, ok := h.clients[client]; !ok {
+		return
+	}
+	h.disconnect(client)
+	client.closeCode = code
+	client.closeReason = reason
+	close(client.Send)
+}
+
 func (h *Hub) disconnect(client *Client) {
-	if _, ok := h.clients[client]; ok {
-		delete(h.clients, client)
+	if _, ok := h.clients[client]; !ok {
+		return
 	}
+	delete(h.clients, client)
 	if client.clientSlot >= 0 {
 		h.world.PlayerSlots[client.clientSlot] = false
 		msg := &pb.ServerMessage{
@@ -250,11 +532,12 @@ func (h *Hub) disconnect(client *Client) {
 			},
 		}
 		h.sendToAll(msg)
-		log.Printf("player %d disconnected\n", client.clientSlot)
+		h.clientLog(client).Info("player disconnected")
 	}
 	if h.world.HostSlot == client.clientSlot {
 		for i, p := range h.world.PlayerSlots {
 			if p {
+				h.world.HostSlot = int32(i)
 				msg := &pb.ServerMessage{
 					Content: &pb.ServerMessage_NewHost{
 						NewHost: &pb.NewHost{
@@ -263,47 +546,37 @@ func (h *Hub) disconnect(client *Client) {
 					},
 				}
 				h.sendToAll(msg)
-				log.Printf("%d is new host\n", i)
+				h.log.Info("new host", "slot", i)
+				break
 			}
 		}
 	}
-	if h.world.Running && len(h.clients) == 0 {
-		log.Println("no clients found")
-		h.world.Running = false
-		for _, ai := range h.world.AIs {
-			ai.stop = true
+	if len(h.clients) == 0 {
+		h.log.Info("room is empty")
+		if h.draining {
+			h.shutdown()
+			return
 		}
-		h.world = NewWorld(h.broadcast)
+		h.world.stop()
+		h.stopTicker()
+		h.playerSlots, h.crowdSize = h.cfg.PlayerSlots, h.cfg.CrowdSize
+		h.world = h.newWorld()
+		h.mapName = h.cfg.Map
 	}
 }
 
-// serveWs handles websocket requests from the peer.
-func (h *Hub) ServeWs(w http.ResponseWriter, r *http.Request) {
-	if !h.isRunning {
-		log.Fatal("hub is not running")
+// clientLog returns c's logger with the match attached while one is
+// running.
+func (h *Hub) clientLog(c *Client) *slog.Logger {
+	if h.world.Running {
+		return c.log.With("match", h.world.ID)
 	}
-	conn, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Println(err)
-		return
-	}
-	client := &Client{
-		Hub:        h,
-		Conn:       conn,
-		clientSlot: -1,
-		Send:       make(chan []byte, 256),
-	}
-	client.Hub.register <- client
-
-	// Allow collection of memory referenced by the caller by doing all work in
-	// new goroutines.
-	go client.WritePump()
-	go client.ReadPump()
+	return c.log
 }
 
 func (h *Hub) getNextFreeClientSlot() int32 {
-	for i := 0; i < common.MaxClients; i++ {
-		if !h.world.PlayerSlots[i] {
+	for i, taken := range h.world.PlayerSlots {
+		if !taken {
 			return int32(i)
 		}
 	}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestClient registers a client directly with the hub without a
+// websocket connection.
+func newTestClient(h *Hub, slot int32) *Client {
+	c := &Client{
+		Hub:        h,
+		Send:       make(chan []byte, 256),
+		clientSlot: slot,
+		log:        h.log,
+	}
+	h.clients[c] = slot
+	h.world.PlayerSlots[slot] = true
+	return c
+}
+
+func TestMalformedMessageDropsClient(t *testing.T) {
+	h := NewHub(1, DefaultConfig())
+	c := newTestClient(h, 0)
+	other := newTestClient(h, 1)
+
+	h.handleClientData(clientData{client: c, data: []byte{0xff, 0x01}})
+
+	if _, ok := h.clients[c]; ok {
+		t.Fatal("client was not removed from hub")
+	}
+	if h.world.PlayerSlots[0] {
+		t.Fatal("slot was not freed")
+	}
+	if _, ok := h.clients[other]; !ok {
+		t.Fatal("other client was removed")
+	}
+	for {
+		if _, ok := <-c.Send; !ok {
+			// closed channel tells WritePump to close the connection
+			break
+		}
+	}
+	if got := h.DecodeStats(); got.Malformed != 1 {
+		t.Fatalf("got %d malformed, want 1", got.Malformed)
+	}
+
+	// messages still in flight from the dropped client are ignored
+	h.handleClientData(clientData{client: c, data: []byte{0xff}})
+	if got := h.DecodeStats(); got.Failed() != 1 {
+		t.Fatalf("got %d failed, want 1", got.Failed())
+	}
+}
+
+func TestInputBeforeGameStartIsIgnored(t *testing.T) {
+	h := NewHub(1, DefaultConfig())
+	c := newTestClient(h, 0)
+	data, err := proto.Marshal(&pb.ClientMessage{
+		Content: &pb.ClientMessage_Input{Input: &pb.Input{UpPressed: true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.handleClientData(clientData{client: c, data: data})
+	if len(c.Send) != 0 {
+		t.Fatalf("got %d messages, want none", len(c.Send))
+	}
+}
+
+func TestSelectMap(t *testing.T) {
+	h := NewHub(1, DefaultConfig())
+	host := newTestClient(h, 0)
+	guest := newTestClient(h, 1)
+	selectMap := func(c *Client, name string) {
+		t.Helper()
+		data, err := proto.Marshal(&pb.ClientMessage{
+			Content: &pb.ClientMessage_SelectMap{SelectMap: &pb.SelectMap{Name: name}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.handleClientData(clientData{client: c, data: data})
+	}
+
+	selectMap(guest, "pillars")
+	selectMap(host, "nowhere")
+	if h.mapName != common.DefaultArena || len(guest.Send) != 0 {
+		t.Fatalf("map changed to %s", h.mapName)
+	}
+	selectMap(host, "pillars")
+	msg, err := common.DecodeServerMessage(<-guest.Send)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ul := msg.GetUpdateLobby(); ul == nil || ul.Map != "pillars" || len(ul.Maps) != len(common.Arenas) {
+		t.Fatalf("got %v, want the lobby on pillars", msg)
+	}
+
+	h.startGame()
+	t.Cleanup(func() {
+		h.stopTicker()
+		h.world.stop()
+	})
+	if h.world.arena.Name != "pillars" {
+		t.Fatalf("playing on %s", h.world.arena.Name)
+	}
+	for i, c := range h.world.Chars {
+		if c != nil && h.world.arena.Blocked(c.Px, c.Py, common.CharRadius) {
+			t.Fatalf("char %d placed in a wall at (%v, %v)", i, c.Px, c.Py)
+		}
+	}
+}
+
+func TestSetCapacity(t *testing.T) {
+	h := NewHub(1, DefaultConfig())
+	host := newTestClient(h, 0)
+	guest := newTestClient(h, 1)
+	setCapacity := func(c *Client, players, crowd int32) {
+		t.Helper()
+		data, err := proto.Marshal(&pb.ClientMessage{
+			Content: &pb.ClientMessage_SetCapacity{SetCapacity: &pb.SetCapacity{Players: players, Crowd: crowd}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.handleClientData(clientData{client: c, data: data})
+	}
+
+	// only the host may resize, and only to a size that can be played
+	setCapacity(guest, 16, 100)
+	setCapacity(host, 0, 100)
+	setCapacity(host, common.MaxClients+1, 100)
+	setCapacity(host, 16, 10)
+	setCapacity(host, 16, common.MaxChars)
+	if len(h.world.PlayerSlots) != common.DefaultPlayers || len(guest.Send) != 0 {
+		t.Fatalf("room resized to %d players", len(h.world.PlayerSlots))
+	}
+
+	setCapacity(host, 16, 100)
+	msg, err := common.DecodeServerMessage(<-guest.Send)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ul := msg.GetUpdateLobby(); ul == nil || len(ul.ConnectedSlots) != 16 || ul.Crowd != 100 {
+		t.Fatalf("got %v, want a lobby of 16 players and 100 characters", msg)
+	}
+	w := h.world
+	if len(w.PlayerSlots) != 16 || len(w.Chars) != 116 || !w.PlayerSlots[0] || !w.PlayerSlots[1] || w.HostSlot != 0 {
+		t.Fatalf("got %d slots %v, %d characters, host %d", len(w.PlayerSlots), w.PlayerSlots, len(w.Chars), w.HostSlot)
+	}
+
+	// players keep their slots, so the room cannot shrink past them
+	late := newTestClient(h, 12)
+	setCapacity(host, 8, 100)
+	if len(h.world.PlayerSlots) != 16 {
+		t.Fatalf("room shrank to %d players with slot 12 taken", len(h.world.PlayerSlots))
+	}
+
+	// the size carries over to the next match, and is dropped once the
+	// room empties
+	h.startGame()
+	t.Cleanup(func() {
+		h.stopTicker()
+		h.world.stop()
+	})
+	if len(h.world.AIs) != 100-3 {
+		t.Fatalf("got %d AIs", len(h.world.AIs))
+	}
+	h.world.Chars[1].IsDead = true
+	h.world.Chars[12].IsDead = true
+	h.step()
+	if h.world.Running || len(h.world.PlayerSlots) != 16 || len(h.world.Chars) != 116 {
+		t.Fatalf("next match has %d slots and %d characters", len(h.world.PlayerSlots), len(h.world.Chars))
+	}
+	for _, c := range []*Client{host, guest, late} {
+		h.disconnect(c)
+	}
+	if len(h.world.PlayerSlots) != common.DefaultPlayers || len(h.world.Chars) != common.DefaultCrowd+common.DefaultPlayers {
+		t.Fatalf("empty room has %d slots and %d characters", len(h.world.PlayerSlots), len(h.world.Chars))
+	}
+}
+
+func TestMatchesInARow(t *testing.T) {
+	h := NewHub(1, DefaultConfig())
+	newTestClient(h, 0)
+	newTestClient(h, 1)
+	h.mapName = "pillars"
+	t.Cleanup(func() {
+		h.stopTicker()
+		h.world.stop()
+	})
+	for match := 1; match <= 3; match++ {
+		h.startGame()
+		played := h.world
+		if !played.Running {
+			t.Fatalf("match %d did not start", match)
+		}
+		played.Chars[1].IsDead = true
+		h.step()
+		if played.Running || h.world == played {
+			t.Fatalf("match %d did not end", match)
+		}
+		// the room is ready for another match with the same players
+		if !h.world.PlayerSlots[0] || !h.world.PlayerSlots[1] || h.world.HostSlot != 0 || h.mapName != common.DefaultArena {
+			t.Fatalf("after match %d: slots %v, host %d, map %s", match, h.world.PlayerSlots, h.world.HostSlot, h.mapName)
+		}
+	}
+}
+
+// FuzzClientData feeds arbitrary bytes through the hub's handling of client
+// messages. The world is marked as running so StartGame does not spawn it.
+func FuzzClientData(f *testing.F) {
+	for _, m := range []*pb.ClientMessage{
+		{Content: &pb.ClientMessage_Input{Input: &pb.Input{ActionPressed: true}}},
+		{Content: &pb.ClientMessage_Input{Input: &pb.Input{UpPressed: true, LeftPressed: true}}},
+		{Content: &pb.ClientMessage_StartGame{StartGame: &pb.StartGame{}}},
+		{Content: &pb.ClientMessage_WorldUpdate{WorldUpdate: &pb.WorldUpdate{}}},
+		{Content: &pb.ClientMessage_SelectMap{SelectMap: &pb.SelectMap{Name: "rooms"}}},
+	} {
+		data, err := proto.Marshal(m)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		h := NewHub(1, DefaultConfig())
+		h.world.Running = true
+		c := newTestClient(h, 0)
+		for i := range h.world.Chars {
+			h.world.Chars[i] = common.NewChar()
+		}
+		h.handleClientData(clientData{client: c, data: data})
+		for len(c.Send) > 0 {
+			if _, err := common.DecodeServerMessage(<-c.Send); err != nil {
+				t.Fatalf("hub sent undecodable message: %v", err)
+			}
+		}
+	})
+}
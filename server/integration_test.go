@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+	"github.com/kisunji/ebiten-poc/transport"
+	"google.golang.org/protobuf/proto"
+)
+
+// testPlayer is a client speaking the protocol over an in-memory pipe.
+type testPlayer struct {
+	t    *testing.T
+	conn transport.Conn
+	msgs chan *pb.ServerMessage
+}
+
+func connectPlayer(t *testing.T, rs *Rooms) *testPlayer {
+	t.Helper()
+	return connectNamed(t, rs, "")
+}
+
+// connectNamed connects a player who chose name.
+func connectNamed(t *testing.T, rs *Rooms, name string) *testPlayer {
+	t.Helper()
+	serverEnd, clientEnd := transport.Pipe()
+	rs.Accept(serverEnd, t.Name(), name)
+	p := &testPlayer{t: t, conn: clientEnd, msgs: make(chan *pb.ServerMessage, 1024)}
+	go func() {
+		defer close(p.msgs)
+		for {
+			data, err := clientEnd.Read(context.Background())
+			if err != nil {
+				return
+			}
+			msg, err := common.DecodeServerMessage(data)
+			if err != nil {
+				t.Errorf("undecodable server message: %v", err)
+				return
+			}
+			p.msgs <- msg
+		}
+	}()
+	t.Cleanup(func() { clientEnd.Close(transport.StatusNormalClosure, "") })
+	return p
+}
+
+func (p *testPlayer) send(m *pb.ClientMessage) {
+	p.t.Helper()
+	data, err := proto.Marshal(m)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if err := p.conn.Write(context.Background(), data); err != nil {
+		p.t.Fatal(err)
+	}
+}
+
+// expect skips messages until one passes match, failing after timeout.
+func (p *testPlayer) expect(desc string, match func(*pb.ServerMessage) bool) *pb.ServerMessage {
+	p.t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case msg, ok := <-p.msgs:
+			if !ok {
+				p.t.Fatalf("connection closed waiting for %s", desc)
+			}
+			if match(msg) {
+				return msg
+			}
+		case <-timeout:
+			p.t.Fatalf("timed out waiting for %s", desc)
+		}
+	}
+}
+
+func TestLobbyToGameEnd(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PlayerSlots = 2
+	cfg.MaxRooms = 1
+	// shorter than Validate allows, to reach GameEnd quickly
+	cfg.MatchLength = 200 * time.Millisecond
+	rs := NewRooms(cfg)
+
+	host := connectPlayer(t, rs)
+	resp := host.expect("connect response", func(m *pb.ServerMessage) bool {
+		return m.GetConnectResponse() != nil
+	}).GetConnectResponse()
+	if resp.ClientSlot != 0 || !resp.IsHost {
+		t.Fatalf("host got %v", resp)
+	}
+
+	guest := connectPlayer(t, rs)
+	resp = guest.expect("connect response", func(m *pb.ServerMessage) bool {
+		return m.GetConnectResponse() != nil
+	}).GetConnectResponse()
+	if resp.ClientSlot != 1 || resp.IsHost {
+		t.Fatalf("guest got %v", resp)
+	}
+	host.expect("lobby with both players", func(m *pb.ServerMessage) bool {
+		ul := m.GetUpdateLobby()
+		return ul != nil && ul.ConnectedSlots[0] && ul.ConnectedSlots[1]
+	})
+
+	late := connectPlayer(t, rs)
+	late.expect("room full error", func(m *pb.ServerMessage) bool {
+		return m.GetConnectError() != nil
+	})
+
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_StartGame{}})
+	for _, p := range []*testPlayer{host, guest} {
+		gs := p.expect("game start", func(m *pb.ServerMessage) bool {
+			return m.GetGameStart() != nil
+		}).GetGameStart()
+		if gs.Players != 2 || gs.Crowd != int32(cfg.CrowdSize+2) {
+			t.Fatalf("got match size %d/%d, want 2/%d", gs.Players, gs.Crowd, cfg.CrowdSize+2)
+		}
+	}
+
+	guest.send(&pb.ClientMessage{Content: &pb.ClientMessage_WorldUpdate{}})
+	all := guest.expect("world update", func(m *pb.ServerMessage) bool {
+		return m.GetUpdateEntities() != nil
+	}).GetUpdateEntities()
+	if len(all.UpdateEntity) != cfg.CrowdSize {
+		t.Fatalf("got %d entities, want %d", len(all.UpdateEntity), cfg.CrowdSize)
+	}
+
+	guest.send(&pb.ClientMessage{Content: &pb.ClientMessage_Input{Input: &pb.Input{RightPressed: true}}})
+	host.expect("guest moving", func(m *pb.ServerMessage) bool {
+		ue := m.GetUpdateEntity()
+		return ue != nil && ue.Index == 1 && ue.Vx == 1
+	})
+
+	for _, p := range []*testPlayer{host, guest} {
+		end := p.expect("game end", func(m *pb.ServerMessage) bool {
+			return m.GetGameEnd() != nil
+		}).GetGameEnd()
+		if len(end.Score) != cfg.PlayerSlots {
+			t.Fatalf("got %d scores, want %d", len(end.Score), cfg.PlayerSlots)
+		}
+	}
+}
+
+func isConnectResponse(m *pb.ServerMessage) bool {
+	return m.GetConnectResponse() != nil
+}
+
+func TestHostMigration(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxRooms = 1
+	rs := NewRooms(cfg)
+
+	host := connectPlayer(t, rs)
+	host.expect("connect response", isConnectResponse)
+	guest := connectPlayer(t, rs)
+	guest.expect("connect response", isConnectResponse)
+	third := connectPlayer(t, rs)
+	third.expect("connect response", isConnectResponse)
+
+	host.conn.Close(transport.StatusNormalClosure, "")
+	for _, p := range []*testPlayer{guest, third} {
+		p.expect("host disconnected", func(m *pb.ServerMessage) bool {
+			pd := m.GetPlayerDisconnected()
+			return pd != nil && pd.Id == 0
+		})
+		nh := p.expect("new host", func(m *pb.ServerMessage) bool {
+			return m.GetNewHost() != nil
+		}).GetNewHost()
+		if nh.Id != 1 {
+			t.Fatalf("got new host %d, want 1", nh.Id)
+		}
+	}
+
+	// the freed slot is reused without taking over as host
+	late := connectPlayer(t, rs)
+	resp := late.expect("connect response", isConnectResponse).GetConnectResponse()
+	if resp.ClientSlot != 0 || resp.IsHost {
+		t.Fatalf("late player got %v", resp)
+	}
+	var newHosts int
+	third.expect("lobby with late player", func(m *pb.ServerMessage) bool {
+		if m.GetNewHost() != nil {
+			newHosts++
+		}
+		ul := m.GetUpdateLobby()
+		return ul != nil && ul.ConnectedSlots[0]
+	})
+	if newHosts != 0 {
+		t.Fatalf("host was announced %d more times", newHosts)
+	}
+	if info := rs.Info(); len(info) != 1 || info[0].Players != 3 {
+		t.Fatalf("got rooms %+v", info)
+	}
+}
+
+func TestSlotExhaustion(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PlayerSlots = 2
+	cfg.MaxRooms = 2
+	rs := NewRooms(cfg)
+
+	for i := 0; i < 4; i++ {
+		p := connectPlayer(t, rs)
+		resp := p.expect("connect response", isConnectResponse).GetConnectResponse()
+		if want := int32(i % 2); resp.ClientSlot != want {
+			t.Fatalf("player %d got slot %d, want %d", i, resp.ClientSlot, want)
+		}
+		if resp.IsHost != (i%2 == 0) {
+			t.Fatalf("player %d got IsHost %v", i, resp.IsHost)
+		}
+	}
+	extra := connectPlayer(t, rs)
+	msg := extra.expect("rejection", func(m *pb.ServerMessage) bool { return true })
+	if msg.GetConnectError() == nil {
+		t.Fatalf("got %v, want a connect error", msg)
+	}
+	info := rs.Info()
+	if len(info) != 2 || info[0].Players != 2 || info[1].Players != 2 {
+		t.Fatalf("got rooms %+v", info)
+	}
+}
+
+func TestMatchTimesOutOnFakeClock(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PlayerSlots = 2
+	cfg.MaxRooms = 1
+	clock := newFakeClock()
+	rs := NewRooms(cfg)
+	rs.clock = clock
+
+	host := connectPlayer(t, rs)
+	host.expect("connect response", isConnectResponse)
+	guest := connectPlayer(t, rs)
+	guest.expect("connect response", isConnectResponse)
+
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_StartGame{}})
+	guest.expect("game start", func(m *pb.ServerMessage) bool {
+		return m.GetGameStart() != nil
+	})
+	// wait for the hub to finish starting the match
+	rs.hubs[0].do(func() {})
+
+	clock.Advance(cfg.MatchLength / 2)
+	rs.hubs[0].do(func() {})
+	if !rs.Info()[0].Running {
+		t.Fatal("match ended early")
+	}
+
+	clock.Advance(cfg.MatchLength)
+	for _, p := range []*testPlayer{host, guest} {
+		p.expect("game end", func(m *pb.ServerMessage) bool {
+			return m.GetGameEnd() != nil
+		})
+	}
+	if rs.Info()[0].Running {
+		t.Fatal("room still running after game end")
+	}
+
+	// the room takes new players again once the last one leaves
+	host.conn.Close(transport.StatusNormalClosure, "")
+	guest.conn.Close(transport.StatusNormalClosure, "")
+	for deadline := time.Now().Add(2 * time.Second); rs.Info()[0].Players > 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("players were not unregistered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	late := connectPlayer(t, rs)
+	resp := late.expect("connect response", isConnectResponse).GetConnectResponse()
+	if !resp.IsHost {
+		t.Fatalf("late player got %v", resp)
+	}
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net"
+)
+
+// NewLogger creates a logger with the level and format set in cfg. The
+// server command installs it as the slog default, which rooms log to.
+func NewLogger(cfg Config, w io.Writer) *slog.Logger {
+	var level slog.Level
+	// checked by Validate
+	_ = level.UnmarshalText([]byte(cfg.LogLevel))
+	opts := &slog.HandlerOptions{Level: level}
+	if cfg.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// remoteIP strips the port from a remote address, if it has one.
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// newMatchID returns a random ID that tells matches apart in logs.
+func newMatchID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+// syncBuffer lets the hub goroutines and the test share a log buffer.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// records decodes every JSON log line written so far.
+func (b *syncBuffer) records(t *testing.T) []map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var recs []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("bad log line %q: %v", line, err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestNewLoggerLevelAndFormat(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LogFormat = "json"
+	cfg.LogLevel = "warn"
+	var buf bytes.Buffer
+	log := NewLogger(cfg, &buf)
+	log.Info("hidden")
+	log.Warn("shown", "room", 3)
+	var rec map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("got %q: %v", buf.String(), err)
+	}
+	if rec["msg"] != "shown" || rec["room"] != float64(3) {
+		t.Fatalf("got %v", rec)
+	}
+
+	cfg.LogFormat = "text"
+	buf.Reset()
+	NewLogger(cfg, &buf).Error("oops", "slot", 1)
+	if !strings.Contains(buf.String(), "msg=oops slot=1") {
+		t.Fatalf("got %q", buf.String())
+	}
+}
+
+func TestRemoteIP(t *testing.T) {
+	for addr, want := range map[string]string{
+		"10.0.0.1:5000": "10.0.0.1",
+		"[::1]:5000":    "::1",
+		"pipe":          "pipe",
+	} {
+		if got := remoteIP(addr); got != want {
+			t.Errorf("remoteIP(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestLogsCarryRoomAndMatch(t *testing.T) {
+	var buf syncBuffer
+	cfg := DefaultConfig()
+	cfg.PlayerSlots = 2
+	rs := NewRooms(cfg)
+	rs.log = slog.New(slog.NewJSONHandler(&buf, nil))
+	host := connectPlayer(t, rs)
+	host.expect("connect response", isConnectResponse)
+	guest := connectPlayer(t, rs)
+	guest.expect("connect response", isConnectResponse)
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_StartGame{}})
+	host.expect("game start", func(m *pb.ServerMessage) bool {
+		return m.GetGameStart() != nil
+	})
+	if !rs.hubs[0].EndMatch() {
+		t.Fatal("no match running")
+	}
+
+	var connected, over int
+	var match interface{}
+	for _, rec := range buf.records(t) {
+		switch rec["msg"] {
+		case "player connected":
+			connected++
+			if rec["room"] != float64(1) || rec["slot"] == nil || rec["remote"] != t.Name() {
+				t.Errorf("connect log missing context: %v", rec)
+			}
+		case "match over":
+			over++
+			match = rec["match"]
+			if rec["room"] != float64(1) || match == nil || match == "" {
+				t.Errorf("match log missing context: %v", rec)
+			}
+		}
+	}
+	if connected != 2 || over != 1 {
+		t.Fatalf("got %d connect and %d match over logs", connected, over)
+	}
+}
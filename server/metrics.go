@@ -0,0 +1,207 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Counter is a value that only goes up.
+type Counter struct {
+	v uint64
+}
+
+func (c *Counter) Inc()          { c.Add(1) }
+func (c *Counter) Add(n uint64)  { atomic.AddUint64(&c.v, n) }
+func (c *Counter) Value() uint64 { return atomic.LoadUint64(&c.v) }
+
+// Gauge is a value that goes up and down.
+type Gauge struct {
+	v int64
+}
+
+func (g *Gauge) Inc()         { atomic.AddInt64(&g.v, 1) }
+func (g *Gauge) Dec()         { atomic.AddInt64(&g.v, -1) }
+func (g *Gauge) Value() int64 { return atomic.LoadInt64(&g.v) }
+
+// CounterVec is a set of counters told apart by the value of one label.
+type CounterVec struct {
+	mu     sync.Mutex
+	values map[string]uint64
+}
+
+func (v *CounterVec) Inc(label string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.values == nil {
+		v.values = make(map[string]uint64)
+	}
+	v.values[label]++
+}
+
+// Values returns a copy of every counter by label.
+func (v *CounterVec) Values() map[string]uint64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	values := make(map[string]uint64, len(v.values))
+	for k, n := range v.values {
+		values[k] = n
+	}
+	return values
+}
+
+// Histogram counts observations into cumulative buckets.
+type Histogram struct {
+	mu      sync.Mutex
+	bounds  []float64
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// NewHistogram creates a histogram with the given ascending upper bounds.
+func NewHistogram(bounds ...float64) *Histogram {
+	return &Histogram{
+		bounds:  bounds,
+		buckets: make([]uint64, len(bounds)),
+	}
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, b := range h.bounds {
+		if v <= b {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// Metrics collects counters for the /metrics endpoint. Values that can be
+// read off the rooms, like connected clients, are gathered when scraped
+// instead.
+type Metrics struct {
+	// Client messages by type.
+	MessagesIn CounterVec
+	// Server messages queued to clients by type, once per recipient.
+	MessagesOut CounterVec
+	// Bytes written to client connections.
+	BytesSent Counter
+	// Messages dropped because a client's send buffer was full.
+	SendDrops Counter
+	// AI goroutines currently running.
+	AIGoroutines Gauge
+	// Matches that ended with a GameEnd.
+	MatchesCompleted Counter
+	// Time taken by one world update, in seconds.
+	TickDuration *Histogram
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		TickDuration: NewHistogram(.00005, .0001, .00025, .0005, .001, .0025, .005, .01, .025),
+	}
+}
+
+// messageType names the content of a ClientMessage or ServerMessage,
+// e.g. "updateEntity".
+func messageType(m proto.Message) string {
+	r := m.ProtoReflect()
+	oneofs := r.Descriptor().Oneofs()
+	if oneofs.Len() == 0 {
+		return "unknown"
+	}
+	f := r.WhichOneof(oneofs.Get(0))
+	if f == nil {
+		return "none"
+	}
+	return string(f.Name())
+}
+
+// ServeMetrics writes the server metrics in the Prometheus text format.
+func (rs *Rooms) ServeMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	rs.writeMetrics(w)
+}
+
+func (rs *Rooms) writeMetrics(w io.Writer) {
+	var clients, running int
+	hubs := rs.allHubs()
+	var decoded, failed uint64
+	for _, h := range hubs {
+		info := h.Info()
+		clients += info.Players
+		if info.Running {
+			running++
+		}
+		counts := h.DecodeStats()
+		decoded += counts.Decoded
+		failed += counts.Failed()
+	}
+	m := rs.metrics
+
+	gauge(w, "oneofthem_connected_clients", "Clients in a room.", int64(clients))
+	gauge(w, "oneofthem_rooms", "Rooms created.", int64(len(hubs)))
+	gauge(w, "oneofthem_running_matches", "Rooms with a match in progress.", int64(running))
+	gauge(w, "oneofthem_ai_goroutines", "AI goroutines running.", m.AIGoroutines.Value())
+	counter(w, "oneofthem_matches_completed_total", "Matches that reached a result.", m.MatchesCompleted.Value())
+	counterVec(w, "oneofthem_messages_in_total", "Client messages received by type.", "type", m.MessagesIn.Values())
+	counterVec(w, "oneofthem_messages_out_total", "Server messages queued by type, per recipient.", "type", m.MessagesOut.Values())
+	counter(w, "oneofthem_bytes_sent_total", "Bytes written to client connections.", m.BytesSent.Value())
+	counter(w, "oneofthem_send_buffer_drops_total", "Messages dropped because a client send buffer was full.", m.SendDrops.Value())
+	counterVec(w, "oneofthem_decoded_messages_total", "Client messages by decode result.", "result", map[string]uint64{
+		"ok":    decoded,
+		"error": failed,
+	})
+	histogram(w, "oneofthem_tick_duration_seconds", "Time taken by one world update.", m.TickDuration)
+}
+
+func header(w io.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func gauge(w io.Writer, name, help string, v int64) {
+	header(w, name, help, "gauge")
+	fmt.Fprintf(w, "%s %d\n", name, v)
+}
+
+func counter(w io.Writer, name, help string, v uint64) {
+	header(w, name, help, "counter")
+	fmt.Fprintf(w, "%s %d\n", name, v)
+}
+
+func counterVec(w io.Writer, name, help, label string, values map[string]uint64) {
+	header(w, name, help, "counter")
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s{%s=%q} %d\n", name, label, k, values[k])
+	}
+}
+
+func histogram(w io.Writer, name, help string, h *Histogram) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	header(w, name, help, "histogram")
+	for i, b := range h.bounds {
+		fmt.Fprintf(w, "%s_bucket{le=%q} %d\n", name, formatFloat(b), h.buckets[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count %d\n", name, h.count)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	h := NewHistogram(1, 2)
+	for _, v := range []float64{0.5, 1, 1.5, 3} {
+		h.Observe(v)
+	}
+	var sb strings.Builder
+	histogram(&sb, "x", "help", h)
+	for _, want := range []string{
+		`x_bucket{le="1"} 2`,
+		`x_bucket{le="2"} 3`,
+		`x_bucket{le="+Inf"} 4`,
+		`x_sum 6`,
+		`x_count 4`,
+	} {
+		if !strings.Contains(sb.String(), want+"\n") {
+			t.Errorf("missing %q in\n%s", want, sb.String())
+		}
+	}
+}
+
+func TestMessageType(t *testing.T) {
+	msg := &pb.ServerMessage{Content: &pb.ServerMessage_UpdateEntity{UpdateEntity: &pb.UpdateEntity{}}}
+	if got := messageType(msg); got != "updateEntity" {
+		t.Fatalf("got %q", got)
+	}
+	if got := messageType(&pb.ClientMessage{}); got != "none" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PlayerSlots = 2
+	cfg.MaxRooms = 1
+	clock := newFakeClock()
+	rs := NewRooms(cfg)
+	rs.clock = clock
+
+	host := connectPlayer(t, rs)
+	host.expect("connect response", isConnectResponse)
+	guest := connectPlayer(t, rs)
+	guest.expect("connect response", isConnectResponse)
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_StartGame{}})
+	guest.expect("game start", func(m *pb.ServerMessage) bool {
+		return m.GetGameStart() != nil
+	})
+	rs.hubs[0].do(func() {})
+	clock.Advance(cfg.tickDuration())
+	rs.hubs[0].do(func() {
+		// end the match on the next tick
+		rs.hubs[0].world.Chars[1].IsDead = true
+	})
+	clock.Advance(cfg.tickDuration())
+	host.expect("game end", func(m *pb.ServerMessage) bool {
+		return m.GetGameEnd() != nil
+	})
+
+	rec := httptest.NewRecorder()
+	rs.ServeMetrics(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	out := string(body)
+	for _, want := range []string{
+		"oneofthem_connected_clients 2",
+		"oneofthem_rooms 1",
+		"oneofthem_running_matches 0",
+		"oneofthem_matches_completed_total 1",
+		`oneofthem_messages_in_total{type="startGame"} 1`,
+		`oneofthem_messages_out_total{type="connectResponse"} 2`,
+		`oneofthem_messages_out_total{type="gameStart"} 2`,
+		`oneofthem_messages_out_total{type="gameEnd"} 2`,
+		`oneofthem_decoded_messages_total{result="ok"} 1`,
+		"oneofthem_send_buffer_drops_total 0",
+		`oneofthem_tick_duration_seconds_bucket{le="+Inf"} 2`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "oneofthem_bytes_sent_total 0\n") {
+		t.Error("no bytes counted as sent")
+	}
+}
+
+func TestQueueDropsWhenBufferFull(t *testing.T) {
+	h := NewHub(1, DefaultConfig())
+	c := newTestClient(h, 0)
+	c.Send = make(chan []byte, 1)
+	h.sendToAll(&pb.ServerMessage{Content: &pb.ServerMessage_GameStart{GameStart: &pb.GameStart{}}})
+	h.sendToAll(&pb.ServerMessage{Content: &pb.ServerMessage_GameStart{GameStart: &pb.GameStart{}}})
+	if got := h.metrics.SendDrops.Value(); got != 1 {
+		t.Fatalf("got %d drops, want 1", got)
+	}
+	if got := h.metrics.MessagesOut.Values()["gameStart"]; got != 1 {
+		t.Fatalf("got %d messages out, want 1", got)
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"sort"
+	"time"
+)
+
+// Mode holds the rules a room plays by.
+type Mode struct {
+	Name string
+	// End the match as soon as one player is left standing.
+	LastSurvivorWins bool
+	// What a player pays for killing an AI.
+	AIPenalty Penalty
+	// How far players can see, in pixels, where walls do not block the
+	// view. Zero means everyone sees everything.
+	Vision float64
+}
+
+// Penalty is applied to a player whose attack kills an AI, at most once
+// per attack.
+type Penalty struct {
+	// How long the player can neither move nor attack.
+	Stun time.Duration
+	// Coins taken off the player's score. The score does not go below zero.
+	ScoreLoss int32
+	// How long every client outlines the player.
+	Reveal time.Duration
+}
+
+// Modes are the modes a server can be configured with, by name.
+var Modes = map[string]Mode{
+	"classic": {
+		Name:             "classic",
+		LastSurvivorWins: true,
+		AIPenalty: Penalty{
+			Stun:      1500 * time.Millisecond,
+			ScoreLoss: 1,
+			Reveal:    3 * time.Second,
+		},
+	},
+	// Classic rules, but players only see who is near them.
+	"fog": {
+		Name:             "fog",
+		LastSurvivorWins: true,
+		AIPenalty: Penalty{
+			Stun:      1500 * time.Millisecond,
+			ScoreLoss: 1,
+			Reveal:    3 * time.Second,
+		},
+		Vision: 120,
+	},
+	// A single player learning the controls among the crowd.
+	"practice": {
+		Name: "practice",
+		AIPenalty: Penalty{
+			Reveal: 2 * time.Second,
+		},
+	},
+}
+
+func modeNames() []string {
+	names := make([]string, 0, len(Modes))
+	for name := range Modes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+// pickupEffect is what collecting a kind of power-up does to a player.
+type pickupEffect struct {
+	// How long the effect lasts, or zero if it is over at once.
+	duration time.Duration
+	// Applies the effect to the player in slot. May be nil for effects
+	// that are only looked up while they last.
+	start func(w *World, slot int)
+	// Undoes start once duration is up. May be nil.
+	end func(w *World, slot int)
+}
+
+var pickupEffects = [common.NumPickupKinds]pickupEffect{
+	common.PickupSpeed: {
+		duration: 5 * time.Second,
+		start:    (*World).startBoost,
+		end:      (*World).endBoost,
+	},
+	common.PickupDisguise: {
+		duration: 3 * time.Second,
+		start:    (*World).startDisguise,
+		end:      (*World).endDisguise,
+	},
+	common.PickupReach: {
+		duration: 8 * time.Second,
+	},
+	common.PickupDecoy: {
+		duration: 4 * time.Second,
+		start:    (*World).spawnDecoy,
+		end:      (*World).removeDecoy,
+	},
+}
+
+// spawnPickup places a random power-up and announces it. It takes the
+// index of a collected one if there is any, and nothing appears while
+// common.MaxPickups are lying around.
+func (w *World) spawnPickup() {
+	if !w.Running {
+		return
+	}
+	index := -1
+	for i, p := range w.Pickups {
+		if p.PickedUp {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		if len(w.Pickups) >= common.MaxPickups {
+			return
+		}
+		index = len(w.Pickups)
+		w.Pickups = append(w.Pickups, nil)
+	}
+	p := common.NewPickup(w.arena, common.PickupKind(rand.Intn(common.NumPickupKinds)))
+	w.Pickups[index] = p
+	w.send(&pb.ServerMessage{
+		Content: &pb.ServerMessage_NewPickup{
+			NewPickup: &pb.NewPickup{
+				Index: int32(index),
+				Kind:  int32(p.Kind),
+				Px:    p.Px,
+				Py:    p.Py,
+			},
+		},
+	})
+}
+
+// collectPickups applies each power-up a player is touching. Everyone is
+// told it is gone but only the collector learns who took it. Players in
+// the middle of an attack collect it once the attack is over.
+func (w *World) collectPickups() {
+	for i, p := range w.Pickups {
+		if p.PickedUp {
+			continue
+		}
+		for slot, isPlayer := range w.PlayerSlots {
+			char := w.Chars[slot]
+			if !isPlayer || char.IsDead || char.Attacking() || !isHit(char.Px, char.Py, p.Px, p.Py, common.PickupRadius) {
+				continue
+			}
+			p.PickedUp = true
+			effect := pickupEffects[p.Kind]
+			if effect.start != nil {
+				effect.start(w, slot)
+			}
+			if effect.duration > 0 {
+				w.effectUntil[slot][p.Kind] = w.clock.Now().Add(effect.duration)
+			}
+			w.log.Debug("pickup collected", "slot", slot, "kind", p.Kind)
+			for other, isPlayer := range w.PlayerSlots {
+				if !isPlayer {
+					continue
+				}
+				got := &pb.PickupGot{Index: int32(i)}
+				if other == slot {
+					got.Yours = true
+					got.DurationMillis = int32(effect.duration.Milliseconds())
+				}
+				w.sendTo(int32(other), &pb.ServerMessage{
+					Content: &pb.ServerMessage_PickupGot{PickupGot: got},
+				})
+			}
+			break
+		}
+	}
+}
+
+// effectActive reports whether the player in slot is under a power-up
+// of kind.
+func (w *World) effectActive(slot int, kind common.PickupKind) bool {
+	return slot < len(w.effectUntil) && w.clock.Now().Before(w.effectUntil[slot][kind])
+}
+
+// endEffects undoes power-ups that have worn off.
+func (w *World) endEffects() {
+	now := w.clock.Now()
+	for slot := range w.effectUntil {
+		for kind, until := range w.effectUntil[slot] {
+			if until.IsZero() || now.Before(until) {
+				continue
+			}
+			w.effectUntil[slot][kind] = time.Time{}
+			if end := pickupEffects[kind].end; end != nil {
+				end(w, slot)
+			}
+		}
+	}
+}
+
+func (w *World) startBoost(slot int) {
+	w.Chars[slot].Boost = common.SpeedBoost
+	w.sendEntity(slot)
+}
+
+func (w *World) endBoost(slot int) {
+	w.Chars[slot].Boost = 0
+	w.sendEntity(slot)
+}
+
+// startDisguise trades places with the nearest living AI in range, so
+// whoever was watching the player follows the AI instead. Nothing happens
+// if there is none, or if the player is already disguised.
+func (w *World) startDisguise(slot int) {
+	if w.disguisedAs[slot] >= 0 {
+		return
+	}
+	char := w.Chars[slot]
+	best, bestDistance := -1, common.DisguiseRange
+	w.near = w.charGrid.Near(w.near[:0], char.Px, char.Py, common.DisguiseRange)
+	for _, i := range w.near {
+		other := w.Chars[i]
+		if w.isPlayer(i) || other == nil || other.IsDead {
+			continue
+		}
+		if d := math.Hypot(other.Px-char.Px, other.Py-char.Py); d <= bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	if best < 0 {
+		return
+	}
+	w.swapChars(slot, best)
+	w.disguisedAs[slot] = best
+}
+
+// endDisguise trades places back with the AI the player swapped with.
+// The disguise just expires if either of them has died since.
+func (w *World) endDisguise(slot int) {
+	i := w.disguisedAs[slot]
+	if i < 0 {
+		return
+	}
+	w.disguisedAs[slot] = -1
+	char, other := w.Chars[slot], w.Chars[i]
+	if char.IsDead || other == nil || other.IsDead {
+		return
+	}
+	w.swapChars(slot, i)
+}
+
+// swapChars swaps where characters a and b are. Each carries on walking
+// the way the other was.
+func (w *World) swapChars(a, b int) {
+	char, other := w.Chars[a], w.Chars[b]
+	w.charGrid.Move(a, char.Px, char.Py, other.Px, other.Py)
+	w.charGrid.Move(b, other.Px, other.Py, char.Px, char.Py)
+	char.Px, char.Py, other.Px, other.Py = other.Px, other.Py, char.Px, char.Py
+	char.Fx, char.Fy, other.Fx, other.Fy = other.Fx, other.Fy, char.Fx, char.Fy
+	char.Vx, char.Vy, other.Vx, other.Vy = other.Vx, other.Vy, char.Vx, char.Vy
+	char.Offset, other.Offset = other.Offset, char.Offset
+	w.sendEntity(a)
+	w.sendEntity(b)
+}
+
+// decoyIndex is the character slot for the decoy of the player in slot,
+// after the crowd.
+func (w *World) decoyIndex(slot int) int {
+	return len(w.Chars) - len(w.PlayerSlots) + slot
+}
+
+// spawnDecoy puts a copy of the player where they stand, walking the way
+// they are going or facing until its AI picks where to go like the
+// crowd's does. It replaces any decoy they already have out. Killing it
+// counts as killing an AI.
+func (w *World) spawnDecoy(slot int) {
+	i := w.decoyIndex(slot)
+	w.removeChar(i)
+	char := w.Chars[slot]
+	decoy := common.NewChar()
+	decoy.Px, decoy.Py = char.Px, char.Py
+	decoy.Fx, decoy.Fy = char.Fx, char.Fy
+	decoy.Vx, decoy.Vy = char.Vx, char.Vy
+	if decoy.Vx == 0 && decoy.Vy == 0 {
+		decoy.Vx, decoy.Vy = char.Fx, char.Fy
+	}
+	if decoy.Vx == 0 && decoy.Vy == 0 {
+		decoy.Vy = 1
+	}
+	decoy.Timing = w.timing
+	w.Chars[i] = decoy
+	w.charGrid.Insert(i, decoy.Px, decoy.Py)
+	w.sendEntity(i)
+}
+
+func (w *World) removeDecoy(slot int) {
+	w.removeChar(w.decoyIndex(slot))
+}
+
+// removeChar takes character i out of the match. With limited vision,
+// updateVisibility tells the players who could see it.
+func (w *World) removeChar(i int) {
+	if w.Chars[i] == nil {
+		return
+	}
+	w.Chars[i] = nil
+	if w.mode.Vision <= 0 {
+		w.send(hiddenUpdate(i))
+	}
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+// givePickup puts a pickup of kind under player 0, who is at (100, 100),
+// and collects it.
+func givePickup(w *World, kind common.PickupKind) {
+	w.Chars[0].Px, w.Chars[0].Py = 100, 100
+	w.Pickups = append(w.Pickups, &common.Pickup{Kind: kind, Px: 100, Py: 100})
+	w.update()
+}
+
+func findPickupGot(msgs []*pb.ServerMessage) *pb.PickupGot {
+	for _, m := range msgs {
+		if pg := m.GetPickupGot(); pg != nil {
+			return pg
+		}
+	}
+	return nil
+}
+
+func TestSpawnPickup(t *testing.T) {
+	w, _, sent := newTestWorld(t, DefaultConfig(), 0)
+	for i := 0; i < common.MaxPickups+1; i++ {
+		w.spawnPickup()
+	}
+	if len(w.Pickups) != common.MaxPickups {
+		t.Fatalf("got %d pickups, want at most %d", len(w.Pickups), common.MaxPickups)
+	}
+	np := (*sent)[len(*sent)-1].GetNewPickup()
+	if np == nil || np.Index != common.MaxPickups-1 {
+		t.Fatalf("last sent %v", (*sent)[len(*sent)-1])
+	}
+
+	// a collected pickup makes way for the next
+	w.Pickups[3].PickedUp = true
+	w.spawnPickup()
+	if np := (*sent)[len(*sent)-1].GetNewPickup(); np == nil || np.Index != 3 || w.Pickups[3].PickedUp {
+		t.Fatalf("new pickup did not reuse index 3: %v", np)
+	}
+}
+
+func TestPickupSpeed(t *testing.T) {
+	w, clock, sent := newTestWorld(t, DefaultConfig(), 0, 1)
+	placeApart(w)
+	givePickup(w, common.PickupSpeed)
+	if w.Chars[0].Boost != common.SpeedBoost || !w.Pickups[0].PickedUp {
+		t.Fatalf("got boost %d", w.Chars[0].Boost)
+	}
+	// each player hears about it, but only the collector learns it was theirs
+	var yours, theirs int
+	for _, m := range *sent {
+		if pg := m.GetPickupGot(); pg != nil && pg.Yours {
+			yours++
+			if pg.DurationMillis != int32(pickupEffects[common.PickupSpeed].duration.Milliseconds()) {
+				t.Errorf("got duration %d", pg.DurationMillis)
+			}
+		} else if pg != nil {
+			theirs++
+		}
+	}
+	if yours != 1 || theirs != 1 {
+		t.Fatalf("sent %d own and %d other pickup messages", yours, theirs)
+	}
+
+	clock.Advance(pickupEffects[common.PickupSpeed].duration)
+	w.update()
+	if w.Chars[0].Boost != 0 {
+		t.Fatal("boost did not wear off")
+	}
+	last := (*sent)[len(*sent)-1].GetUpdateEntity()
+	if last == nil || last.Index != 0 || last.Boost != 0 {
+		t.Fatalf("end of boost not announced, last sent %v", last)
+	}
+}
+
+func TestPickupDisguise(t *testing.T) {
+	w, clock, _ := newTestWorld(t, DefaultConfig(), 0, 1)
+	placeApart(w)
+	near, far := w.Chars[common.DefaultPlayers], w.Chars[common.DefaultPlayers+1]
+	near.Px, near.Py = 140, 100
+	far.Px, far.Py = 100, 170
+	you := w.Chars[0]
+	you.Px, you.Py = 100, 100
+	w.update()
+	// set off after the update so the swap happens before anyone moves
+	near.Vx, near.Vy = 0, -1
+	you.ProcessInput(&pb.Input{RightPressed: true})
+	w.Pickups = append(w.Pickups, &common.Pickup{Kind: common.PickupDisguise, Px: 100, Py: 100})
+	w.collectPickups()
+	if you.Px != 140 || you.Py != 100 || near.Px != 100 || near.Py != 100 {
+		t.Fatalf("did not swap with the nearest AI: at (%v, %v), AI at (%v, %v)", you.Px, you.Py, near.Px, near.Py)
+	}
+	// each walks on the way the other was going
+	if you.Vx != 0 || you.Vy != -1 || near.Vx != 1 || near.Vy != 0 {
+		t.Fatalf("got velocity (%d, %d), AI (%d, %d)", you.Vx, you.Vy, near.Vx, near.Vy)
+	}
+
+	// another disguise while disguised does not swap again
+	w.Pickups = append(w.Pickups, &common.Pickup{Kind: common.PickupDisguise, Px: 140, Py: 100})
+	w.collectPickups()
+	if you.Px != 140 || near.Px != 100 {
+		t.Fatalf("swapped again: at (%v, %v), AI at (%v, %v)", you.Px, you.Py, near.Px, near.Py)
+	}
+
+	// once it wears off they swap back, wherever they have got to
+	you.Vx, you.Vy, near.Vx, near.Vy = 0, 0, 0, 0
+	you.Px, near.Px = 150, 90
+	clock.Advance(pickupEffects[common.PickupDisguise].duration)
+	w.update()
+	if you.Px != 90 || near.Px != 150 || w.disguisedAs[0] != -1 {
+		t.Fatalf("did not swap back: at (%v, %v), AI at (%v, %v)", you.Px, you.Py, near.Px, near.Py)
+	}
+}
+
+func TestPickupDisguiseExpires(t *testing.T) {
+	w, clock, _ := newTestWorld(t, DefaultConfig(), 0, 1)
+	placeApart(w)
+	near := w.Chars[common.DefaultPlayers]
+	near.Px, near.Py = 140, 100
+	givePickup(w, common.PickupDisguise)
+	you := w.Chars[0]
+	if you.Px != 140 {
+		t.Fatalf("did not swap: at (%v, %v)", you.Px, you.Py)
+	}
+	// the AI dies while the player is disguised, so there is nobody to
+	// swap back with
+	near.IsDead = true
+	clock.Advance(pickupEffects[common.PickupDisguise].duration)
+	w.update()
+	if you.Px != 140 || w.disguisedAs[0] != -1 {
+		t.Fatalf("swapped back with a dead AI: at (%v, %v)", you.Px, you.Py)
+	}
+}
+
+func TestPickupWaitsForAttack(t *testing.T) {
+	w, _, _ := newTestWorld(t, DefaultConfig(), 0, 1)
+	placeApart(w)
+	you := w.Chars[0]
+	you.Px, you.Py = 100, 100
+	you.ProcessInput(&pb.Input{ActionPressed: true})
+	w.Pickups = append(w.Pickups, &common.Pickup{Kind: common.PickupSpeed, Px: 100, Py: 100})
+	w.collectPickups()
+	if w.Pickups[0].PickedUp {
+		t.Fatal("collected during an attack")
+	}
+	for you.Attacking() {
+		w.update()
+	}
+	w.update()
+	if !w.Pickups[0].PickedUp || you.Boost != common.SpeedBoost {
+		t.Fatal("not collected after the attack")
+	}
+}
+
+func TestPickupReach(t *testing.T) {
+	w, _, _ := newTestWorld(t, DefaultConfig(), 0, 1)
+	placeApart(w)
+	givePickup(w, common.PickupReach)
+	attacker, npc := w.Chars[0], w.Chars[common.DefaultPlayers]
+	attacker.Fx, attacker.Fy = 0, 1
+	// out of normal reach
+	npc.Px, npc.Py = 100, 100+common.HitRadius*2.5
+	attacker.ProcessInput(&pb.Input{ActionPressed: true})
+	for attacker.Attacking() {
+		w.update()
+	}
+	if !npc.IsDead {
+		t.Fatal("boosted attack did not reach")
+	}
+}
+
+func TestPickupDecoy(t *testing.T) {
+	w, clock, sent := newTestWorld(t, DefaultConfig(), 0, 1)
+	placeApart(w)
+	w.Chars[0].ProcessInput(&pb.Input{RightPressed: true})
+	givePickup(w, common.PickupDecoy)
+	i := w.decoyIndex(0)
+	decoy := w.Chars[i]
+	if decoy == nil || decoy.Vx != 1 || w.isPlayer(i) {
+		t.Fatalf("got decoy %+v at %d", decoy, i)
+	}
+	x := decoy.Px
+	w.update()
+	if decoy.Px <= x {
+		t.Fatal("decoy does not walk")
+	}
+
+	clock.Advance(pickupEffects[common.PickupDecoy].duration + time.Millisecond)
+	w.update()
+	if w.Chars[i] != nil {
+		t.Fatal("decoy outlived its pickup")
+	}
+	var gone bool
+	for _, m := range *sent {
+		if ue := m.GetUpdateEntity(); ue != nil && ue.Index == int32(i) && ue.Hidden {
+			gone = true
+		}
+	}
+	if !gone {
+		t.Fatal("decoy removal not announced")
+	}
+}
+
+func TestDecoyWalksWithTheCrowd(t *testing.T) {
+	clock := newFakeClock()
+	h := newHub(1, DefaultConfig(), clock, NewMetrics(), slog.Default())
+	newTestClient(h, 0)
+	newTestClient(h, 1)
+	h.startGame()
+	t.Cleanup(func() {
+		h.stopTicker()
+		h.world.stop()
+	})
+	w := h.world
+	placeApart(w)
+	givePickup(w, common.PickupDecoy)
+	i := w.decoyIndex(0)
+	decoy := w.Chars[i]
+	if decoy == nil || decoy.Vx == 0 && decoy.Vy == 0 {
+		t.Fatalf("got decoy %+v", decoy)
+	}
+	// run the AI as the hub does until the decoy's AI rests it
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		select {
+		case data := <-h.AIChan:
+			h.handleAI(data)
+			if data.Id == int32(i) && !data.Walk {
+				if decoy.Vx != 0 || decoy.Vy != 0 {
+					t.Fatalf("decoy walks on at (%d, %d)", decoy.Vx, decoy.Vy)
+				}
+				return
+			}
+		case <-time.After(10 * time.Millisecond):
+			if time.Now().After(deadline) {
+				t.Fatal("decoy has no AI")
+			}
+			clock.Advance(time.Second)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package server
+
+// PracticeConfig returns settings for an in-process, single-player
+// practice server.
+func PracticeConfig() Config {
+	cfg := DefaultConfig()
+	cfg.Insecure = true
+	cfg.AllowedOrigins = nil
+	cfg.MaxRooms = 1
+	cfg.PlayerSlots = 1
+	cfg.Mode = "practice"
+	return cfg
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+	"github.com/kisunji/ebiten-poc/transport"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPracticeSinglePlayer(t *testing.T) {
+	serverEnd, conn := transport.Pipe()
+	NewRooms(PracticeConfig()).Accept(serverEnd, "test", "")
+	defer conn.Close(transport.StatusNormalClosure, "")
+
+	start, err := proto.Marshal(&pb.ClientMessage{Content: &pb.ClientMessage_StartGame{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Write(context.Background(), start); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	var started bool
+	for {
+		data, err := conn.Read(ctx)
+		if err != nil {
+			break // deadline reached with the game still going
+		}
+		msg, err := common.DecodeServerMessage(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch msg.Content.(type) {
+		case *pb.ServerMessage_ConnectError:
+			t.Fatal(msg)
+		case *pb.ServerMessage_GameStart:
+			started = true
+		case *pb.ServerMessage_GameEnd:
+			t.Fatal("practice game ended with a single player alive")
+		}
+	}
+	if !started {
+		t.Fatal("game did not start")
+	}
+}
+
+func TestPracticeRoomIsFullWithOnePlayer(t *testing.T) {
+	cfg := PracticeConfig()
+	rs := NewRooms(cfg)
+	first := &Client{clientSlot: -1, Send: make(chan []byte, 256), cfg: cfg, log: rs.log}
+	second := &Client{clientSlot: -1, Send: make(chan []byte, 256), cfg: cfg, log: rs.log}
+	if !rs.join(first) {
+		t.Fatal("first player rejected")
+	}
+	if rs.join(second) {
+		t.Fatal("second player joined a practice server")
+	}
+}
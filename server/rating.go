@@ -0,0 +1,151 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/kisunji/ebiten-poc/common"
+)
+
+const (
+	// Rating given to a player before their first rated match.
+	initialRating = 1500
+	// Most a rating can move in one match.
+	ratingK = 32
+)
+
+// Ratings holds the rating of every player who has finished a rated match.
+// Ratings are not stored; they are rebuilt from the match history.
+type Ratings struct {
+	mu      sync.Mutex
+	players map[string]*common.PlayerRating
+}
+
+func NewRatings() *Ratings {
+	return &Ratings{players: make(map[string]*common.PlayerRating)}
+}
+
+// Update rates the named players of a finished match against each other.
+// Every pair counts as one game of Elo: the match winner beats everyone,
+// otherwise the higher score wins and equal scores draw. Matches cut short
+// by a shutdown or with fewer than two named players are not rated.
+func (r *Ratings) Update(rec MatchRecord) {
+	if rec.Reason == EndShutdown {
+		return
+	}
+	players := ratedPlayers(rec.Players)
+	if len(players) < 2 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ratings := make([]*common.PlayerRating, len(players))
+	for i, p := range players {
+		ratings[i] = r.players[p.Name]
+		if ratings[i] == nil {
+			ratings[i] = &common.PlayerRating{Name: p.Name, Rating: initialRating}
+			r.players[p.Name] = ratings[i]
+		}
+	}
+	// every player is rated against the others' ratings from before the match
+	deltas := make([]float64, len(players))
+	k := ratingK / float64(len(players)-1)
+	for i, a := range players {
+		for j, b := range players {
+			if i == j {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, (ratings[j].Rating-ratings[i].Rating)/400))
+			deltas[i] += k * (outcome(rec.Winner, a, b) - expected)
+		}
+	}
+	for i, p := range players {
+		ratings[i].Rating += deltas[i]
+		ratings[i].Matches++
+		if p.Slot == rec.Winner {
+			ratings[i].Wins++
+		}
+	}
+}
+
+// ratedPlayers returns the players with a name. A name used by more than
+// one player in the same match is left out, since they can't be told apart.
+func ratedPlayers(players []PlayerResult) []PlayerResult {
+	seen := make(map[string]int)
+	for _, p := range players {
+		seen[p.Name]++
+	}
+	var rated []PlayerResult
+	for _, p := range players {
+		if p.Name != "" && seen[p.Name] == 1 {
+			rated = append(rated, p)
+		}
+	}
+	return rated
+}
+
+// outcome scores a against b: 1 for a win, .5 for a draw and 0 for a loss.
+func outcome(winner int32, a, b PlayerResult) float64 {
+	switch {
+	case a.Slot == winner || a.Score > b.Score && b.Slot != winner:
+		return 1
+	case b.Slot == winner || a.Score < b.Score:
+		return 0
+	}
+	return .5
+}
+
+// Leaderboard returns up to limit players by rating, best first, and the
+// standing of name if it is not empty and has been rated.
+func (r *Ratings) Leaderboard(limit int, name string) common.Leaderboard {
+	r.mu.Lock()
+	all := make([]common.PlayerRating, 0, len(r.players))
+	for _, p := range r.players {
+		all = append(all, *p)
+	}
+	r.mu.Unlock()
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Rating != all[j].Rating {
+			return all[i].Rating > all[j].Rating
+		}
+		return all[i].Name < all[j].Name
+	})
+	lb := common.Leaderboard{Top: []common.RankedPlayer{}}
+	for i, p := range all {
+		ranked := common.RankedPlayer{Rank: i + 1, PlayerRating: p}
+		if i < limit {
+			lb.Top = append(lb.Top, ranked)
+		}
+		if name != "" && p.Name == name {
+			lb.Player = &ranked
+		}
+	}
+	return lb
+}
+
+// ServeLeaderboard lists the best rated players as JSON. With a player
+// query parameter that player's rank is included; limit caps the number
+// of top players.
+func (rs *Rooms) ServeLeaderboard(w http.ResponseWriter, r *http.Request) {
+	rs.allowCORS(w, r)
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, rs.ratings.Leaderboard(limit, r.URL.Query().Get("player")))
+}
+
+// allowCORS lets the browser client read the response when it is served
+// from one of the allowed origins.
+func (rs *Rooms) allowCORS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Origin")
+	if origin := r.Header.Get("Origin"); origin != "" && rs.cfg.originAllowed(origin) {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+}
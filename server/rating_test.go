@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kisunji/ebiten-poc/common"
+)
+
+func result(slot int32, name string, score int32) PlayerResult {
+	return PlayerResult{Slot: slot, Name: name, Score: score}
+}
+
+func ratingOf(t *testing.T, r *Ratings, name string) common.PlayerRating {
+	t.Helper()
+	lb := r.Leaderboard(maxMatchLimit, name)
+	if lb.Player == nil {
+		t.Fatalf("%s is not rated", name)
+	}
+	return lb.Player.PlayerRating
+}
+
+func TestRatingsWinnerTakesFromLoser(t *testing.T) {
+	r := NewRatings()
+	r.Update(MatchRecord{
+		Reason:  EndLastSurvivor,
+		Winner:  1,
+		Players: []PlayerResult{result(0, "alice", 5), result(1, "bob", 0)},
+	})
+	alice, bob := ratingOf(t, r, "alice"), ratingOf(t, r, "bob")
+	if bob.Rating != initialRating+ratingK/2 || alice.Rating != initialRating-ratingK/2 {
+		t.Fatalf("got alice %v, bob %v", alice.Rating, bob.Rating)
+	}
+	if bob.Wins != 1 || bob.Matches != 1 || alice.Wins != 0 || alice.Matches != 1 {
+		t.Fatalf("got alice %+v, bob %+v", alice, bob)
+	}
+
+	// an upset moves ratings further than an expected result
+	r.Update(MatchRecord{
+		Reason:  EndTimeout,
+		Winner:  0,
+		Players: []PlayerResult{result(0, "alice", 3), result(1, "bob", 1)},
+	})
+	if got := ratingOf(t, r, "alice").Rating - alice.Rating; got <= ratingK/2 {
+		t.Fatalf("upset gained %v", got)
+	}
+}
+
+func TestRatingsDraw(t *testing.T) {
+	r := NewRatings()
+	r.Update(MatchRecord{
+		Reason:  EndTimeout,
+		Winner:  -1,
+		Players: []PlayerResult{result(0, "alice", 2), result(1, "bob", 2), result(2, "carol", 0)},
+	})
+	alice, bob, carol := ratingOf(t, r, "alice"), ratingOf(t, r, "bob"), ratingOf(t, r, "carol")
+	if alice.Rating != bob.Rating || alice.Rating <= initialRating {
+		t.Fatalf("tied players got %v and %v", alice.Rating, bob.Rating)
+	}
+	if carol.Rating >= initialRating {
+		t.Fatalf("last place got %v", carol.Rating)
+	}
+	if total := alice.Rating + bob.Rating + carol.Rating; math.Abs(total-3*initialRating) > 1e-9 {
+		t.Fatalf("ratings do not add up: %v", total)
+	}
+}
+
+func TestRatingsSkipUnratedMatches(t *testing.T) {
+	r := NewRatings()
+	for _, rec := range []MatchRecord{
+		// anonymous players
+		{Reason: EndTimeout, Winner: 0, Players: []PlayerResult{result(0, "alice", 1), result(1, "", 0)}},
+		// both called bob
+		{Reason: EndTimeout, Winner: 0, Players: []PlayerResult{result(0, "bob", 1), result(1, "bob", 0)}},
+		// cut short
+		{Reason: EndShutdown, Winner: 0, Players: []PlayerResult{result(0, "alice", 1), result(1, "carol", 0)}},
+	} {
+		r.Update(rec)
+	}
+	if lb := r.Leaderboard(10, ""); len(lb.Top) != 0 {
+		t.Fatalf("rated %+v", lb.Top)
+	}
+}
+
+func TestSetHistoryReplaysRatings(t *testing.T) {
+	h := NewHistory()
+	h.Add(MatchRecord{
+		Reason:  EndLastSurvivor,
+		Winner:  0,
+		Players: []PlayerResult{result(0, "alice", 0), result(1, "bob", 0)},
+	})
+	rs := NewRooms(DefaultConfig())
+	rs.SetHistory(h)
+	if got := ratingOf(t, rs.ratings, "alice"); got.Rating <= initialRating || got.Wins != 1 {
+		t.Fatalf("alice %+v", got)
+	}
+}
+
+func TestServeLeaderboard(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AllowedOrigins = []string{"https://example.com"}
+	rs := NewRooms(cfg)
+	rs.saveMatch(MatchRecord{
+		Reason:  EndTimeout,
+		Winner:  2,
+		Players: []PlayerResult{result(0, "alice", 1), result(1, "bob", 0), result(2, "carol", 4)},
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/leaderboard?limit=2&player=bob", nil)
+	r.Header.Set("Origin", "https://example.com")
+	rec := httptest.NewRecorder()
+	rs.ServeLeaderboard(rec, r)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+		t.Fatalf("Access-Control-Allow-Origin %q", got)
+	}
+	var lb common.Leaderboard
+	if err := json.Unmarshal(rec.Body.Bytes(), &lb); err != nil {
+		t.Fatal(err)
+	}
+	if len(lb.Top) != 2 || lb.Top[0].Name != "carol" || lb.Top[0].Rank != 1 || lb.Top[1].Name != "alice" {
+		t.Fatalf("top %+v", lb.Top)
+	}
+	if lb.Player == nil || lb.Player.Name != "bob" || lb.Player.Rank != 3 {
+		t.Fatalf("player %+v", lb.Player)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/leaderboard", nil)
+	r.Header.Set("Origin", "https://evil.example")
+	rec = httptest.NewRecorder()
+	rs.ServeLeaderboard(rec, r)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("foreign origin allowed: %q", got)
+	}
+}
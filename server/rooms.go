@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+	"github.com/kisunji/ebiten-poc/transport"
+	"google.golang.org/protobuf/proto"
+)
+
+// Rooms places incoming connections into hubs, starting a new hub when
+// every existing one is full or mid-game.
+type Rooms struct {
+	cfg      Config
+	clock    Clock
+	metrics  *Metrics
+	log      *slog.Logger
+	upgrader websocket.Upgrader
+	// Finished matches.
+	history *History
+	// Player ratings, kept up to date with history.
+	ratings *Ratings
+	// Finished matches waiting for saveMatches, and closed once it has
+	// saved them all.
+	records chan MatchRecord
+	saved   chan struct{}
+
+	mu   sync.Mutex
+	hubs []*Hub
+	// Set by Shutdown to turn away new players.
+	draining bool
+
+	// Running WritePumps, so Shutdown can wait for close messages to go out.
+	writers sync.WaitGroup
+}
+
+func NewRooms(cfg Config) *Rooms {
+	rs := &Rooms{
+		cfg:     cfg,
+		clock:   realClock{},
+		metrics: NewMetrics(),
+		log:     slog.Default(),
+		history: NewHistory(),
+		ratings: NewRatings(),
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				if len(r.Header["Origin"]) == 0 {
+					return true
+				}
+				return cfg.originAllowed(r.Header["Origin"][0])
+			},
+		},
+		records: make(chan MatchRecord, recordBuffer),
+		saved:   make(chan struct{}),
+	}
+	go rs.saveMatches()
+	return rs
+}
+
+// SetHistory makes rs save finished matches to h instead of keeping them
+// in memory, and rates players from the matches already in h. Call it
+// before accepting connections.
+func (rs *Rooms) SetHistory(h *History) {
+	rs.history = h
+	rs.ratings = NewRatings()
+	for _, rec := range h.All() {
+		rs.ratings.Update(rec)
+	}
+}
+
+// ServeWs handles websocket requests from the peer. The player's name is
+// taken from the name query parameter.
+func (rs *Rooms) ServeWs(w http.ResponseWriter, r *http.Request) {
+	conn, err := rs.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		rs.log.Info("websocket upgrade failed", "remote", remoteIP(r.RemoteAddr), "err", err)
+		return
+	}
+	rs.Accept(newWSConn(conn, rs.cfg), r.RemoteAddr, r.URL.Query().Get("name"))
+}
+
+// Accept places a new connection in a room, or tells it why it cannot
+// join and hangs up. remote is the peer's address, for logs. name is
+// cleaned with common.CleanName and may be empty.
+func (rs *Rooms) Accept(conn transport.Conn, remote, name string) {
+	name = common.CleanName(name)
+	client := &Client{
+		Conn:       conn,
+		clientSlot: -1,
+		name:       name,
+		Send:       make(chan []byte, 256),
+		cfg:        rs.cfg,
+		metrics:    rs.metrics,
+		log:        rs.log.With("remote", remoteIP(remote)),
+	}
+	if name != "" {
+		client.log = client.log.With("name", name)
+	}
+	if !rs.join(client) {
+		reason := "all rooms are full"
+		if rs.isDraining() {
+			reason = "server is shutting down"
+		}
+		client.log.Info("rejecting player", "reason", reason)
+		reject(client, reason)
+		rs.startWritePump(client)
+		return
+	}
+
+	// Allow collection of memory referenced by the caller by doing all work in
+	// new goroutines.
+	rs.startWritePump(client)
+	go client.ReadPump()
+}
+
+func (rs *Rooms) startWritePump(client *Client) {
+	rs.writers.Add(1)
+	go func() {
+		defer rs.writers.Done()
+		client.WritePump()
+	}()
+}
+
+func (rs *Rooms) isDraining() bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.draining
+}
+
+// Shutdown turns away new players, tells everyone connected when the
+// server will stop and waits for running matches to finish. Matches still
+// running when ctx is done are ended early and ctx's error is returned.
+// Either way Shutdown returns once every connection has been closed and
+// every finished match has been saved.
+func (rs *Rooms) Shutdown(ctx context.Context) error {
+	rs.mu.Lock()
+	rs.draining = true
+	hubs := append([]*Hub(nil), rs.hubs...)
+	rs.mu.Unlock()
+
+	deadline, _ := ctx.Deadline()
+	for _, h := range hubs {
+		h.Drain(deadline)
+	}
+	var err error
+	for _, h := range hubs {
+		select {
+		case <-h.Done():
+		case <-ctx.Done():
+			err = ctx.Err()
+			h.Close()
+			<-h.Done()
+		}
+	}
+	// no hub is left to finish a match
+	close(rs.records)
+	<-rs.saved
+	rs.writers.Wait()
+	return err
+}
+
+// Info returns a snapshot of every room.
+func (rs *Rooms) Info() []RoomInfo {
+	hubs := rs.allHubs()
+	infos := make([]RoomInfo, 0, len(hubs))
+	for _, h := range hubs {
+		infos = append(infos, h.Info())
+	}
+	return infos
+}
+
+// join places client in the first room that takes it, opening a new room
+// if none does. Rooms are asked one at a time without holding rs.mu, since
+// each Join waits for that room's hub goroutine.
+func (rs *Rooms) join(client *Client) bool {
+	if rs.isDraining() {
+		return false
+	}
+	for _, h := range rs.allHubs() {
+		if h.Join(client) {
+			return true
+		}
+	}
+	h := rs.openRoom()
+	if h == nil {
+		return false
+	}
+	return h.Join(client)
+}
+
+// openRoom starts a new room, or returns nil if the server is shutting
+// down or already runs MaxRooms.
+func (rs *Rooms) openRoom() *Hub {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.draining || len(rs.hubs) >= rs.cfg.MaxRooms {
+		return nil
+	}
+	h := newHub(len(rs.hubs)+1, rs.cfg, rs.clock, rs.metrics, rs.log)
+	h.onMatchEnd = rs.recordMatch
+	go h.Run()
+	rs.hubs = append(rs.hubs, h)
+	h.log.Info("room created")
+	return h
+}
+
+// reject queues a ConnectError for a client that never joined a hub and
+// closes its send channel so WritePump hangs up after delivering it.
+func reject(client *Client, reason string) {
+	client.closeCode = transport.StatusTryAgainLater
+	client.closeReason = reason
+	resp := &pb.ServerMessage{
+		Content: &pb.ServerMessage_ConnectError{
+			ConnectError: &pb.ConnectError{
+				Message: reason,
+			},
+		},
+	}
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		client.log.Error("marshaling connect error", "err", err)
+	} else {
+		client.Send <- data
+		client.metrics.MessagesOut.Inc(messageType(resp))
+	}
+	close(client.Send)
+}
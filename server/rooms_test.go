@@ -0,0 +1,63 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoomsJoinOpensNewRoom(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PlayerSlots = 2
+	cfg.MaxRooms = 2
+	rs := NewRooms(cfg)
+
+	var hubs []*Hub
+	for i := 0; i < 4; i++ {
+		c := &Client{clientSlot: -1, Send: make(chan []byte, 256), cfg: cfg, log: rs.log}
+		if !rs.join(c) {
+			t.Fatalf("client %d was rejected", i)
+		}
+		hubs = append(hubs, c.Hub)
+	}
+	if hubs[0] != hubs[1] || hubs[2] != hubs[3] || hubs[0] == hubs[2] {
+		t.Fatalf("unexpected room assignment %v", hubs)
+	}
+	c := &Client{clientSlot: -1, Send: make(chan []byte, 256), cfg: cfg, log: rs.log}
+	if rs.join(c) {
+		t.Fatal("joined with every room full")
+	}
+}
+
+func TestRoomsJoinDoesNotHoldLock(t *testing.T) {
+	cfg := DefaultConfig()
+	rs := NewRooms(cfg)
+	// a room whose hub goroutine is busy, so Join waits on it
+	busy := newHub(1, cfg, rs.clock, rs.metrics, rs.log)
+	rs.hubs = append(rs.hubs, busy)
+
+	joined := make(chan bool)
+	c := &Client{clientSlot: -1, Send: make(chan []byte, 256), cfg: cfg, log: rs.log}
+	go func() {
+		joined <- rs.join(c)
+	}()
+	// let join reach the busy hub
+	time.Sleep(50 * time.Millisecond)
+	// shutdown checks and room listings go ahead meanwhile
+	done := make(chan struct{})
+	go func() {
+		rs.isDraining()
+		rs.allHubs()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("rooms locked while a hub is busy")
+	}
+
+	go busy.Run()
+	t.Cleanup(busy.Close)
+	if !<-joined || c.Hub != busy {
+		t.Fatal("client did not join the room once its hub was free")
+	}
+}
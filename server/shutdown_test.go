@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/pb"
+	"github.com/kisunji/ebiten-poc/transport"
+)
+
+// expectClosed waits for the server to hang up and returns the close code.
+func (p *testPlayer) expectClosed() transport.StatusCode {
+	p.t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-p.msgs:
+			if !ok {
+				_, err := p.conn.Read(context.Background())
+				var ce *transport.CloseError
+				if !errors.As(err, &ce) {
+					p.t.Fatalf("got %v, want a close error", err)
+				}
+				return ce.Code
+			}
+		case <-timeout:
+			p.t.Fatal("timed out waiting for close")
+		}
+	}
+}
+
+func startShutdown(rs *Rooms, timeout time.Duration) <-chan error {
+	errc := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		errc <- rs.Shutdown(ctx)
+	}()
+	return errc
+}
+
+func TestShutdownIdleRoom(t *testing.T) {
+	cfg := DefaultConfig()
+	rs := NewRooms(cfg)
+	p := connectPlayer(t, rs)
+	p.expect("connect response", isConnectResponse)
+
+	errc := startShutdown(rs, time.Minute)
+	n := p.expect("notice", func(m *pb.ServerMessage) bool {
+		return m.GetServerNotice() != nil
+	}).GetServerNotice()
+	if n.Countdown < 59 || n.Countdown > 60 {
+		t.Fatalf("got countdown %d", n.Countdown)
+	}
+	if code := p.expectClosed(); code != transport.StatusGoingAway {
+		t.Fatalf("got close code %d", code)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+
+	late := connectPlayer(t, rs)
+	ce := late.expect("rejection", func(m *pb.ServerMessage) bool { return true }).GetConnectError()
+	if ce == nil || ce.Message != "server is shutting down" {
+		t.Fatalf("got %v", ce)
+	}
+}
+
+func TestShutdownWaitsForMatch(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PlayerSlots = 2
+	clock := newFakeClock()
+	rs := NewRooms(cfg)
+	rs.clock = clock
+	host := connectPlayer(t, rs)
+	host.expect("connect response", isConnectResponse)
+	guest := connectPlayer(t, rs)
+	guest.expect("connect response", isConnectResponse)
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_StartGame{}})
+	host.expect("game start", func(m *pb.ServerMessage) bool {
+		return m.GetGameStart() != nil
+	})
+
+	errc := startShutdown(rs, 2*time.Second)
+	host.expect("notice", func(m *pb.ServerMessage) bool {
+		return m.GetServerNotice() != nil
+	})
+	select {
+	case err := <-errc:
+		t.Fatalf("shutdown returned %v during the match", err)
+	default:
+	}
+
+	h := rs.hubs[0]
+	h.do(func() {
+		h.world.Chars[1].IsDead = true
+	})
+	clock.Advance(cfg.tickDuration())
+	for _, p := range []*testPlayer{host, guest} {
+		p.expect("game end", func(m *pb.ServerMessage) bool {
+			return m.GetGameEnd() != nil
+		})
+		if code := p.expectClosed(); code != transport.StatusGoingAway {
+			t.Fatalf("got close code %d", code)
+		}
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if n := rs.metrics.AIGoroutines.Value(); n != 0 {
+		t.Fatalf("%d AI goroutines still running", n)
+	}
+}
+
+func TestShutdownEndsMatchAtDeadline(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PlayerSlots = 2
+	rs := NewRooms(cfg)
+	rs.clock = newFakeClock()
+	host := connectPlayer(t, rs)
+	host.expect("connect response", isConnectResponse)
+	guest := connectPlayer(t, rs)
+	guest.expect("connect response", isConnectResponse)
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_StartGame{}})
+	host.expect("game start", func(m *pb.ServerMessage) bool {
+		return m.GetGameStart() != nil
+	})
+
+	errc := startShutdown(rs, 50*time.Millisecond)
+	end := guest.expect("game end", func(m *pb.ServerMessage) bool {
+		return m.GetGameEnd() != nil
+	}).GetGameEnd()
+	if len(end.Score) == 0 {
+		t.Fatal("match ended early without scores")
+	}
+	if code := guest.expectClosed(); code != transport.StatusGoingAway {
+		t.Fatalf("got close code %d", code)
+	}
+	if err := <-errc; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want deadline exceeded", err)
+	}
+	if n := rs.metrics.AIGoroutines.Value(); n != 0 {
+		t.Fatalf("%d AI goroutines still running", n)
+	}
+}
+
+func TestShutdownAfterAdminEndsMatch(t *testing.T) {
+	rs, host, guest := newAdminRoom(t)
+	rs.clock = newFakeClock()
+	host.send(&pb.ClientMessage{Content: &pb.ClientMessage_StartGame{}})
+	host.expect("game start", func(m *pb.ServerMessage) bool {
+		return m.GetGameStart() != nil
+	})
+
+	errc := startShutdown(rs, 2*time.Second)
+	host.expect("notice", func(m *pb.ServerMessage) bool {
+		return m.GetServerNotice() != nil
+	})
+	if rec := adminRequest(t, rs, "POST", "/admin/rooms/1/end", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+	}
+	for _, p := range []*testPlayer{host, guest} {
+		p.expect("game end", func(m *pb.ServerMessage) bool {
+			return m.GetGameEnd() != nil
+		})
+		if code := p.expectClosed(); code != transport.StatusGoingAway {
+			t.Fatalf("got close code %d", code)
+		}
+	}
+	// well before the deadline
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kisunji/ebiten-poc/transport"
+)
+
+// wsConn adapts a gorilla websocket to transport.Conn. Read deadlines are
+// extended by pongs, so a peer that stops answering pings is dropped.
+type wsConn struct {
+	conn *websocket.Conn
+	cfg  Config
+}
+
+func newWSConn(conn *websocket.Conn, cfg Config) *wsConn {
+	conn.SetReadLimit(cfg.MaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+		return nil
+	})
+	return &wsConn{conn: conn, cfg: cfg}
+}
+
+func (c *wsConn) Read(ctx context.Context) ([]byte, error) {
+	_, buf, err := c.conn.ReadMessage()
+	var ce *websocket.CloseError
+	if errors.As(err, &ce) {
+		return nil, &transport.CloseError{Code: transport.StatusCode(ce.Code), Reason: ce.Text}
+	}
+	return buf, err
+}
+
+func (c *wsConn) Write(ctx context.Context, data []byte) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteWait))
+	w, err := c.conn.NextWriter(websocket.BinaryMessage)
+	if err != nil {
+		return err
+	}
+	w.Write(data)
+	return w.Close()
+}
+
+func (c *wsConn) Ping(ctx context.Context) error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteWait))
+	return c.conn.WriteMessage(websocket.PingMessage, nil)
+}
+
+func (c *wsConn) Close(code transport.StatusCode, reason string) error {
+	msg := websocket.FormatCloseMessage(int(code), reason)
+	_ = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(c.cfg.WriteWait))
+	return c.conn.Close()
+}
@@ -1,35 +1,58 @@
 package server
 
 import (
-	"log"
+	"log/slog"
 	"math"
 	"math/rand"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/kisunji/ebiten-poc/common"
 	"github.com/kisunji/ebiten-poc/pb"
-	"google.golang.org/protobuf/proto"
 )
 
-const (
-	updateFrequency = 1 * time.Second / 60
-)
+// gridCellSize is the size of the cells characters and coins are indexed
+// in, in pixels. It is close to the hit and pickup radii.
+const gridCellSize = 32
 
-func NewWorld(broadcast chan []byte) *World {
+// NewWorld creates an idle world. send delivers a message to every client
+// in the room and sendTo to a single player.
+func NewWorld(cfg Config, clock Clock, metrics *Metrics, log *slog.Logger, send func(*pb.ServerMessage)) *World {
 	return &World{
 		Running:     false,
-		Chars:       make(common.Chars, common.MaxChars),
+		Chars:       make(common.Chars, cfg.CrowdSize+cfg.PlayerSlots),
 		tick:        0,
-		PlayerSlots: make([]bool, common.MaxClients),
-		Score:       make([]int32, common.MaxClients),
+		PlayerSlots: make([]bool, cfg.PlayerSlots),
+		Score:       make([]int32, cfg.PlayerSlots),
+		Kills:       make([]int32, cfg.PlayerSlots),
+		Names:       make([]string, cfg.PlayerSlots),
 		AIs:         make([]*AI, 0),
-		broadcast:   broadcast,
+		clock:       clock,
+		metrics:     metrics,
+		log:         log,
+		send:        send,
+		coinDue:     make(chan struct{}),
+		pickupDue:   make(chan struct{}),
 		killSig:     make(chan struct{}),
-		duration:    3 * time.Minute,
+		duration:    cfg.MatchLength,
+		tickLength:  cfg.tickDuration(),
+		mode:        Modes[cfg.Mode],
+		timing:      cfg.attackTiming(),
+		arena:       common.Arenas[cfg.Map],
+
+		pickupInterval: cfg.PickupInterval,
+		coinPattern:    CoinPatterns[cfg.CoinPattern],
+		maxCoins:       cfg.MaxCoins,
+		coinLifetime:   cfg.CoinLifetime,
 	}
 }
 
+// World holds the state of a match. It is owned by the hub goroutine;
+// the AI and coin goroutines only send it signals.
 type World struct {
+	// Identifies the match in logs. Set by Setup.
+	ID          string
 	Running     bool
 	Chars       common.Chars
 	Coins       []*common.Coin
@@ -38,164 +61,227 @@ type World struct {
 	PlayerSlots []bool
 	HostSlot    int32
 	AIs         []*AI
-	broadcast   chan []byte
-	killSig     chan struct{}
-	startTime   time.Time
-	duration    time.Duration
+	clock       Clock
+	metrics     *Metrics
+	// Logs with the room and, once Setup has run, the match attached.
+	log  *slog.Logger
+	send func(*pb.ServerMessage)
+	// Delivers a message to the client in one player slot.
+	sendTo func(slot int32, msg *pb.ServerMessage)
+	// signalled when the next coin or power-up should appear
+	coinDue   chan struct{}
+	pickupDue chan struct{}
+	// Longest wait between power-ups; zero for none.
+	pickupInterval time.Duration
+	// Where and how often coins appear, how many may lie around and how
+	// long each lasts, zero for ever.
+	coinPattern  CoinPattern
+	maxCoins     int
+	coinLifetime time.Duration
+	// closed to stop the AI and coin goroutines
+	killSig  chan struct{}
+	stopOnce sync.Once
+	// tracks the AI and coin goroutines
+	goroutines sync.WaitGroup
+	startTime  time.Time
+	duration   time.Duration
+	tickLength time.Duration
+	mode       Mode
+	// Length of each attack phase, in ticks.
+	timing common.AttackTiming
+	// Map the match is played on.
+	arena *common.Arena
+
+	// Players each player has killed.
+	Kills []int32
+	// Player names by slot, filled in before Setup.
+	Names []string
+	// Player slots taken when the match started.
+	participants []int32
+	// When each player's stun wears off, and the input they last sent
+	// while stunned.
+	stunnedUntil []time.Time
+	heldInput    []*pb.Input
+	// Whether each player has already been penalized during the active
+	// phase of their current attack.
+	penalized []bool
+	// When each coin expires, by index; zero for never. Collected and
+	// expired coins make way for new ones.
+	coinUntil []time.Time
+	// Spots the hotspots coin pattern gathers coins around.
+	hotspots [][2]float64
+	// Power-ups lying in the arena or collected, by index. Collected
+	// ones make way for new ones.
+	Pickups []*common.Pickup
+	// When each player's power-up effects wear off, by kind.
+	effectUntil [][common.NumPickupKinds]time.Time
+	// The AI each player swapped places with while disguised, or -1.
+	disguisedAs []int
+	// Which characters each player can see, when the mode limits vision.
+	visible [][]bool
+	// Where characters and uncollected coins are, rebuilt every tick.
+	charGrid *common.Grid
+	coinGrid *common.Grid
+	// Scratch space for grid queries.
+	near   []int
+	inView []bool
+	// Set when the match ends.
+	endTime   time.Time
+	endReason string
+	survivor  int32
 }
 
+// Setup creates the characters, starts the AI and coin goroutines and
+// starts the match clock.
 func (w *World) Setup(aiChan chan AIData) {
-	for i := 0; i < common.MaxChars; i++ {
-		if i < common.MaxClients && w.PlayerSlots[i] {
-			w.Chars[i] = common.NewChar()
-		} else {
-			char := common.NewChar()
-			w.Chars[i] = char
+	w.ID = newMatchID()
+	w.log = w.log.With("match", w.ID)
+	w.participants = nil
+	players, crowd := len(w.PlayerSlots), len(w.Chars)
+	w.stunnedUntil = make([]time.Time, players)
+	w.effectUntil = make([][common.NumPickupKinds]time.Time, players)
+	w.disguisedAs = make([]int, players)
+	for i := range w.disguisedAs {
+		w.disguisedAs[i] = -1
+	}
+	w.heldInput = make([]*pb.Input, players)
+	w.penalized = make([]bool, players)
+	w.visible = make([][]bool, players)
+	for i := range w.visible {
+		w.visible[i] = make([]bool, crowd)
+	}
+	w.inView = make([]bool, crowd)
+	w.charGrid = common.NewGrid(w.arena.PixelWidth(), w.arena.PixelHeight(), gridCellSize)
+	w.coinGrid = common.NewGrid(w.arena.PixelWidth(), w.arena.PixelHeight(), gridCellSize)
+	for i, isPlayer := range w.PlayerSlots {
+		if isPlayer {
+			w.participants = append(w.participants, int32(i))
+		}
+	}
+	// decoy slots stay empty until a decoy is collected
+	for i := 0; i < crowd-players; i++ {
+		char := common.NewChar()
+		char.Px, char.Py = w.arena.RandomSpot()
+		char.Timing = w.timing
+		w.Chars[i] = char
+		if !w.isPlayer(i) {
 			ai := &AI{
-				Char:    char,
-				id:      int32(i),
-				killSig: make(chan struct{}),
+				Char: char,
+				id:   int32(i),
 			}
 			w.AIs = append(w.AIs, ai)
-			go w.RunAI(ai, aiChan)
+			w.goroutines.Add(1)
+			go func() {
+				defer w.goroutines.Done()
+				w.RunAI(ai, aiChan)
+			}()
 		}
 	}
-	go w.makeCoins()
-}
-
-func (w *World) makeCoins() {
-	timer := time.NewTimer(time.Duration(rand.Intn(5000)) * time.Millisecond)
-	defer func() {
-		log.Println("stopping makeCoins")
-		timer.Stop()
-	}()
-	for {
-		select {
-		case <-timer.C:
-			if !w.Running {
-				continue
-			}
-			coin := common.NewCoin()
-			w.Coins = append(w.Coins, coin)
-			msg := &pb.ServerMessage{
-				Content: &pb.ServerMessage_NewCoin{
-					NewCoin: &pb.NewCoin{
-						Index:       int32(len(w.Coins) - 1),
-						Px:          coin.Px,
-						Py:          coin.Py,
-						FrameOffset: int32(coin.FrameOffset),
-					},
-				},
-			}
-			bytes, err := proto.Marshal(msg)
-			if err != nil {
-				log.Fatal(err)
-			}
-			w.broadcast <- bytes
-			timer.Reset(time.Duration(rand.Intn(5000)) * time.Millisecond)
-		case <-w.killSig:
-			return
-		}
+	// decoys walk like the crowd while they are out; the hub ignores
+	// their AI while the slot is empty
+	for i := crowd - players; i < crowd; i++ {
+		ai := &AI{id: int32(i)}
+		w.goroutines.Add(1)
+		go func() {
+			defer w.goroutines.Done()
+			w.RunAI(ai, aiChan)
+		}()
 	}
+	w.spawnEvery(w.coinDue, w.coinPattern.Interval, "coins")
+	if w.pickupInterval > 0 {
+		w.spawnEvery(w.pickupDue, w.pickupInterval, "pickups")
+	}
+	w.startTime = w.clock.Now()
+	w.Running = true
 }
 
-// Run should be called in a goroutine
-func (w *World) Run() {
-	w.startTime = time.Now()
-	w.Running = true
-	previous := time.Now()
-	var lag time.Duration
+// stop ends the match and its goroutines. It is safe to call more than once.
+func (w *World) stop() {
+	w.Running = false
+	w.stopOnce.Do(func() {
+		close(w.killSig)
+		w.log.Debug("stopping world")
+	})
+}
 
-	for w.Running {
-		current := time.Now()
-		elapsed := current.Sub(previous)
-		previous = current
-		lag += elapsed
+// wait blocks until the goroutines started by Setup have returned. Call
+// stop first.
+func (w *World) wait() {
+	w.goroutines.Wait()
+}
 
-		for lag >= updateFrequency {
-			w.update()
-			lag -= updateFrequency
-		}
+// spawnEvery starts a goroutine that signals due after random waits of
+// up to interval until the world stops. what names it in logs.
+func (w *World) spawnEvery(due chan<- struct{}, interval time.Duration, what string) {
+	next := func() time.Duration {
+		return time.Duration(rand.Int63n(int64(interval)))
 	}
-	for _, ai := range w.AIs {
-		ai.killSig <- struct{}{}
-	}
-	w.killSig <- struct{}{}
-	log.Println("stopping world")
+	timer := w.clock.NewTimer(next())
+	w.goroutines.Add(1)
+	go func() {
+		defer func() {
+			w.log.Debug("stopping spawner", "spawns", what)
+			timer.Stop()
+			w.goroutines.Done()
+		}()
+		for {
+			select {
+			case <-timer.C():
+				select {
+				case due <- struct{}{}:
+				case <-w.killSig:
+					return
+				}
+				timer.Reset(next())
+			case <-w.killSig:
+				return
+			}
+		}
+	}()
 }
 
 func (w *World) update() {
+	w.tick++
+	w.endStuns()
+	w.endEffects()
+	// kept up to date as characters move below
+	w.charGrid.Clear()
 	for i, char := range w.Chars {
-		if char == nil || char.IsDead {
-			continue
+		if char != nil {
+			w.charGrid.Insert(i, char.Px, char.Py)
 		}
-		if char.Attacking() {
-			char.Attack()
-			// reached end of animation
-			if !char.Attacking() {
-				x0, y0 := char.ImpactSite(common.HitRadius)
-				for j, isPlayer := range w.PlayerSlots {
-					if !isPlayer || i == j {
-						continue
-					}
-					target := w.Chars[j]
-					if target.IsDead {
-						continue
-					}
-					if isHit(x0, y0, target.Px, target.Py, common.HitRadius) {
-						target.IsDead = true
-						ue := &pb.ServerMessage{
-							Content: &pb.ServerMessage_UpdateEntity{
-								UpdateEntity: &pb.UpdateEntity{
-									Index:  int32(j),
-									Fx:     int32(target.Fx),
-									Fy:     int32(target.Fy),
-									Px:     target.Px,
-									Py:     target.Py,
-									Speed:  int32(target.Speed),
-									IsDead: true,
-								},
-							},
-						}
-						bytes, err := proto.Marshal(ue)
-						if err != nil {
-							log.Fatalln("client connect: marshaling error: ", err)
-						}
-						w.broadcast <- bytes
-					}
-				}
-			}
-		}
-		char.Move()
 	}
-	for i, coin := range w.Coins {
-		if coin.PickedUp {
+	for i, char := range w.Chars {
+		if char == nil || char.IsDead {
 			continue
 		}
-		for j, isPlayer := range w.PlayerSlots {
-			if !isPlayer {
-				continue
-			}
-			target := w.Chars[j]
-			if target.IsDead {
-				continue
-			}
-			if isHit(target.Px, target.Py, coin.Px, coin.Py, coin.PickupRadius) {
-				w.Score[j]++
-				w.Coins[i].PickedUp = true
-				ue := &pb.ServerMessage{
-					Content: &pb.ServerMessage_CoinGot{
-						CoinGot: &pb.CoinGot{Index: int32(i)},
-					},
-				}
-				bytes, err := proto.Marshal(ue)
-				if err != nil {
-					log.Fatalln("client connect: marshaling error: ", err)
-				}
-				w.broadcast <- bytes
+		wasAttacking := char.Attacking()
+		char.Attack()
+		if char.AttackPhase == common.AttackActive {
+			w.resolveAttack(i, char)
+		} else if i < len(w.penalized) {
+			w.penalized[i] = false
+		}
+		if wasAttacking && !char.Attacking() {
+			// movement held during the attack starts now, unless a penalty
+			// has stunned the attacker. Without a cooldown the attack
+			// goes straight back to idle.
+			if w.stunned(int32(i)) {
+				char.Vx, char.Vy = 0, 0
 			}
+			w.sendEntity(i)
 		}
+		if char.Exert() {
+			w.sendEntity(i)
+		}
+		x, y := char.Px, char.Py
+		char.Move(w.arena)
+		w.charGrid.Move(i, x, y, char.Px, char.Py)
 	}
+	w.expireCoins()
+	w.collectCoins()
+	w.collectPickups()
+	w.updateVisibility()
 	var alive []int
 	for j, isPlayer := range w.PlayerSlots {
 		if !isPlayer {
@@ -206,38 +292,280 @@ func (w *World) update() {
 			alive = append(alive, j)
 		}
 	}
-	if len(alive) == 1 {
-		ge := &pb.ServerMessage{
+	if w.mode.LastSurvivorWins && len(alive) == 1 {
+		w.log.Info("match over", "survivor", alive[0])
+		w.survivor = int32(alive[0])
+		w.endReason = EndLastSurvivor
+		w.endTime = w.clock.Now()
+		w.send(&pb.ServerMessage{
 			Content: &pb.ServerMessage_GameEnd{
 				GameEnd: &pb.GameEnd{
 					Survivor: int32(alive[0]),
+					Kills:    w.Kills,
 				},
 			},
+		})
+		w.stop()
+		return
+	}
+	if w.clock.Now().Sub(w.startTime) > w.duration {
+		w.end(EndTimeout)
+	}
+}
+
+// resolveAttack kills whoever stands at the impact site of char's attack
+// while it is active. A player who kills an AI pays the mode's AIPenalty,
+// once per attack.
+func (w *World) resolveAttack(i int, char *common.Char) {
+	reach := common.HitRadius
+	if w.effectActive(i, common.PickupReach) {
+		reach *= common.ReachBoost
+	}
+	x0, y0 := char.ImpactSite(reach)
+	var hitAI bool
+	w.near = w.charGrid.Near(w.near[:0], x0, y0, reach)
+	sort.Ints(w.near)
+	for _, j := range w.near {
+		target := w.Chars[j]
+		if i == j || target.IsDead {
+			continue
+		}
+		if !isHit(x0, y0, target.Px, target.Py, reach) {
+			continue
 		}
-		bytes, err := proto.Marshal(ge)
-		if err != nil {
-			log.Fatalln("client connect: marshaling error: ", err)
+		target.IsDead = true
+		w.sendEntity(j)
+		if !w.isPlayer(j) {
+			hitAI = true
+			continue
 		}
-		w.broadcast <- bytes
-		w.Running = false
+		if i < len(w.Kills) {
+			w.Kills[i]++
+		}
+		w.log.Debug("player killed", "killer", i, "victim", j)
+		w.sendKill(&pb.PlayerKilled{
+			Killer: int32(i),
+			Victim: int32(j),
+			Tick:   w.tick,
+			Px:     target.Px,
+			Py:     target.Py,
+		})
 	}
-	if time.Since(w.startTime) > w.duration {
-		ge := &pb.ServerMessage{
-			Content: &pb.ServerMessage_GameEnd{
-				GameEnd: &pb.GameEnd{
-					Score:    w.Score,
-				},
+	if hitAI && w.isPlayer(i) && !w.penalized[i] {
+		w.penalized[i] = true
+		w.penalize(i)
+	}
+}
+
+func (w *World) isPlayer(i int) bool {
+	return i < len(w.PlayerSlots) && w.PlayerSlots[i]
+}
+
+// penalize applies the mode's AIPenalty to the player in slot and tells
+// every client.
+func (w *World) penalize(slot int) {
+	p := w.mode.AIPenalty
+	loss := p.ScoreLoss
+	if loss > w.Score[slot] {
+		loss = w.Score[slot]
+	}
+	w.Score[slot] -= loss
+	if p.Stun > 0 {
+		w.stunnedUntil[slot] = w.clock.Now().Add(p.Stun)
+		char := w.Chars[slot]
+		char.Vx, char.Vy = 0, 0
+		w.sendEntity(slot)
+	}
+	w.log.Debug("player penalized", "slot", slot, "score_loss", loss)
+	w.send(&pb.ServerMessage{
+		Content: &pb.ServerMessage_PlayerPenalized{
+			PlayerPenalized: &pb.PlayerPenalized{
+				Index:        int32(slot),
+				ScoreLoss:    loss,
+				StunMillis:   int32(p.Stun.Milliseconds()),
+				RevealMillis: int32(p.Reveal.Milliseconds()),
+			},
+		},
+	})
+}
+
+// stunned reports whether the player in slot is serving a stun and must
+// not move or attack.
+func (w *World) stunned(slot int32) bool {
+	return int(slot) < len(w.stunnedUntil) && w.clock.Now().Before(w.stunnedUntil[slot])
+}
+
+// endStuns lifts stuns that have worn off and applies the last input each
+// player sent while stunned.
+func (w *World) endStuns() {
+	now := w.clock.Now()
+	for i, until := range w.stunnedUntil {
+		if until.IsZero() || now.Before(until) {
+			continue
+		}
+		w.stunnedUntil[i] = time.Time{}
+		if input := w.heldInput[i]; input != nil {
+			w.heldInput[i] = nil
+			w.Chars[i].ProcessInput(input)
+			w.sendEntity(i)
+		}
+	}
+}
+
+// sendEntity tells every player who can see character i about its state.
+func (w *World) sendEntity(i int) {
+	msg := entityUpdate(i, w.Chars[i])
+	if w.mode.Vision <= 0 {
+		w.send(msg)
+		return
+	}
+	for p, isPlayer := range w.PlayerSlots {
+		if isPlayer && w.visibleTo(int32(p), i) {
+			w.sendTo(int32(p), msg)
+		}
+	}
+}
+
+// sendKill adds a kill to every player's feed. When the mode limits
+// vision, only players who can see the victim learn where it fell.
+func (w *World) sendKill(pk *pb.PlayerKilled) {
+	msg := &pb.ServerMessage{Content: &pb.ServerMessage_PlayerKilled{PlayerKilled: pk}}
+	if w.mode.Vision <= 0 {
+		w.send(msg)
+		return
+	}
+	unseen := &pb.ServerMessage{
+		Content: &pb.ServerMessage_PlayerKilled{
+			PlayerKilled: &pb.PlayerKilled{
+				Killer: pk.Killer,
+				Victim: pk.Victim,
+				Tick:   pk.Tick,
 			},
+		},
+	}
+	for p, isPlayer := range w.PlayerSlots {
+		if !isPlayer {
+			continue
+		}
+		if w.visibleTo(int32(p), int(pk.Victim)) {
+			w.sendTo(int32(p), msg)
+		} else {
+			w.sendTo(int32(p), unseen)
+		}
+	}
+}
+
+// visibleTo reports whether the player in slot can currently see
+// character i.
+func (w *World) visibleTo(slot int32, i int) bool {
+	if w.mode.Vision <= 0 {
+		return true
+	}
+	return int(slot) < len(w.visible) && w.visible[slot][i]
+}
+
+// sees reports whether player p has character i in view: within the
+// mode's vision radius with no wall in between. Players always see
+// themselves, and dead players watch the whole arena.
+func (w *World) sees(p, i int) bool {
+	viewer, target := w.Chars[p], w.Chars[i]
+	if p == i || viewer.IsDead {
+		return true
+	}
+	if !isHit(viewer.Px, viewer.Py, target.Px, target.Py, w.mode.Vision) {
+		return false
+	}
+	return w.arena.LineOfSight(viewer.Px, viewer.Py, target.Px, target.Py)
+}
+
+// updateVisibility works out who each player can see. Characters coming
+// into view are sent in full; those leaving it are sent as hidden so the
+// client stops drawing them.
+func (w *World) updateVisibility() {
+	if w.mode.Vision <= 0 {
+		return
+	}
+	for p, isPlayer := range w.PlayerSlots {
+		if !isPlayer {
+			continue
+		}
+		viewer := w.Chars[p]
+		for i := range w.inView {
+			w.inView[i] = (viewer.IsDead || i == p) && w.Chars[i] != nil
+		}
+		if !viewer.IsDead {
+			w.near = w.charGrid.Near(w.near[:0], viewer.Px, viewer.Py, w.mode.Vision)
+			for _, i := range w.near {
+				w.inView[i] = w.sees(p, i)
+			}
 		}
-		bytes, err := proto.Marshal(ge)
-		if err != nil {
-			log.Fatalln("client connect: marshaling error: ", err)
+		for i, sees := range w.inView {
+			if sees == w.visible[p][i] {
+				continue
+			}
+			w.visible[p][i] = sees
+			if sees {
+				w.sendTo(int32(p), entityUpdate(i, w.Chars[i]))
+				continue
+			}
+			w.sendTo(int32(p), hiddenUpdate(i))
 		}
-		w.broadcast <- bytes
-		w.Running = false
 	}
 }
 
+// hiddenUpdate tells a client to forget character i, because it is out
+// of sight or gone.
+func hiddenUpdate(i int) *pb.ServerMessage {
+	return &pb.ServerMessage{
+		Content: &pb.ServerMessage_UpdateEntity{
+			UpdateEntity: &pb.UpdateEntity{
+				Index:  int32(i),
+				Hidden: true,
+			},
+		},
+	}
+}
+
+// entityUpdate describes character i for clients.
+func entityUpdate(i int, c *common.Char) *pb.ServerMessage {
+	return &pb.ServerMessage{
+		Content: &pb.ServerMessage_UpdateEntity{
+			UpdateEntity: &pb.UpdateEntity{
+				Index:       int32(i),
+				Fx:          int32(c.Fx),
+				Fy:          int32(c.Fy),
+				Vx:          int32(c.Vx),
+				Vy:          int32(c.Vy),
+				Px:          c.Px,
+				Py:          c.Py,
+				Speed:       int32(c.Speed),
+				Stamina:     int32(c.Stamina),
+				Boost:       int32(c.Boost),
+				AttackFrame: int32(c.AttackFrame),
+				AttackPhase: int32(c.AttackPhase),
+				IsDead:      c.IsDead,
+			},
+		},
+	}
+}
+
+// end announces the scores and stops the match. reason says why it ended,
+// for the match history.
+func (w *World) end(reason string) {
+	w.log.Info("match over", "score", w.Score, "reason", reason)
+	w.endReason = reason
+	w.endTime = w.clock.Now()
+	w.send(&pb.ServerMessage{
+		Content: &pb.ServerMessage_GameEnd{
+			GameEnd: &pb.GameEnd{
+				Score: w.Score,
+				Kills: w.Kills,
+			},
+		},
+	})
+	w.stop()
+}
+
 func isHit(x0, y0, x1, y1, radius float64) bool {
 	dx := x1 - x0
 	dy := y1 - y0
@@ -0,0 +1,505 @@
+package server
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/kisunji/ebiten-poc/common"
+	"github.com/kisunji/ebiten-poc/pb"
+)
+
+// newTestWorld starts a match with the given player slots taken. Messages
+// the world sends are collected in the returned slice.
+func newTestWorld(t *testing.T, cfg Config, players ...int) (*World, *fakeClock, *[]*pb.ServerMessage) {
+	t.Helper()
+	clock := newFakeClock()
+	var sent []*pb.ServerMessage
+	w := NewWorld(cfg, clock, NewMetrics(), slog.Default(), func(m *pb.ServerMessage) {
+		sent = append(sent, m)
+	})
+	w.sendTo = func(_ int32, m *pb.ServerMessage) {
+		sent = append(sent, m)
+	}
+	for _, p := range players {
+		w.PlayerSlots[p] = true
+	}
+	w.Setup(make(chan AIData))
+	t.Cleanup(w.stop)
+	return w, clock, &sent
+}
+
+// placeApart moves every character far from (x, y) so only the ones a
+// test positions explicitly can interact.
+func placeApart(w *World) {
+	for _, c := range w.Chars {
+		if c == nil {
+			continue
+		}
+		c.Px, c.Py = 1000, 1000
+		c.Vx, c.Vy = 0, 0
+	}
+}
+
+func findGameEnd(msgs []*pb.ServerMessage) *pb.GameEnd {
+	for _, m := range msgs {
+		if ge := m.GetGameEnd(); ge != nil {
+			return ge
+		}
+	}
+	return nil
+}
+
+func TestAttackKillsPlayer(t *testing.T) {
+	w, _, sent := newTestWorld(t, DefaultConfig(), 0, 1, 2)
+	placeApart(w)
+	attacker, victim := w.Chars[0], w.Chars[1]
+	attacker.Px, attacker.Py = 100, 100
+	attacker.Fx, attacker.Fy = 1, 0
+	victim.Px, victim.Py = 100+common.HitRadius, 100
+
+	attacker.ProcessInput(&pb.Input{ActionPressed: true})
+	for attacker.AttackPhase == common.AttackWindUp {
+		if victim.IsDead {
+			t.Fatal("victim died during the wind-up")
+		}
+		w.update()
+	}
+	if attacker.AttackPhase != common.AttackActive || !victim.IsDead {
+		t.Fatal("victim survived the active window")
+	}
+	killTick := w.tick
+	for attacker.Attacking() {
+		w.update()
+	}
+	if w.Chars[2].IsDead || attacker.IsDead {
+		t.Fatal("bystander died")
+	}
+	var announced bool
+	var killed *pb.PlayerKilled
+	for _, m := range *sent {
+		if ue := m.GetUpdateEntity(); ue != nil && ue.Index == 1 && ue.IsDead {
+			announced = true
+		}
+		if pk := m.GetPlayerKilled(); pk != nil {
+			killed = pk
+		}
+	}
+	if !announced {
+		t.Fatal("death was not announced")
+	}
+	if killed == nil || killed.Killer != 0 || killed.Victim != 1 || killed.Tick != killTick ||
+		killed.Px != victim.Px || killed.Py != victim.Py {
+		t.Fatalf("got PlayerKilled %v", killed)
+	}
+	if w.Kills[0] != 1 || w.Kills[1] != 0 {
+		t.Fatalf("unexpected kills %v", w.Kills)
+	}
+	if !w.Running {
+		t.Fatal("match ended with two players alive")
+	}
+}
+
+func TestAttackCooldown(t *testing.T) {
+	w, _, sent := newTestWorld(t, DefaultConfig(), 0, 1)
+	placeApart(w)
+	attacker := w.Chars[0]
+	attacker.Px, attacker.Py = 100, 100
+	attacker.Fx, attacker.Fy = 1, 0
+
+	attacker.ProcessInput(&pb.Input{ActionPressed: true})
+	attacker.ProcessInput(&pb.Input{DownPressed: true})
+	for attacker.Attacking() {
+		if attacker.Vy != 0 {
+			t.Fatal("moved during the attack")
+		}
+		w.update()
+	}
+	if attacker.AttackPhase != common.AttackCooldown || attacker.Vy != 1 {
+		t.Fatalf("got phase %d and vy %d after recovery", attacker.AttackPhase, attacker.Vy)
+	}
+	last := (*sent)[len(*sent)-1].GetUpdateEntity()
+	if last == nil || last.Index != 0 || last.Vy != 1 || last.AttackPhase != int32(common.AttackCooldown) {
+		t.Fatalf("cooldown not announced, last sent %v", last)
+	}
+
+	attacker.ProcessInput(&pb.Input{ActionPressed: true})
+	if attacker.Attacking() {
+		t.Fatal("attacked again during the cooldown")
+	}
+	for i := 0; i < w.timing.Cooldown; i++ {
+		w.update()
+	}
+	attacker.ProcessInput(&pb.Input{ActionPressed: true})
+	if attacker.AttackPhase != common.AttackWindUp {
+		t.Fatal("cannot attack after the cooldown")
+	}
+}
+
+func TestAttackWithoutCooldown(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AttackCooldown = 0
+	w, _, sent := newTestWorld(t, cfg, 0, 1)
+	placeApart(w)
+	attacker := w.Chars[0]
+	attacker.Px, attacker.Py = 100, 100
+	attacker.Fx, attacker.Fy = 1, 0
+	lastUpdate := func() *pb.UpdateEntity {
+		for i := len(*sent) - 1; i >= 0; i-- {
+			if ue := (*sent)[i].GetUpdateEntity(); ue != nil && ue.Index == 0 {
+				return ue
+			}
+		}
+		return nil
+	}
+
+	attacker.ProcessInput(&pb.Input{ActionPressed: true})
+	attacker.ProcessInput(&pb.Input{DownPressed: true})
+	for attacker.Attacking() {
+		w.update()
+	}
+	if attacker.AttackPhase != common.AttackIdle || attacker.Vy != 1 {
+		t.Fatalf("got phase %d and vy %d after recovery", attacker.AttackPhase, attacker.Vy)
+	}
+	last := lastUpdate()
+	if last == nil || last.Vy != 1 || last.AttackPhase != int32(common.AttackIdle) {
+		t.Fatalf("end of attack not announced, last sent %v", last)
+	}
+
+	// a penalized attacker stays put
+	placeApart(w)
+	npc := w.Chars[common.DefaultPlayers]
+	npc.Px, npc.Py = 100, 100+common.HitRadius
+	attacker.Px, attacker.Py = 100, 100
+	attacker.Fx, attacker.Fy = 0, 1
+	attacker.ProcessInput(&pb.Input{ActionPressed: true, DownPressed: true})
+	for attacker.Attacking() {
+		w.update()
+	}
+	if !npc.IsDead || !w.stunned(0) {
+		t.Fatal("attacker not penalized")
+	}
+	if attacker.Vx != 0 || attacker.Vy != 0 {
+		t.Fatalf("stunned attacker moving at (%d, %d)", attacker.Vx, attacker.Vy)
+	}
+	if last := lastUpdate(); last == nil || last.Vy != 0 || last.AttackPhase != int32(common.AttackIdle) {
+		t.Fatalf("end of attack not announced, last sent %v", last)
+	}
+}
+
+func TestSprintIsAnnounced(t *testing.T) {
+	w, _, sent := newTestWorld(t, DefaultConfig(), 0, 1)
+	placeApart(w)
+	runner := w.Chars[0]
+	runner.Px, runner.Py = 100, 100
+	runner.ProcessInput(&pb.Input{RightPressed: true, SprintPressed: true})
+	w.update()
+	if runner.Px != 100+common.SprintSpeed {
+		t.Fatalf("moved to %v, want to sprint", runner.Px)
+	}
+	last := (*sent)[len(*sent)-1].GetUpdateEntity()
+	if last == nil || last.Index != 0 || last.Speed != common.SprintSpeed || last.Stamina != common.MaxStamina-common.SprintCost {
+		t.Fatalf("sprint not announced, last sent %v", last)
+	}
+
+	// nothing new to say while the sprint goes on
+	n := len(*sent)
+	w.update()
+	if len(*sent) != n {
+		t.Fatalf("sent %v", (*sent)[n:])
+	}
+}
+
+func TestFogOfWar(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Mode = "fog"
+	cfg.Map = "pillars"
+	w, _, sent := newTestWorld(t, cfg, 0, 1)
+	got := make(map[int32][]*pb.ServerMessage)
+	w.sendTo = func(slot int32, m *pb.ServerMessage) {
+		got[slot] = append(got[slot], m)
+	}
+	placeApart(w)
+	near, behind, far := common.DefaultPlayers, common.DefaultPlayers+1, common.DefaultPlayers+2
+	w.Chars[0].Px, w.Chars[0].Py = 72, 80
+	w.Chars[near].Px, w.Chars[near].Py = 72, 140
+	// across a pillar
+	w.Chars[behind].Px, w.Chars[behind].Py = 152, 80
+	w.Chars[far].Px, w.Chars[far].Py = 72, 280
+	w.update()
+
+	for i, want := range map[int]bool{0: true, near: true, behind: false, far: false} {
+		if w.visibleTo(0, i) != want {
+			t.Errorf("player sees %d: got %v, want %v", i, !want, want)
+		}
+	}
+	var shown []int32
+	for _, m := range got[0] {
+		if ue := m.GetUpdateEntity(); ue != nil {
+			shown = append(shown, ue.Index)
+		}
+	}
+	if len(shown) != 2 || shown[0] != 0 || shown[1] != int32(near) {
+		t.Fatalf("player was sent %v", shown)
+	}
+	for _, m := range *sent {
+		if m.GetUpdateEntity() != nil {
+			t.Fatalf("character update sent to everyone: %v", m)
+		}
+	}
+
+	w.Chars[near].Py = 280
+	w.update()
+	last := got[0][len(got[0])-1].GetUpdateEntity()
+	if last == nil || last.Index != int32(near) || !last.Hidden {
+		t.Fatalf("leaving view sent %v", last)
+	}
+
+	// the dead watch everything
+	w.Chars[0].IsDead = true
+	w.update()
+	if !w.visibleTo(0, behind) || !w.visibleTo(0, far) {
+		t.Fatal("dead player cannot see the whole arena")
+	}
+}
+
+func TestFogHidesDistantKills(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Mode = "fog"
+	cfg.Map = "pillars"
+	w, _, _ := newTestWorld(t, cfg, 0, 1, 2)
+	got := make(map[int32][]*pb.ServerMessage)
+	w.sendTo = func(slot int32, m *pb.ServerMessage) {
+		got[slot] = append(got[slot], m)
+	}
+	placeApart(w)
+	attacker, victim := w.Chars[1], w.Chars[2]
+	w.Chars[0].Px, w.Chars[0].Py = 72, 80
+	attacker.Px, attacker.Py = 72, 280
+	attacker.Fx, attacker.Fy = 0, 1
+	victim.Px, victim.Py = 72, 280+common.HitRadius
+	w.update()
+
+	attacker.ProcessInput(&pb.Input{ActionPressed: true})
+	for attacker.Attacking() {
+		w.update()
+	}
+	if !victim.IsDead {
+		t.Fatal("victim survived")
+	}
+	kill := func(slot int32) *pb.PlayerKilled {
+		for _, m := range got[slot] {
+			if pk := m.GetPlayerKilled(); pk != nil {
+				return pk
+			}
+		}
+		return nil
+	}
+	if pk := kill(1); pk == nil || pk.Px != victim.Px || pk.Py != victim.Py {
+		t.Fatalf("killer was sent %v", pk)
+	}
+	if pk := kill(0); pk == nil || pk.Victim != 2 || pk.Px != 0 || pk.Py != 0 {
+		t.Fatalf("distant player was sent %v", pk)
+	}
+}
+
+func TestRoomSize(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PlayerSlots = 16
+	cfg.CrowdSize = 500
+	cfg.Map = "warehouse"
+	w, _, sent := newTestWorld(t, cfg, 0, 15)
+	// and a decoy slot per player
+	if len(w.Chars) != 516 || len(w.AIs) != 498 || len(w.Score) != 16 {
+		t.Fatalf("got %d characters, %d AIs and %d scores", len(w.Chars), len(w.AIs), len(w.Score))
+	}
+	placeApart(w)
+	attacker, npc := w.Chars[15], w.Chars[499]
+	attacker.Px, attacker.Py = 100, 100
+	attacker.Fx, attacker.Fy = 0, 1
+	npc.Px, npc.Py = 100, 100+common.HitRadius
+	attacker.ProcessInput(&pb.Input{ActionPressed: true})
+	for attacker.Attacking() {
+		w.update()
+	}
+	if !npc.IsDead || findPenalty(*sent).GetIndex() != 15 {
+		t.Fatal("player 15 did not kill the last AI")
+	}
+}
+
+// attackAI has player 0 kill the first AI with a full attack.
+func attackAI(w *World) *common.Char {
+	attacker, npc := w.Chars[0], w.Chars[common.DefaultPlayers]
+	attacker.Px, attacker.Py = 100, 100
+	attacker.Fx, attacker.Fy = 0, 1
+	npc.Px, npc.Py = 100, 100+common.HitRadius
+
+	attacker.ProcessInput(&pb.Input{ActionPressed: true})
+	for attacker.Attacking() {
+		w.update()
+	}
+	return npc
+}
+
+func findPenalty(msgs []*pb.ServerMessage) *pb.PlayerPenalized {
+	for _, m := range msgs {
+		if pp := m.GetPlayerPenalized(); pp != nil {
+			return pp
+		}
+	}
+	return nil
+}
+
+func TestAttackOnAIPenalizesAttacker(t *testing.T) {
+	cfg := DefaultConfig()
+	penalty := Modes[cfg.Mode].AIPenalty
+	w, clock, sent := newTestWorld(t, cfg, 0, 1)
+	placeApart(w)
+	w.Score[0] = 3
+	npc := attackAI(w)
+	if !npc.IsDead {
+		t.Fatal("AI survived")
+	}
+	pp := findPenalty(*sent)
+	if pp == nil || pp.Index != 0 || pp.ScoreLoss != penalty.ScoreLoss ||
+		pp.StunMillis != int32(penalty.Stun.Milliseconds()) || pp.RevealMillis != int32(penalty.Reveal.Milliseconds()) {
+		t.Fatalf("got PlayerPenalized %v", pp)
+	}
+	if w.Score[0] != 3-penalty.ScoreLoss {
+		t.Fatalf("score %d after penalty", w.Score[0])
+	}
+	if w.Kills[0] != 0 {
+		t.Fatal("AI kill counted as a player kill")
+	}
+
+	// input sent while stunned waits for the stun to wear off
+	if !w.stunned(0) {
+		t.Fatal("attacker not stunned")
+	}
+	w.heldInput[0] = &pb.Input{RightPressed: true}
+	clock.Advance(penalty.Stun - time.Millisecond)
+	w.update()
+	if w.Chars[0].Vx != 0 {
+		t.Fatal("moved while stunned")
+	}
+	clock.Advance(time.Millisecond)
+	w.update()
+	if w.stunned(0) || w.Chars[0].Vx != 1 {
+		t.Fatalf("held input not applied after the stun: vx %d", w.Chars[0].Vx)
+	}
+}
+
+func TestAIPenaltyNeverLeavesNegativeScore(t *testing.T) {
+	w, _, sent := newTestWorld(t, DefaultConfig(), 0, 1)
+	placeApart(w)
+	attackAI(w)
+	if pp := findPenalty(*sent); pp == nil || pp.ScoreLoss != 0 || w.Score[0] != 0 {
+		t.Fatalf("got %v with score %d", pp, w.Score[0])
+	}
+}
+
+func TestPracticeAIPenaltyOnlyReveals(t *testing.T) {
+	w, _, sent := newTestWorld(t, PracticeConfig(), 0)
+	placeApart(w)
+	w.Score[0] = 2
+	attackAI(w)
+	pp := findPenalty(*sent)
+	if pp == nil || pp.StunMillis != 0 || pp.RevealMillis == 0 || w.Score[0] != 2 {
+		t.Fatalf("got %v with score %d", pp, w.Score[0])
+	}
+	if w.stunned(0) {
+		t.Fatal("practice player stunned")
+	}
+}
+
+func TestLastSurvivorWins(t *testing.T) {
+	w, _, sent := newTestWorld(t, DefaultConfig(), 0, 3)
+	placeApart(w)
+	w.Chars[3].IsDead = true
+	w.Kills[0] = 1
+	w.update()
+	ge := findGameEnd(*sent)
+	if ge == nil || ge.Survivor != 0 {
+		t.Fatalf("got %v, want player 0 to win", ge)
+	}
+	if len(ge.Kills) != common.DefaultPlayers || ge.Kills[0] != 1 {
+		t.Fatalf("unexpected kills %v", ge.Kills)
+	}
+	if w.Running {
+		t.Fatal("world still running")
+	}
+	select {
+	case <-w.killSig:
+	default:
+		t.Fatal("AI and coin goroutines were not signalled to stop")
+	}
+}
+
+func TestPracticeIgnoresLastSurvivor(t *testing.T) {
+	w, _, sent := newTestWorld(t, PracticeConfig(), 0)
+	w.update()
+	if findGameEnd(*sent) != nil || !w.Running {
+		t.Fatal("practice match ended with the only player alive")
+	}
+}
+
+func TestCoinPickup(t *testing.T) {
+	w, _, sent := newTestWorld(t, DefaultConfig(), 0, 1)
+	placeApart(w)
+	w.spawnCoins()
+	w.spawnCoins()
+	w.Coins[0].Px, w.Coins[0].Py = 500, 500
+	coin := w.Coins[1]
+	coin.Px, coin.Py = 100, 100
+	w.Chars[1].Px, w.Chars[1].Py = coin.Px, coin.Py
+
+	w.update()
+	if !coin.PickedUp || w.Coins[0].PickedUp {
+		t.Fatal("wrong coin picked up")
+	}
+	if w.Score[1] != 1 || w.Score[0] != 0 {
+		t.Fatalf("unexpected scores %v", w.Score)
+	}
+	var got []int32
+	for _, m := range *sent {
+		if cg := m.GetCoinGot(); cg != nil {
+			got = append(got, cg.Index)
+		}
+	}
+	if len(got) != 1 || got[0] != 1 {
+		t.Fatalf("got CoinGot %v, want [1]", got)
+	}
+
+	w.update()
+	if w.Score[1] != 1 {
+		t.Fatal("coin was picked up twice")
+	}
+}
+
+func TestMatchTimesOut(t *testing.T) {
+	cfg := DefaultConfig()
+	// the coin is only collected at the end
+	cfg.CoinLifetime = 0
+	w, clock, sent := newTestWorld(t, cfg, 0, 1)
+	placeApart(w)
+	w.spawnCoins()
+	w.Chars[1].Px, w.Chars[1].Py = w.Coins[0].Px, w.Coins[0].Py
+
+	clock.Advance(cfg.MatchLength - time.Second)
+	w.update()
+	if findGameEnd(*sent) != nil {
+		t.Fatal("match ended early")
+	}
+	clock.Advance(2 * time.Second)
+	w.update()
+	ge := findGameEnd(*sent)
+	if ge == nil {
+		t.Fatal("match did not time out")
+	}
+	if len(ge.Score) != common.DefaultPlayers || ge.Score[1] != 1 {
+		t.Fatalf("unexpected scores %v", ge.Score)
+	}
+	if w.Running {
+		t.Fatal("world still running")
+	}
+}
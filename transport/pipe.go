@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"context"
+	"sync"
+)
+
+// Messages a pipe end can hold before Write blocks.
+const pipeBuffer = 256
+
+type pipe struct {
+	done   chan struct{}
+	once   sync.Once
+	code   StatusCode
+	reason string
+}
+
+func (p *pipe) close(code StatusCode, reason string) {
+	p.once.Do(func() {
+		p.code = code
+		p.reason = reason
+		close(p.done)
+	})
+}
+
+type pipeConn struct {
+	p   *pipe
+	in  chan []byte
+	out chan []byte
+	// set on the end that called Close so its own reads fail with ErrClosed
+	local bool
+	mu    sync.Mutex
+}
+
+// Pipe returns two connected in-memory Conns. Messages written to one are
+// read from the other in order, and closing either end closes both.
+func Pipe() (Conn, Conn) {
+	p := &pipe{done: make(chan struct{})}
+	ab := make(chan []byte, pipeBuffer)
+	ba := make(chan []byte, pipeBuffer)
+	return &pipeConn{p: p, in: ba, out: ab}, &pipeConn{p: p, in: ab, out: ba}
+}
+
+func (c *pipeConn) Read(ctx context.Context) ([]byte, error) {
+	// deliver everything written before the close, like a websocket would
+	select {
+	case data := <-c.in:
+		return data, nil
+	default:
+	}
+	select {
+	case data := <-c.in:
+		return data, nil
+	case <-c.p.done:
+		select {
+		case data := <-c.in:
+			return data, nil
+		default:
+		}
+		return nil, c.closedErr()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *pipeConn) Write(ctx context.Context, data []byte) error {
+	select {
+	case <-c.p.done:
+		return c.closedErr()
+	default:
+	}
+	buf := append([]byte(nil), data...)
+	select {
+	case c.out <- buf:
+		return nil
+	case <-c.p.done:
+		return c.closedErr()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *pipeConn) Ping(ctx context.Context) error {
+	select {
+	case <-c.p.done:
+		return c.closedErr()
+	default:
+		return nil
+	}
+}
+
+func (c *pipeConn) Close(code StatusCode, reason string) error {
+	c.mu.Lock()
+	c.local = true
+	c.mu.Unlock()
+	c.p.close(code, reason)
+	return nil
+}
+
+func (c *pipeConn) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.local {
+		return ErrClosed
+	}
+	return &CloseError{Code: c.p.code, Reason: c.p.reason}
+}
@@ -0,0 +1,52 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPipe(t *testing.T) {
+	ctx := context.Background()
+	a, b := Pipe()
+	for _, msg := range []string{"one", "two"} {
+		if err := a.Write(ctx, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Write(ctx, []byte("back")); err != nil {
+		t.Fatal(err)
+	}
+	a.Close(StatusGoingAway, "bye")
+
+	// messages sent before the close are still delivered
+	for _, want := range []string{"one", "two"} {
+		got, err := b.Read(ctx)
+		if err != nil || string(got) != want {
+			t.Fatalf("got %q, %v; want %q", got, err, want)
+		}
+	}
+	_, err := b.Read(ctx)
+	var ce *CloseError
+	if !errors.As(err, &ce) || ce.Code != StatusGoingAway || ce.Reason != "bye" {
+		t.Fatalf("got %v, want close error", err)
+	}
+	if !IsExpectedClose(err) {
+		t.Fatal("going away should be an expected close")
+	}
+	if err := b.Ping(ctx); err == nil {
+		t.Fatal("ping succeeded on closed pipe")
+	}
+	if err := a.Write(ctx, []byte("late")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("got %v, want ErrClosed", err)
+	}
+}
+
+func TestPipeReadContext(t *testing.T) {
+	a, _ := Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := a.Read(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+}
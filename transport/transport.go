@@ -0,0 +1,61 @@
+// Package transport abstracts the message connection between the game
+// client and the server so either end can run over a websocket or, in
+// tests and practice mode, an in-memory pipe.
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// StatusCode is a close status. Values match websocket close codes.
+type StatusCode int
+
+const (
+	StatusNormalClosure   StatusCode = 1000
+	StatusGoingAway       StatusCode = 1001
+	StatusProtocolError   StatusCode = 1002
+	StatusUnsupportedData StatusCode = 1003
+	StatusPolicyViolation StatusCode = 1008
+	StatusInternalError   StatusCode = 1011
+	StatusTryAgainLater   StatusCode = 1013
+)
+
+// Conn is a bidirectional connection carrying whole binary messages.
+//
+// Read and Write may be called from different goroutines, but at most one
+// goroutine may read and one may write at a time.
+type Conn interface {
+	// Read blocks until the next message arrives.
+	Read(ctx context.Context) ([]byte, error)
+	Write(ctx context.Context, data []byte) error
+	// Ping returns once the peer has answered, or with an error if it
+	// cannot be reached.
+	Ping(ctx context.Context) error
+	// Close tells the peer why the connection is ending and releases it.
+	Close(code StatusCode, reason string) error
+}
+
+// ErrClosed is returned for operations on a connection closed locally.
+var ErrClosed = errors.New("connection closed")
+
+// CloseError is returned by Read once the peer has closed the connection.
+type CloseError struct {
+	Code   StatusCode
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("connection closed: status %d: %s", e.Code, e.Reason)
+}
+
+// IsExpectedClose reports whether err is the peer closing normally or
+// going away, as opposed to a failure worth logging.
+func IsExpectedClose(err error) bool {
+	var ce *CloseError
+	if errors.As(err, &ce) {
+		return ce.Code == StatusNormalClosure || ce.Code == StatusGoingAway
+	}
+	return errors.Is(err, ErrClosed)
+}